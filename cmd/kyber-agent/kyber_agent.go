@@ -0,0 +1,319 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the kyber-agent daemon that loads the keys of a JSON config into a kyber_agent and serves them on a Unix socket
+	{
+	  "socket": "/run/kyber-agent/agent.sock",
+	  "socket_mode": "0660",
+	  "socket_group": "kyber",
+	  "audit_log": "/var/log/kyber-agent.log",
+	  "confirm_command": ["/usr/local/bin/kyber-confirm"],
+	  "keys": [
+	    {"name": "web", "file": "web.pem", "passphrase_file": "web.pass", "uids": [33], "gids": [], "confirm": false}
+	  ]
+	}
+relative paths are taken from the directory of the config, the audit log goes to stderr when it is not set
+the socket is 0600 unless socket_mode is set, so by default only the user running the agent can use it,
+to serve other users the agent runs as its own user with socket_mode 0660 and socket_group a group those users are in,
+and every key lists the uids or gids that may use it, a gid matches the primary or any supplementary group of the process (supplementary groups are
+only read on linux), a key without either is usable by anyone who can open the socket
+an encrypted key without a passphrase_file asks for its passphrase on stdin
+the confirm command runs with KYBER_AGENT_KEY, KYBER_AGENT_UID, KYBER_AGENT_GID and KYBER_AGENT_PID set and allows the use by exiting 0
+*/
+package main
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_agent"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const confirm_timeout=time.Minute
+
+type key_config struct{
+	Name string `json:"name"`
+	File string `json:"file"`
+	Passphrase_File string `json:"passphrase_file"`
+	UIDs []int `json:"uids"`
+	GIDs []int `json:"gids"`
+	Confirm bool `json:"confirm"`
+}
+
+type config struct{
+	Socket string `json:"socket"`
+	Socket_Mode string `json:"socket_mode"`
+	Socket_Group string `json:"socket_group"`
+	Audit_Log string `json:"audit_log"`
+	Confirm_Command []string `json:"confirm_command"`
+	Keys []key_config `json:"keys"`
+}
+
+type daemon struct{
+	stdin *bufio.Reader
+	stdout,stderr io.Writer
+}
+
+func main(){
+	stop:=make(chan os.Signal,1)
+	signal.Notify(stop,syscall.SIGINT,syscall.SIGTERM)
+	os.Exit(run(os.Args[1:],os.Stdin,os.Stdout,os.Stderr,stop))
+}
+
+func run(args []string,stdin io.Reader,stdout,stderr io.Writer,stop <-chan os.Signal)int{
+	d:=&daemon{bufio.NewReader(stdin),stdout,stderr}
+	var err error
+	switch{
+	case len(args)>0&&args[0]=="encrypt-key":
+		err=d.encrypt_key(args[1:])
+	case len(args)>0&&args[0]=="serve":
+		err=d.serve(args[1:],stop)
+	default:
+		fmt.Fprintln(stderr,"usage:")
+		fmt.Fprintln(stderr,"	kyber-agent serve -config file")
+		fmt.Fprintln(stderr,"	kyber-agent encrypt-key -key file -passphrase-file file -out file")
+		return 2
+	}
+	if err!=nil{
+		if err!=flag.ErrHelp{
+			fmt.Fprintln(stderr,"kyber-agent "+args[0]+": "+err.Error())
+		}
+		return 1
+	}
+	return 0
+}
+
+func (d *daemon)flags(name string)*flag.FlagSet{
+	f:=flag.NewFlagSet(name,flag.ContinueOnError)
+	f.SetOutput(d.stderr)
+	return f
+}
+
+//the first line of a passphrase file, so a trailing newline is not part of it
+func read_passphrase(path string)([]byte,error){
+	data,err:=os.ReadFile(path)
+	if err!=nil{
+		return nil,err
+	}
+	line,_,_:=bytes.Cut(data,[]byte("\n"))
+	return bytes.TrimSuffix(line,[]byte("\r")),nil
+}
+
+func (d *daemon)encrypt_key(args []string)error{
+	f:=d.flags("encrypt-key")
+	key:=f.String("key","","plain PEM private key file")
+	passphrase_file:=f.String("passphrase-file","","file holding the passphrase")
+	out:=f.String("out","","encrypted key output file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *key==""||*passphrase_file==""||*out==""{
+		return errors.New("-key, -passphrase-file and -out are required")
+	}
+	data,err:=os.ReadFile(*key)
+	if err!=nil{
+		return err
+	}
+	sk,err:=kyber_agent.Load_Key_File(data,func()([]byte,error){
+		return nil,errors.New(*key+" is already encrypted")
+	})
+	if err!=nil{
+		return err
+	}
+	passphrase,err:=read_passphrase(*passphrase_file)
+	if err!=nil{
+		return err
+	}
+	if len(passphrase)==0{
+		return errors.New("passphrase is empty")
+	}
+	encrypted,err:=kyber_agent.Encrypt_Key_File(sk,passphrase)
+	if err!=nil{
+		return err
+	}
+	return os.WriteFile(*out,encrypted,0600)
+}
+
+func load_config(path string)(*config,error){
+	data,err:=os.ReadFile(path)
+	if err!=nil{
+		return nil,err
+	}
+	c:=new(config)
+	dec:=json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err=dec.Decode(c);err!=nil{
+		return nil,errors.New(path+": "+err.Error())
+	}
+	if c.Socket==""||len(c.Keys)==0{
+		return nil,errors.New(path+": socket and keys are required")
+	}
+	dir:=filepath.Dir(path)
+	resolve:=func(p *string){
+		if *p!=""&&!filepath.IsAbs(*p){
+			*p=filepath.Join(dir,*p)
+		}
+	}
+	resolve(&c.Socket)
+	resolve(&c.Audit_Log)
+	for i:=range c.Keys{
+		resolve(&c.Keys[i].File)
+		resolve(&c.Keys[i].Passphrase_File)
+	}
+	return c,nil
+}
+
+//the mode is octal permission bits, the group a name or a numeric gid
+func socket_access(c *config)(mode os.FileMode,gid int,err error){
+	mode,gid=0600,-1
+	if c.Socket_Mode!=""{
+		m,err:=strconv.ParseUint(c.Socket_Mode,8,32)
+		if err!=nil||m&^uint64(os.ModePerm)!=0{
+			return 0,0,errors.New("socket_mode "+c.Socket_Mode+" is not octal permission bits")
+		}
+		mode=os.FileMode(m)
+	}
+	if c.Socket_Group!=""{
+		if g,err:=user.LookupGroup(c.Socket_Group);err==nil{
+			c.Socket_Group=g.Gid
+		}
+		if gid,err=strconv.Atoi(c.Socket_Group);err!=nil||gid<0{
+			return 0,0,errors.New("socket_group "+c.Socket_Group+" is not a known group")
+		}
+	}
+	return mode,gid,nil
+}
+
+func (d *daemon)load_keys(c *config,a *kyber_agent.Agent)error{
+	for _,k:=range c.Keys{
+		data,err:=os.ReadFile(k.File)
+		if err!=nil{
+			return err
+		}
+		sk,err:=kyber_agent.Load_Key_File(data,func()([]byte,error){
+			if k.Passphrase_File!=""{
+				return read_passphrase(k.Passphrase_File)
+			}
+			fmt.Fprint(d.stderr,"passphrase for "+k.Name+": ")
+			line,err:=d.stdin.ReadString('\n')
+			if err!=nil&&(err!=io.EOF||line==""){
+				return nil,errors.New("no passphrase for "+k.Name)
+			}
+			return bytes.TrimRight([]byte(line),"\r\n"),nil
+		})
+		if err!=nil{
+			return errors.New(k.Name+": "+err.Error())
+		}
+		if err=a.Add(kyber_agent.Key{Name:k.Name,Private_Key:sk,UIDs:k.UIDs,GIDs:k.GIDs,Confirm:k.Confirm});err!=nil{
+			return errors.New(k.Name+": "+err.Error())
+		}
+	}
+	return nil
+}
+
+func confirm_command(command []string)func(key string,p kyber_agent.Peer)bool{
+	return func(key string,p kyber_agent.Peer)bool{
+		ctx,cancel:=context.WithTimeout(context.Background(),confirm_timeout)
+		defer cancel()
+		cmd:=exec.CommandContext(ctx,command[0],command[1:]...)
+		cmd.Env=append(os.Environ(),
+			"KYBER_AGENT_KEY="+key,
+			"KYBER_AGENT_UID="+strconv.Itoa(p.UID),
+			"KYBER_AGENT_GID="+strconv.Itoa(p.GID),
+			"KYBER_AGENT_PID="+strconv.Itoa(p.PID),
+		)
+		return cmd.Run()==nil
+	}
+}
+
+//a socket left by an agent that died is removed, one that still answers is not touched
+func remove_stale(path string)error{
+	info,err:=os.Lstat(path)
+	if err!=nil||info.Mode()&os.ModeSocket==0{
+		return nil
+	}
+	if conn,err:=net.Dial("unix",path);err==nil{
+		conn.Close()
+		return errors.New(path+" is in use by a running agent")
+	}
+	return os.Remove(path)
+}
+
+func (d *daemon)serve(args []string,stop <-chan os.Signal)error{
+	f:=d.flags("serve")
+	config_path:=f.String("config","","JSON config file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *config_path==""{
+		return errors.New("-config is required")
+	}
+	c,err:=load_config(*config_path)
+	if err!=nil{
+		return err
+	}
+	mode,gid,err:=socket_access(c)
+	if err!=nil{
+		return err
+	}
+	a:=kyber_agent.New()
+	if err=d.load_keys(c,a);err!=nil{
+		return err
+	}
+	audit:=d.stderr
+	if c.Audit_Log!=""{
+		log,err:=os.OpenFile(c.Audit_Log,os.O_WRONLY|os.O_CREATE|os.O_APPEND,0600)
+		if err!=nil{
+			return err
+		}
+		defer log.Close()
+		audit=log
+	}
+	a.Audit=kyber_agent.Audit_Log(audit)
+	if len(c.Confirm_Command)>0{
+		a.Confirm=confirm_command(c.Confirm_Command)
+	}
+	if mode&0077!=0{
+		for _,k:=range c.Keys{
+			if len(k.UIDs)==0&&len(k.GIDs)==0{
+				fmt.Fprintln(d.stderr,"kyber-agent: warning: "+k.Name+" has no uids or gids, anyone who can open the socket can use it")
+			}
+		}
+	}
+	if err=remove_stale(c.Socket);err!=nil{
+		return err
+	}
+	l,err:=kyber_agent.Listen_Shared(c.Socket,mode,gid)
+	if err!=nil{
+		return err
+	}
+	fmt.Fprintf(d.stdout,"kyber-agent: serving %d keys on %s\n",len(c.Keys),c.Socket)
+	done:=make(chan error,1)
+	go func(){done<-a.Serve(l)}()
+	select{
+	case <-stop:
+		l.Close()//also removes the socket file
+		<-done
+		return nil
+	case err=<-done:
+		l.Close()
+		return err
+	}
+}
@@ -0,0 +1,181 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the kyber-agent daemon with plain and encrypted keys, the confirm command and the audit log
+*/
+package main
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_agent"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+//the daemon writes to stderr from its own goroutine while the test reads it
+type locked_buffer struct{
+	mu sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *locked_buffer)Write(p []byte)(int,error){
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *locked_buffer)String()string{
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func write(t *testing.T,path string,data []byte){
+	if err:=os.WriteFile(path,data,0600);err!=nil{
+		t.Fatal(err)
+	}
+}
+
+func plain_key(t *testing.T,path string)kyber_kem.Private_Key{
+	sk,_:=kyber_kem.Kyber_768.Keygen()
+	write(t,path,pem.EncodeToMemory(&pem.Block{Type:"KYBER PRIVATE KEY",Headers:map[string]string{"Scheme":sk.Scheme().Name()},Bytes:sk.To_Bytes()}))
+	return sk
+}
+
+func Test_Serve(t *testing.T){
+	if _,err:=exec.LookPath("sh");err!=nil{
+		t.Skip("no sh for the confirm command")
+	}
+	dir:=t.TempDir()
+	var sks []kyber_kem.Private_Key
+	for _,name:=range []string{"file","prompt","allowed","refused"}{
+		sks=append(sks,plain_key(t,filepath.Join(dir,name+".pem")))
+	}
+	write(t,filepath.Join(dir,"pass"),[]byte("file passphrase\n"))
+	write(t,filepath.Join(dir,"prompt.pass"),[]byte("typed passphrase\n"))
+	for _,name:=range []string{"file","prompt"}{
+		var stderr bytes.Buffer
+		args:=[]string{"encrypt-key","-key",filepath.Join(dir,name+".pem"),"-out",filepath.Join(dir,name+".enc"),"-passphrase-file",filepath.Join(dir,"pass")}
+		if name=="prompt"{
+			args[len(args)-1]=filepath.Join(dir,"prompt.pass")
+		}
+		if code:=run(args,nil,&stderr,&stderr,nil);code!=0{
+			t.Fatal(stderr.String())
+		}
+	}
+	var stderr bytes.Buffer
+	if code:=run([]string{"encrypt-key","-key",filepath.Join(dir,"file.enc"),"-out",filepath.Join(dir,"x"),"-passphrase-file",filepath.Join(dir,"pass")},nil,&stderr,&stderr,nil);code!=1||!strings.Contains(stderr.String(),"already encrypted"){
+		t.Fatal("encrypted key was encrypted again")
+	}
+	write(t,filepath.Join(dir,"agent.json"),[]byte(`{
+	"socket": "agent.sock",
+	"socket_mode": "0660",
+	"socket_group": "`+strconv.Itoa(os.Getgid())+`",
+	"audit_log": "audit.log",
+	"confirm_command": ["sh", "-c", "test \"$KYBER_AGENT_KEY\" = allowed"],
+	"keys": [
+		{"name": "file", "file": "file.enc", "passphrase_file": "pass"},
+		{"name": "prompt", "file": "prompt.enc"},
+		{"name": "allowed", "file": "allowed.pem", "confirm": true},
+		{"name": "refused", "file": "refused.pem", "confirm": true}
+	]
+}`))
+	stop:=make(chan os.Signal,1)
+	done:=make(chan int,1)
+	var stdout,errs locked_buffer
+	go func(){
+		done<-run([]string{"serve","-config",filepath.Join(dir,"agent.json")},strings.NewReader("typed passphrase\n"),&stdout,&errs,stop)
+	}()
+	socket:=filepath.Join(dir,"agent.sock")
+	var c *kyber_agent.Client
+	for i:=0;c==nil;i++{
+		var err error
+		if c,err=kyber_agent.Dial(socket);err!=nil{
+			select{
+			case code:=<-done:
+				t.Fatalf("agent exited with %d: %s",code,errs.String())
+			case <-time.After(50*time.Millisecond):
+			}
+			if i>200{
+				t.Fatal("agent did not start")
+			}
+		}
+	}
+	if info,err:=os.Stat(socket);err!=nil||info.Mode().Perm()!=0660{
+		t.Fatal("socket_mode was not applied")
+	}
+	if !strings.Contains(errs.String(),"warning: refused has no uids or gids"){
+		t.Fatal("key open to everyone on a shared socket was not warned about")
+	}
+	keys,err:=c.List()
+	if err!=nil||len(keys)!=4{
+		t.Fatal("agent does not list its keys")
+	}
+	for i,k:=range keys{
+		ct,K,_:=sks[i].Public().Enc()
+		got,err:=k.Dec(ct,32)
+		if k.Name()=="refused"{
+			if err!=kyber_agent.Err_Denied{
+				t.Fatal("confirm command did not refuse")
+			}
+			continue
+		}
+		if err!=nil||!bytes.Equal(got,K){
+			t.Fatal(k.Name()+" did not decapsulate")
+		}
+	}
+	c.Close()
+	stop<-syscall.SIGTERM
+	if code:=<-done;code!=0{
+		t.Fatal(errs.String())
+	}
+	if _,err=os.Stat(socket);!os.IsNotExist(err){
+		t.Fatal("socket was left behind")
+	}
+	if !strings.Contains(errs.String(),"passphrase for prompt: "){
+		t.Fatal("encrypted key without a passphrase file was not prompted for")
+	}
+	log,_:=os.ReadFile(filepath.Join(dir,"audit.log"))
+	if !strings.Contains(string(log),`op="dec" key="allowed" result="ok"`)||!strings.Contains(string(log),`op="dec" key="refused" result="not confirmed"`){
+		t.Fatal("audit log is missing events:\n"+string(log))
+	}
+}
+
+func Test_Errors(t *testing.T){
+	dir:=t.TempDir()
+	plain_key(t,filepath.Join(dir,"k.pem"))
+	cases:=[]struct{
+		config,want string
+	}{
+		{`{"socket":"s","keys":[]}`,"socket and keys are required"},
+		{`{"socket":"s","keys":[{"name":"k","file":"k.pem"}],"typo":1}`,"unknown field"},
+		{`{"socket":"s","keys":[{"name":"k","file":"k.pem"},{"name":"k","file":"k.pem"}]}`,"already taken"},
+		{`{"socket":"s","keys":[{"name":"k","file":"missing.pem"}]}`,"missing.pem"},
+		{`{"socket":"s","socket_mode":"1777","keys":[{"name":"k","file":"k.pem"}]}`,"not octal permission bits"},
+		{`{"socket":"s","socket_mode":"rw","keys":[{"name":"k","file":"k.pem"}]}`,"not octal permission bits"},
+		{`{"socket":"s","socket_group":"no such group","keys":[{"name":"k","file":"k.pem"}]}`,"not a known group"},
+	}
+	for _,tc:=range cases{
+		write(t,filepath.Join(dir,"agent.json"),[]byte(tc.config))
+		var stdout,stderr bytes.Buffer
+		if code:=run([]string{"serve","-config",filepath.Join(dir,"agent.json")},nil,&stdout,&stderr,nil);code!=1||!strings.Contains(stderr.String(),tc.want){
+			t.Fatalf("config %s: exit %d, %s",tc.config,code,stderr.String())
+		}
+	}
+	var stderr bytes.Buffer
+	if run(nil,nil,&stderr,&stderr,nil)!=2||!strings.Contains(stderr.String(),"kyber-agent serve -config file"){
+		t.Fatal("usage was not printed")
+	}
+}
@@ -0,0 +1,666 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the kyber command line tool for generating keys, raw encapsulation, file encryption and JSON or YAML secrets
+keys are written as PEM with a Scheme header by default, hex, base64 and raw keys are matched to a scheme by their length
+encrypted files are magic||kyber_seal(file key)||64KiB ChaCha20-Poly1305 chunks with nonce=11 byte counter||last chunk flag
+*/
+package main
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_seal"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+	"crypto/cipher"
+	"crypto/rand"
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const(
+	magic="kyber-encrypted-v1\n"
+	file_key_len=32
+	chunk_len=64*1024
+	pem_private="KYBER PRIVATE KEY"
+	pem_public="KYBER PUBLIC KEY"
+)
+
+type cli struct{
+	stdin io.Reader
+	stdout,stderr io.Writer
+}
+
+type command struct{
+	name,usage string
+	run func(c *cli,args []string)error
+}
+
+var commands []command
+
+func init(){//set here since the usage command reads the list
+	commands=[]command{
+		{"keygen","keygen [-scheme kyber_768] [-format pem|hex|base64|raw] [-out file] [-pub file]",(*cli).keygen},
+		{"pubkey","pubkey -key file [-scheme name] [-format pem|hex|base64|raw] [-out file]",(*cli).pubkey},
+		{"encap","encap -key file -ct file [-scheme name] [-format hex|base64]",(*cli).encap},
+		{"decap","decap -key file -ct file [-scheme name] [-format hex|base64]",(*cli).decap},
+		{"encrypt","encrypt -key file [-scheme name] [-in file] [-out file]",(*cli).encrypt},
+		{"decrypt","decrypt -key file [-scheme name] [-in file] [-out file]",(*cli).decrypt},
+		{"inspect","inspect [file]",(*cli).inspect},
+		{"secrets-encrypt","secrets-encrypt -recipient file [-recipient file]... [-scheme name] [-in file] [-out file]",(*cli).secrets_encrypt},
+		{"secrets-decrypt","secrets-decrypt -key file [-scheme name] [-in file] [-out file]",(*cli).secrets_decrypt},
+		{"secrets-add","secrets-add -key file -recipient file [-scheme name] [-in file] [-out file]",(*cli).secrets_add},
+		{"secrets-remove","secrets-remove -key file -recipient file [-scheme name] [-in file] [-out file]",(*cli).secrets_remove},
+		{"secrets-rotate","secrets-rotate -key file [-scheme name] [-in file] [-out file]",(*cli).secrets_rotate},
+	}
+}
+
+func main(){
+	os.Exit(run(os.Args[1:],os.Stdin,os.Stdout,os.Stderr))
+}
+
+func run(args []string,stdin io.Reader,stdout,stderr io.Writer)int{
+	c:=&cli{stdin,stdout,stderr}
+	if len(args)==0{
+		c.usage()
+		return 2
+	}
+	for _,cmd:=range commands{
+		if cmd.name!=args[0]{
+			continue
+		}
+		if err:=cmd.run(c,args[1:]);err!=nil{
+			if err!=flag.ErrHelp{
+				fmt.Fprintln(stderr,"kyber "+cmd.name+": "+err.Error())
+			}
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintln(stderr,"kyber: unknown command "+args[0])
+	c.usage()
+	return 2
+}
+
+func (c *cli)usage(){
+	fmt.Fprintln(c.stderr,"usage:")
+	for _,cmd:=range commands{
+		fmt.Fprintln(c.stderr,"	kyber "+cmd.usage)
+	}
+	fmt.Fprintln(c.stderr,"schemes:")
+	for _,s:=range kyber_kem.All{
+		fmt.Fprintln(c.stderr,"	"+s.Name())
+	}
+}
+
+func (c *cli)flags(name string)*flag.FlagSet{
+	f:=flag.NewFlagSet(name,flag.ContinueOnError)
+	f.SetOutput(c.stderr)
+	return f
+}
+
+func (c *cli)open(path string)(io.ReadCloser,error){
+	if path=="-"{
+		return io.NopCloser(c.stdin),nil
+	}
+	return os.Open(path)
+}
+
+func (c *cli)read_file(path string)([]byte,error){
+	r,err:=c.open(path)
+	if err!=nil{
+		return nil,err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+//the caller has to close the writer, stdout is never closed
+func (c *cli)create(path string,private bool)(io.WriteCloser,error){
+	if path=="-"{
+		return nop_write_closer{c.stdout},nil
+	}
+	perm:=os.FileMode(0644)
+	if private{
+		perm=0600
+	}
+	return os.OpenFile(path,os.O_WRONLY|os.O_CREATE|os.O_TRUNC,perm)
+}
+
+type nop_write_closer struct{
+	io.Writer
+}
+
+func (nop_write_closer)Close()error{
+	return nil
+}
+
+func (c *cli)write_file(path string,data []byte,private bool)error{
+	w,err:=c.create(path,private)
+	if err!=nil{
+		return err
+	}
+	if _,err=w.Write(data);err!=nil{
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func encode_key(data []byte,s kyber_kem.Scheme,private bool,format string)([]byte,error){
+	switch format{
+	case "pem":
+		block:=&pem.Block{Type:pem_public,Headers:map[string]string{"Scheme":s.Name()},Bytes:data}
+		if private{
+			block.Type=pem_private
+		}
+		return pem.EncodeToMemory(block),nil
+	case "hex":
+		return []byte(hex.EncodeToString(data)+"\n"),nil
+	case "base64":
+		return []byte(base64.StdEncoding.EncodeToString(data)+"\n"),nil
+	case "raw":
+		return data,nil
+	}
+	return nil,errors.New("unknown format "+format)
+}
+
+func encode_secret(K []byte,format string)(string,error){
+	switch format{
+	case "hex":
+		return hex.EncodeToString(K),nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(K),nil
+	}
+	return "",errors.New("unknown format "+format)
+}
+
+//text encodings are tried before raw so a hex or base64 key is never mistaken for raw bytes
+func decode_text(data []byte)(decoded []byte,encoding string){
+	trimmed:=bytes.TrimSpace(data)
+	if b,err:=hex.DecodeString(string(trimmed));err==nil&&len(b)>0{
+		return b,"hex"
+	}
+	if b,err:=base64.StdEncoding.DecodeString(string(trimmed));err==nil&&len(b)>0{
+		return b,"base64"
+	}
+	return data,"raw"
+}
+
+type key_file struct{
+	scheme kyber_kem.Scheme
+	private bool
+	data []byte
+	encoding string
+}
+
+func parse_key(data []byte,scheme_name string)(*key_file,error){
+	var named kyber_kem.Scheme
+	if scheme_name!=""{
+		s,err:=kyber_kem.Scheme_by_Name(scheme_name)
+		if err!=nil{
+			return nil,err
+		}
+		named=s
+	}
+	if block,_:=pem.Decode(data);block!=nil{
+		if block.Type!=pem_private&&block.Type!=pem_public{
+			return nil,errors.New("unexpected PEM block "+block.Type)
+		}
+		k:=&key_file{named,block.Type==pem_private,block.Bytes,"pem"}
+		if header,ok:=block.Headers["Scheme"];ok||named==nil{
+			s,err:=kyber_kem.Scheme_by_Name(header)
+			if err!=nil{
+				return nil,err
+			}
+			if named!=nil&&s!=named{
+				return nil,errors.New("key is "+s.Name()+" but -scheme is "+named.Name())
+			}
+			k.scheme=s
+		}
+		return k,nil
+	}
+	decoded,encoding:=decode_text(data)
+	for _,s:=range kyber_kem.All{//the SHAKE variant comes first so it wins for public keys when -scheme is not given
+		if named!=nil&&s!=named{
+			continue
+		}
+		switch len(decoded){
+		case s.Sk_Len():
+			if _,err:=s.Bytes_to_Sk(decoded);err!=nil&&named==nil{//a 90s private key fails the SHAKE public key hash check
+				continue
+			}
+			return &key_file{s,true,decoded,encoding},nil
+		case s.Pk_Len():
+			return &key_file{s,false,decoded,encoding},nil
+		}
+	}
+	return nil,errors.New("not a kyber key")
+}
+
+func (c *cli)load_private(path,scheme_name string)(kyber_kem.Private_Key,error){
+	data,err:=c.read_file(path)
+	if err!=nil{
+		return nil,err
+	}
+	k,err:=parse_key(data,scheme_name)
+	if err!=nil{
+		return nil,err
+	}
+	if !k.private{
+		return nil,errors.New(path+" is a public key")
+	}
+	return k.scheme.Bytes_to_Sk(k.data)
+}
+
+//a private key can be given anywhere a public key is expected
+func (c *cli)load_public(path,scheme_name string)(kyber_kem.Public_Key,error){
+	data,err:=c.read_file(path)
+	if err!=nil{
+		return nil,err
+	}
+	k,err:=parse_key(data,scheme_name)
+	if err!=nil{
+		return nil,err
+	}
+	if k.private{
+		sk,err:=k.scheme.Bytes_to_Sk(k.data)
+		if err!=nil{
+			return nil,err
+		}
+		return sk.Public(),nil
+	}
+	return k.scheme.Bytes_to_Pk(k.data)
+}
+
+func (c *cli)keygen(args []string)error{
+	f:=c.flags("keygen")
+	scheme_name:=f.String("scheme","kyber_768","kyber parameter set")
+	format:=f.String("format","pem","key format: pem, hex, base64 or raw")
+	out:=f.String("out","-","private key output file")
+	pub:=f.String("pub","","also write the public key to this file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	s,err:=kyber_kem.Scheme_by_Name(*scheme_name)
+	if err!=nil{
+		return err
+	}
+	sk,err:=s.Keygen()
+	if err!=nil{
+		return err
+	}
+	data,err:=encode_key(sk.To_Bytes(),s,true,*format)
+	if err!=nil{
+		return err
+	}
+	if err=c.write_file(*out,data,true);err!=nil{
+		return err
+	}
+	if *pub==""{
+		return nil
+	}
+	data,_=encode_key(sk.Public().To_Bytes(),s,false,*format)
+	return c.write_file(*pub,data,false)
+}
+
+func (c *cli)pubkey(args []string)error{
+	f:=c.flags("pubkey")
+	key:=f.String("key","-","private key file")
+	scheme_name:=f.String("scheme","","scheme of a hex, base64 or raw key")
+	format:=f.String("format","pem","key format: pem, hex, base64 or raw")
+	out:=f.String("out","-","public key output file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	sk,err:=c.load_private(*key,*scheme_name)
+	if err!=nil{
+		return err
+	}
+	data,err:=encode_key(sk.Public().To_Bytes(),sk.Scheme(),false,*format)
+	if err!=nil{
+		return err
+	}
+	return c.write_file(*out,data,false)
+}
+
+func (c *cli)encap(args []string)error{
+	f:=c.flags("encap")
+	key:=f.String("key","","public key file")
+	ct:=f.String("ct","","ciphertext output file")
+	scheme_name:=f.String("scheme","","scheme of a hex, base64 or raw key")
+	format:=f.String("format","hex","shared secret format: hex or base64")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *key==""||*ct==""{
+		return errors.New("-key and -ct are required")
+	}
+	pk,err:=c.load_public(*key,*scheme_name)
+	if err!=nil{
+		return err
+	}
+	cipher_text,K,err:=pk.Enc()
+	if err!=nil{
+		return err
+	}
+	secret,err:=encode_secret(K,*format)
+	if err!=nil{
+		return err
+	}
+	if err=c.write_file(*ct,cipher_text,false);err!=nil{
+		return err
+	}
+	_,err=fmt.Fprintln(c.stdout,secret)
+	return err
+}
+
+func (c *cli)decap(args []string)error{
+	f:=c.flags("decap")
+	key:=f.String("key","","private key file")
+	ct:=f.String("ct","-","ciphertext file")
+	scheme_name:=f.String("scheme","","scheme of a hex, base64 or raw key")
+	format:=f.String("format","hex","shared secret format: hex or base64")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *key==""{
+		return errors.New("-key is required")
+	}
+	sk,err:=c.load_private(*key,*scheme_name)
+	if err!=nil{
+		return err
+	}
+	cipher_text,err:=c.read_file(*ct)
+	if err!=nil{
+		return err
+	}
+	if len(cipher_text)!=sk.Scheme().Ct_Len(){
+		return fmt.Errorf("ciphertext is %d bytes, %s needs %d",len(cipher_text),sk.Scheme().Name(),sk.Scheme().Ct_Len())
+	}
+	K,err:=sk.Dec(cipher_text)
+	if err!=nil{
+		return err
+	}
+	secret,err:=encode_secret(K,*format)
+	if err!=nil{
+		return err
+	}
+	_,err=fmt.Fprintln(c.stdout,secret)
+	return err
+}
+
+func payload_aead(file_key []byte)(cipher.AEAD,error){
+	key,err:=kyber_kdf.KMAC256.Derive(file_key,nil,"kyber cli payload",chacha20poly1305.KeySize)
+	if err!=nil{
+		return nil,err
+	}
+	return chacha20poly1305.New(key)
+}
+
+func chunk_nonce(counter uint64,last bool)[]byte{
+	nonce:=make([]byte,chacha20poly1305.NonceSize)
+	for i:=10;i>=0&&counter>0;i--{
+		nonce[i]=byte(counter)
+		counter>>=8
+	}
+	if last{
+		nonce[11]=1
+	}
+	return nonce
+}
+
+//reads one chunk and reports if it is the last one by peeking at the next byte
+func read_chunk(r *bufio.Reader,buf []byte)(n int,last bool,err error){
+	n,err=io.ReadFull(r,buf)
+	switch err{
+	case io.EOF,io.ErrUnexpectedEOF:
+		return n,true,nil
+	case nil:
+		_,err=r.Peek(1)
+		if err==io.EOF{
+			return n,true,nil
+		}
+		return n,false,err
+	}
+	return n,false,err
+}
+
+func (c *cli)encrypt(args []string)error{
+	f:=c.flags("encrypt")
+	key:=f.String("key","","recipient public key file")
+	scheme_name:=f.String("scheme","","scheme of a hex, base64 or raw key")
+	in:=f.String("in","-","input file")
+	out:=f.String("out","-","output file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *key==""{
+		return errors.New("-key is required")
+	}
+	pk,err:=c.load_public(*key,*scheme_name)
+	if err!=nil{
+		return err
+	}
+	file_key:=make([]byte,file_key_len)
+	if _,err=rand.Read(file_key);err!=nil{
+		return err
+	}
+	sealed,err:=kyber_seal.Seal(pk,file_key,[]byte(magic))
+	if err!=nil{
+		return err
+	}
+	aead,err:=payload_aead(file_key)
+	if err!=nil{
+		return err
+	}
+	src,err:=c.open(*in)
+	if err!=nil{
+		return err
+	}
+	defer src.Close()
+	dst,err:=c.create(*out,false)
+	if err!=nil{
+		return err
+	}
+	if _,err=dst.Write(append([]byte(magic),sealed...));err!=nil{
+		dst.Close()
+		return err
+	}
+	r:=bufio.NewReaderSize(src,chunk_len)
+	buf:=make([]byte,chunk_len)
+	for counter:=uint64(0);;counter++{
+		n,last,err:=read_chunk(r,buf)
+		if err!=nil{
+			dst.Close()
+			return err
+		}
+		if _,err=dst.Write(aead.Seal(nil,chunk_nonce(counter,last),buf[:n],nil));err!=nil{
+			dst.Close()
+			return err
+		}
+		if last{
+			return dst.Close()
+		}
+	}
+}
+
+//chunks are written as soon as they are authenticated, so a failure can leave a partial output behind
+func (c *cli)decrypt(args []string)error{
+	f:=c.flags("decrypt")
+	key:=f.String("key","","private key file")
+	scheme_name:=f.String("scheme","","scheme of a hex, base64 or raw key")
+	in:=f.String("in","-","input file")
+	out:=f.String("out","-","output file")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	if *key==""{
+		return errors.New("-key is required")
+	}
+	sk,err:=c.load_private(*key,*scheme_name)
+	if err!=nil{
+		return err
+	}
+	src,err:=c.open(*in)
+	if err!=nil{
+		return err
+	}
+	defer src.Close()
+	r:=bufio.NewReaderSize(src,chunk_len+chacha20poly1305.Overhead)
+	header:=make([]byte,len(magic)+kyber_seal.Overhead(sk.Scheme())+file_key_len)
+	if _,err=io.ReadFull(r,header);err!=nil||string(header[:len(magic)])!=magic{
+		return errors.New("input is not a kyber encrypted file")
+	}
+	file_key,err:=kyber_seal.Open(sk,header[len(magic):],[]byte(magic))
+	if err!=nil{
+		return err
+	}
+	aead,err:=payload_aead(file_key)
+	if err!=nil{
+		return err
+	}
+	dst,err:=c.create(*out,true)
+	if err!=nil{
+		return err
+	}
+	buf:=make([]byte,chunk_len+chacha20poly1305.Overhead)
+	for counter:=uint64(0);;counter++{
+		n,last,err:=read_chunk(r,buf)
+		if err!=nil{
+			dst.Close()
+			return err
+		}
+		plaintext,err:=aead.Open(nil,chunk_nonce(counter,last),buf[:n],nil)
+		if err!=nil||(last&&len(plaintext)==0&&counter>0){
+			dst.Close()
+			return errors.New("payload is corrupted or truncated")
+		}
+		if _,err=dst.Write(plaintext);err!=nil{
+			dst.Close()
+			return err
+		}
+		if last{
+			return dst.Close()
+		}
+	}
+}
+
+func fingerprint(pk []byte)string{
+	sum:=sha3.Sum256(pk)
+	return "SHA3-256:"+hex.EncodeToString(sum[:])
+}
+
+func (c *cli)inspect(args []string)error{
+	f:=c.flags("inspect")
+	if err:=f.Parse(args);err!=nil{
+		return err
+	}
+	path:="-"
+	if f.NArg()>0{
+		path=f.Arg(0)
+	}
+	data,err:=c.read_file(path)
+	if err!=nil{
+		return err
+	}
+	var b strings.Builder
+	switch{
+	case bytes.HasPrefix(data,[]byte(magic)):
+		describe_encrypted(&b,data[len(magic):])
+	case bytes.HasPrefix(data,[]byte("-----BEGIN ")):
+		k,err:=parse_key(data,"")
+		if err!=nil{
+			return err
+		}
+		if err=describe_key(&b,k);err!=nil{
+			return err
+		}
+	default:
+		describe_raw(&b,data)
+	}
+	_,err=io.WriteString(c.stdout,b.String())
+	return err
+}
+
+func describe_key(b *strings.Builder,k *key_file)error{
+	pk:=k.data
+	kind:="public key"
+	if k.private{
+		sk,err:=k.scheme.Bytes_to_Sk(k.data)
+		if err!=nil{
+			return err
+		}
+		pk=sk.Public().To_Bytes()
+		kind="private key"
+	}else if _,err:=k.scheme.Bytes_to_Pk(k.data);err!=nil{
+		return err
+	}
+	fmt.Fprintf(b,"type: %s\nscheme: %s\nencoding: %s\nsize: %d bytes\nfingerprint: %s\n",kind,k.scheme.Name(),k.encoding,len(k.data),fingerprint(pk))
+	return nil
+}
+
+func describe_encrypted(b *strings.Builder,data []byte){
+	fmt.Fprintf(b,"type: encrypted file\n")
+	if len(data)<2||data[0]!=kyber_seal.Version||data[1]==kyber_seal.Scheme_other||int(data[1])>len(kyber_kem.All){
+		fmt.Fprintf(b,"header: unreadable\n")
+		return
+	}
+	s:=kyber_kem.All[data[1]-1]
+	payload:=len(data)-kyber_seal.Overhead(s)-file_key_len
+	fmt.Fprintf(b,"scheme: %s\n",s.Name())
+	if payload<chacha20poly1305.Overhead{
+		fmt.Fprintf(b,"payload: truncated\n")
+		return
+	}
+	chunks:=(payload+chunk_len+chacha20poly1305.Overhead-1)/(chunk_len+chacha20poly1305.Overhead)
+	if chunks==0{
+		chunks=1
+	}
+	fmt.Fprintf(b,"payload: %d bytes\nchunks: %d\n",payload-chunks*chacha20poly1305.Overhead,chunks)
+}
+
+//keys without a PEM header and ciphertexts only have their length to go on
+func describe_raw(b *strings.Builder,data []byte){
+	decoded,encoding:=decode_text(data)
+	fmt.Fprintf(b,"encoding: %s\nsize: %d bytes\n",encoding,len(decoded))
+	found:=false
+	for _,s:=range kyber_kem.All{
+		for _,m:=range []struct{
+			n int
+			kind string
+		}{{s.Sk_Len(),"private key"},{s.Pk_Len(),"public key"},{s.Ct_Len(),"ciphertext"}}{
+			if m.n!=len(decoded){
+				continue
+			}
+			if _,err:=s.Bytes_to_Sk(decoded);m.kind=="private key"&&err!=nil{//the embedded public key hash tells SHAKE and 90s keys apart
+				continue
+			}
+			fmt.Fprintf(b,"possible: %s %s\n",s.Name(),m.kind)
+			found=true
+		}
+	}
+	if len(decoded)>=2&&decoded[0]==kyber_seal.Version&&decoded[1]!=kyber_seal.Scheme_other&&int(decoded[1])<=len(kyber_kem.All){
+		s:=kyber_kem.All[decoded[1]-1]
+		if len(decoded)>=kyber_seal.Overhead(s){
+			fmt.Fprintf(b,"possible: %s sealed box with %d bytes of plaintext\n",s.Name(),len(decoded)-kyber_seal.Overhead(s))
+			found=true
+		}
+	}
+	if !found{
+		fmt.Fprintf(b,"possible: nothing known\n")
+	}
+}
@@ -0,0 +1,220 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the kyber command line tool
+the deterministic commands are checked against golden files in testdata, run go test -update to rewrite them
+*/
+package main
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/rand"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update=flag.Bool("update",false,"rewrite the golden files")
+
+func run_cli(stdin []byte,args ...string)(code int,stdout,stderr string){
+	var out,errs bytes.Buffer
+	code=run(args,bytes.NewReader(stdin),&out,&errs)
+	return code,out.String(),errs.String()
+}
+
+func Test_Golden(t *testing.T){
+	cases:=[]struct{
+		name string
+		args []string
+	}{
+		{"usage",nil},
+		{"unknown_command",[]string{"bogus"}},
+		{"inspect_private_pem",[]string{"inspect","testdata/sk_768.pem"}},
+		{"inspect_public_pem",[]string{"inspect","testdata/pk_768.pem"}},
+		{"inspect_private_hex",[]string{"inspect","testdata/sk_512_90s.hex"}},
+		{"inspect_ciphertext",[]string{"inspect","testdata/ct_768.bin"}},
+		{"inspect_encrypted",[]string{"inspect","testdata/message.enc"}},
+		{"pubkey",[]string{"pubkey","-key","testdata/sk_768.pem"}},
+		{"pubkey_hex_90s",[]string{"pubkey","-key","testdata/sk_512_90s.hex","-format","hex"}},
+		{"pubkey_from_public",[]string{"pubkey","-key","testdata/pk_768.pem"}},
+		{"pubkey_scheme_mismatch",[]string{"pubkey","-key","testdata/sk_768.pem","-scheme","kyber_768_90s"}},
+		{"decap_scheme_mismatch",[]string{"decap","-key","testdata/sk_768.pem","-scheme","kyber_512","-ct","testdata/ct_768.bin"}},
+		{"decap",[]string{"decap","-key","testdata/sk_768.pem","-ct","testdata/ct_768.bin"}},
+		{"decap_base64",[]string{"decap","-key","testdata/sk_768.pem","-ct","testdata/ct_768.bin","-format","base64"}},
+		{"decap_wrong_scheme",[]string{"decap","-key","testdata/sk_512_90s.hex","-ct","testdata/ct_768.bin"}},
+		{"decrypt",[]string{"decrypt","-key","testdata/sk_768.pem","-in","testdata/message.enc"}},
+		{"keygen_bad_scheme",[]string{"keygen","-scheme","kyber_2048"}},
+	}
+	for _,tc:=range cases{
+		code,stdout,stderr:=run_cli(nil,tc.args...)
+		got:=fmt.Sprintf("exit: %d\n--- stdout\n%s--- stderr\n%s",code,stdout,stderr)
+		path:=filepath.Join("testdata",tc.name+".golden")
+		if *update{
+			if err:=os.WriteFile(path,[]byte(got),0644);err!=nil{
+				t.Fatal(err)
+			}
+			continue
+		}
+		want,err:=os.ReadFile(path)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if got!=string(want){
+			t.Fatal(tc.name+" does not match its golden file, got:\n"+got)
+		}
+	}
+}
+
+func Test_Round_Trip(t *testing.T){
+	dir:=t.TempDir()
+	for _,s:=range kyber_kem.All{
+		for _,format:=range []string{"pem","hex","base64","raw"}{
+			sk_path:=filepath.Join(dir,s.Name()+"."+format)
+			pk_path:=sk_path+".pub"
+			if code,_,stderr:=run_cli(nil,"keygen","-scheme",s.Name(),"-format",format,"-out",sk_path,"-pub",pk_path);code!=0{
+				t.Fatal(stderr)
+			}
+			scheme:=[]string{}
+			if format=="raw"||format=="hex"||format=="base64"{//public keys of the 90s variants need the scheme named
+				scheme=[]string{"-scheme",s.Name()}
+			}
+			ct_path:=filepath.Join(dir,"ct")
+			code,enc_secret,stderr:=run_cli(nil,append([]string{"encap","-key",pk_path,"-ct",ct_path},scheme...)...)
+			if code!=0{
+				t.Fatal(stderr)
+			}
+			code,dec_secret,stderr:=run_cli(nil,"decap","-key",sk_path,"-ct",ct_path)
+			if code!=0{
+				t.Fatal(stderr)
+			}
+			if enc_secret!=dec_secret||len(enc_secret)!=65{
+				t.Fatal(s.Name()+" "+format+" shared secrets do not match")
+			}
+		}
+		sk_path:=filepath.Join(dir,s.Name()+".pem")
+		for _,size:=range []int{0,1,chunk_len,chunk_len+1,2*chunk_len+7}{
+			data:=make([]byte,size)
+			rand.Read(data)
+			code,encrypted,stderr:=run_cli(data,"encrypt","-key",sk_path+".pub")
+			if code!=0{
+				t.Fatal(stderr)
+			}
+			code,decrypted,stderr:=run_cli([]byte(encrypted),"decrypt","-key",sk_path)
+			if code!=0{
+				t.Fatal(stderr)
+			}
+			if decrypted!=string(data){
+				t.Fatal(s.Name()+" decrypted file does not match")
+			}
+			if size==2*chunk_len+7{
+				tampered:=[]byte(encrypted)
+				tampered[len(tampered)-1]^=1
+				if code,_,_=run_cli(tampered,"decrypt","-key",sk_path);code==0{
+					t.Fatal(s.Name()+" decrypted a tampered file")
+				}
+				truncated:=encrypted[:len(encrypted)-7-16]
+				if code,_,_=run_cli([]byte(truncated),"decrypt","-key",sk_path);code==0{
+					t.Fatal(s.Name()+" decrypted a file truncated at a chunk boundary")
+				}
+			}
+		}
+	}
+}
+
+func Test_Files(t *testing.T){
+	dir:=t.TempDir()
+	sk_path:=filepath.Join(dir,"sk.pem")
+	if code,_,stderr:=run_cli(nil,"keygen","-out",sk_path);code!=0{
+		t.Fatal(stderr)
+	}
+	if info,err:=os.Stat(sk_path);err!=nil||info.Mode().Perm()!=0600{
+		t.Fatal("private key file is not private")
+	}
+	in,out,back:=filepath.Join(dir,"in"),filepath.Join(dir,"out"),filepath.Join(dir,"back")
+	os.WriteFile(in,[]byte(strings.Repeat("file contents ",10000)),0644)
+	if code,_,stderr:=run_cli(nil,"encrypt","-key",sk_path,"-in",in,"-out",out);code!=0{
+		t.Fatal(stderr)
+	}
+	if code,_,stderr:=run_cli(nil,"decrypt","-key",sk_path,"-in",out,"-out",back);code!=0{
+		t.Fatal(stderr)
+	}
+	a,_:=os.ReadFile(in)
+	b,_:=os.ReadFile(back)
+	if !bytes.Equal(a,b){
+		t.Fatal("decrypted file does not match")
+	}
+	if info,err:=os.Stat(back);err!=nil||info.Mode().Perm()!=0600{
+		t.Fatal("decrypted file is not private")
+	}
+	other:=filepath.Join(dir,"other.pem")
+	run_cli(nil,"keygen","-out",other)
+	if code,_,_:=run_cli(nil,"decrypt","-key",other,"-in",out,"-out",back);code==0{
+		t.Fatal("decrypted with the wrong key")
+	}
+}
+
+func Test_Secrets(t *testing.T){
+	dir:=t.TempDir()
+	alice,bob:=filepath.Join(dir,"alice.pem"),filepath.Join(dir,"bob.pem")
+	for _,path:=range []string{alice,bob}{
+		if code,_,stderr:=run_cli(nil,"keygen","-out",path,"-pub",path+".pub");code!=0{
+			t.Fatal(stderr)
+		}
+	}
+	plain:="{\n  \"api_key\": \"s3cret\",\n  \"port_unencrypted\": 8080\n}\n"
+	code,doc,stderr:=run_cli([]byte(plain),"secrets-encrypt","-recipient",alice+".pub")
+	if code!=0{
+		t.Fatal(stderr)
+	}
+	if strings.Contains(doc,"s3cret")||!strings.Contains(doc,`"port_unencrypted": 8080`){
+		t.Fatal("secrets-encrypt did not encrypt just the values")
+	}
+	if code,_,_=run_cli([]byte(doc),"secrets-decrypt","-key",bob);code==0{
+		t.Fatal("non recipient decrypted the secrets")
+	}
+	code,doc,stderr=run_cli([]byte(doc),"secrets-add","-key",alice,"-recipient",bob+".pub")
+	if code!=0{
+		t.Fatal(stderr)
+	}
+	code,got,stderr:=run_cli([]byte(doc),"secrets-decrypt","-key",bob)
+	if code!=0||got!=plain{
+		t.Fatal("added recipient can not decrypt: "+stderr)
+	}
+	if code,doc,stderr=run_cli([]byte(doc),"secrets-rotate","-key",bob);code!=0{
+		t.Fatal(stderr)
+	}
+	if code,doc,stderr=run_cli([]byte(doc),"secrets-remove","-key",bob,"-recipient",alice+".pub");code!=0{
+		t.Fatal(stderr)
+	}
+	if code,_,_=run_cli([]byte(doc),"secrets-decrypt","-key",alice);code==0{
+		t.Fatal("removed recipient decrypted the secrets")
+	}
+	path:=filepath.Join(dir,"secrets.json")
+	os.WriteFile(filepath.Join(dir,"enc.json"),[]byte(doc),0644)
+	if code,_,stderr=run_cli(nil,"secrets-decrypt","-key",bob,"-in",filepath.Join(dir,"enc.json"),"-out",path);code!=0{
+		t.Fatal(stderr)
+	}
+	if info,err:=os.Stat(path);err!=nil||info.Mode().Perm()!=0600{
+		t.Fatal("decrypted secrets file is not private")
+	}
+	if code,_,stderr=run_cli([]byte(doc),"secrets-add","-key",bob);code==0||!strings.Contains(stderr,"-recipient"){
+		t.Fatal("secrets-add ran without a recipient")
+	}
+	plain="api_key: s3cret\nport_unencrypted: 8080\n"
+	if code,doc,stderr=run_cli([]byte(plain),"secrets-encrypt","-recipient",bob+".pub");code!=0{
+		t.Fatal(stderr)
+	}
+	if strings.Contains(doc,"s3cret")||!strings.Contains(doc,"\nport_unencrypted: 8080\n"){
+		t.Fatal("secrets-encrypt did not keep YAML")
+	}
+	if code,got,stderr=run_cli([]byte(doc),"secrets-decrypt","-key",bob);code!=0||got!=plain{
+		t.Fatal("YAML secrets did not round trip: "+stderr)
+	}
+}
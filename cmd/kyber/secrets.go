@@ -0,0 +1,128 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the secrets commands that encrypt the values of a JSON or YAML config with kyber_secrets and manage its recipients
+*/
+package main
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_secrets"
+	"errors"
+	"flag"
+)
+
+type secrets_flags struct{
+	f *flag.FlagSet
+	key,scheme,in,out *string
+	recipients []string
+}
+
+func (c *cli)secrets_flags(name string,args []string)(*secrets_flags,error){
+	s:=&secrets_flags{f:c.flags(name)}
+	s.key=s.f.String("key","","private key file of a recipient")
+	s.scheme=s.f.String("scheme","","scheme of hex, base64 or raw keys")
+	s.in=s.f.String("in","-","input file")
+	s.out=s.f.String("out","-","output file")
+	s.f.Func("recipient","recipient public key file, can be repeated",func(path string)error{
+		s.recipients=append(s.recipients,path)
+		return nil
+	})
+	return s,s.f.Parse(args)
+}
+
+func (c *cli)secrets_recipients(s *secrets_flags)([]kyber_kem.Public_Key,error){
+	var pks []kyber_kem.Public_Key
+	for _,path:=range s.recipients{
+		pk,err:=c.load_public(path,*s.scheme)
+		if err!=nil{
+			return nil,err
+		}
+		pks=append(pks,pk)
+	}
+	return pks,nil
+}
+
+//runs op on the document from -in with the -key private key and the single -recipient if need_recipient
+func (c *cli)secrets_edit(name string,args []string,need_recipient,private_out bool,op func(doc []byte,sk kyber_kem.Private_Key,pk kyber_kem.Public_Key)([]byte,error))error{
+	s,err:=c.secrets_flags(name,args)
+	if err!=nil{
+		return err
+	}
+	if *s.key==""{
+		return errors.New("-key is required")
+	}
+	if need_recipient!=(len(s.recipients)==1){
+		if need_recipient{
+			return errors.New("exactly one -recipient is required")
+		}
+		return errors.New("-recipient is not used")
+	}
+	sk,err:=c.load_private(*s.key,*s.scheme)
+	if err!=nil{
+		return err
+	}
+	var pk kyber_kem.Public_Key
+	if need_recipient{
+		pks,err:=c.secrets_recipients(s)
+		if err!=nil{
+			return err
+		}
+		pk=pks[0]
+	}
+	doc,err:=c.read_file(*s.in)
+	if err!=nil{
+		return err
+	}
+	out,err:=op(doc,sk,pk)
+	if err!=nil{
+		return err
+	}
+	return c.write_file(*s.out,out,private_out)
+}
+
+func (c *cli)secrets_encrypt(args []string)error{
+	s,err:=c.secrets_flags("secrets-encrypt",args)
+	if err!=nil{
+		return err
+	}
+	if len(s.recipients)==0{
+		return errors.New("at least one -recipient is required")
+	}
+	pks,err:=c.secrets_recipients(s)
+	if err!=nil{
+		return err
+	}
+	plain,err:=c.read_file(*s.in)
+	if err!=nil{
+		return err
+	}
+	doc,err:=kyber_secrets.Encrypt(plain,pks)
+	if err!=nil{
+		return err
+	}
+	return c.write_file(*s.out,doc,false)
+}
+
+func (c *cli)secrets_decrypt(args []string)error{
+	return c.secrets_edit("secrets-decrypt",args,false,true,func(doc []byte,sk kyber_kem.Private_Key,_ kyber_kem.Public_Key)([]byte,error){
+		return kyber_secrets.Decrypt(doc,sk)
+	})
+}
+
+func (c *cli)secrets_add(args []string)error{
+	return c.secrets_edit("secrets-add",args,true,false,kyber_secrets.Add_Recipient)
+}
+
+func (c *cli)secrets_remove(args []string)error{
+	return c.secrets_edit("secrets-remove",args,true,false,kyber_secrets.Remove_Recipient)
+}
+
+func (c *cli)secrets_rotate(args []string)error{
+	return c.secrets_edit("secrets-rotate",args,false,false,func(doc []byte,sk kyber_kem.Private_Key,_ kyber_kem.Public_Key)([]byte,error){
+		return kyber_secrets.Rotate(doc,sk)
+	})
+}
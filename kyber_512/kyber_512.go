@@ -71,6 +71,7 @@ func Bytes_to_Sk(data []byte)(sk *sk_512,err error){
 		err=errors.New("public key mismatch")
 		return
 	}
+	sk.h=bytes32
 	copy(sk.z[:],data[1600:])
 	return
 }
@@ -103,6 +104,7 @@ func Bytes_to_Sk_90s(data []byte)(sk *sk_512_90s,err error){
 		err=errors.New("public key mismatch")
 		return
 	}
+	sk.h=bytes32
 	copy(sk.z[:],data[1600:])
 	return
 }
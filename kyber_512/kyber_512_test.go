@@ -100,6 +100,34 @@ var(
 	bench_ss []byte
 )
 
+//a key loaded from bytes has to decapsulate like the one it was saved from
+func Test_Bytes_to_Sk_512(t *testing.T){
+	sk:=Keygen()
+	data:=sk.To_Bytes()
+	loaded,err:=Bytes_to_Sk(data[:])
+	if err!=nil{
+		t.Fatal(err)
+	}
+	pk,_:=Bytes_to_Pk(sk.Pk_Bytes[:])
+	ct,ss_enc:=pk.Enc(32)
+	ss_dec,err:=loaded.Dec(ct[:],32)
+	if err!=nil||string(ss_dec)!=string(ss_enc){
+		t.Fatal("loaded private key does not decapsulate")
+	}
+	sk_90s:=Keygen_90s()
+	data=sk_90s.To_Bytes()
+	loaded_90s,err:=Bytes_to_Sk_90s(data[:])
+	if err!=nil{
+		t.Fatal(err)
+	}
+	pk_90s,_:=Bytes_to_Pk_90s(sk_90s.Pk_Bytes[:])
+	ct,K_enc:=pk_90s.Enc()
+	K_dec,err:=loaded_90s.Dec(ct[:])
+	if err!=nil||K_dec!=K_enc{
+		t.Fatal("loaded 90s private key does not decapsulate")
+	}
+}
+
 func Benchmark_Keygen_512(b *testing.B){
 	for i:=0;i<b.N;i++{
 		bench_key_512=Keygen()
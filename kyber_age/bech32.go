@@ -0,0 +1,122 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the Bech32 encoding (BIP 173) used for age recipients and identities, without the 90 character limit since post quantum keys are long
+*/
+package kyber_age
+
+import(
+	"errors"
+	"strings"
+)
+
+const bech32_charset="qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32_generator=[5]uint32{0x3b6a57b2,0x26508e6d,0x1ea119fa,0x3d4233dd,0x2a1462b3}
+
+func bech32_polymod(values []byte)uint32{
+	chk:=uint32(1)
+	for _,v:=range values{
+		top:=chk>>25
+		chk=(chk&0x1ffffff)<<5^uint32(v)
+		for i:=0;i<5;i++{
+			if (top>>i)&1==1{
+				chk^=bech32_generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32_hrp_expand(hrp string)[]byte{
+	out:=make([]byte,0,2*len(hrp)+1)
+	for i:=0;i<len(hrp);i++{
+		out=append(out,hrp[i]>>5)
+	}
+	out=append(out,0)
+	for i:=0;i<len(hrp);i++{
+		out=append(out,hrp[i]&31)
+	}
+	return out
+}
+
+func convert_bits(data []byte,from,to uint,pad bool)([]byte,error){
+	var acc,bits uint
+	out:=make([]byte,0,len(data)*int(from)/int(to)+1)
+	max_v:=uint(1)<<to-1
+	for _,b:=range data{
+		if uint(b)>>from!=0{
+			return nil,errors.New("bech32: invalid data range")
+		}
+		acc=acc<<from|uint(b)
+		bits+=from
+		for bits>=to{
+			bits-=to
+			out=append(out,byte(acc>>bits&max_v))
+		}
+	}
+	if pad{
+		if bits>0{
+			out=append(out,byte(acc<<(to-bits)&max_v))
+		}
+	}else if bits>=from||acc<<(to-bits)&max_v!=0{
+		return nil,errors.New("bech32: invalid padding")
+	}
+	return out,nil
+}
+
+//the hrp case is kept for the whole string so identities come out upper case
+func Bech32_Encode(hrp string,data []byte)(string,error){
+	values,err:=convert_bits(data,8,5,true)
+	if err!=nil{
+		return "",err
+	}
+	lower:=strings.ToLower(hrp)
+	polymod:=bech32_polymod(append(append(bech32_hrp_expand(lower),values...),0,0,0,0,0,0))^1
+	var b strings.Builder
+	b.WriteString(lower)
+	b.WriteByte('1')
+	for _,v:=range values{
+		b.WriteByte(bech32_charset[v])
+	}
+	for i:=0;i<6;i++{
+		b.WriteByte(bech32_charset[(polymod>>(5*(5-i)))&31])
+	}
+	if strings.ToUpper(hrp)==hrp{
+		return strings.ToUpper(b.String()),nil
+	}
+	return b.String(),nil
+}
+
+func Bech32_Decode(s string)(hrp string,data []byte,err error){
+	if strings.ToLower(s)!=s&&strings.ToUpper(s)!=s{
+		return "",nil,errors.New("bech32: mixed case")
+	}
+	s=strings.ToLower(s)
+	pos:=strings.LastIndexByte(s,'1')
+	if pos<1||pos+7>len(s){
+		return "",nil,errors.New("bech32: separator in the wrong place")
+	}
+	hrp=s[:pos]
+	for i:=0;i<len(hrp);i++{
+		if hrp[i]<33||hrp[i]>126{
+			return "",nil,errors.New("bech32: invalid character in the hrp")
+		}
+	}
+	values:=make([]byte,0,len(s)-pos-1)
+	for i:=pos+1;i<len(s);i++{
+		v:=strings.IndexByte(bech32_charset,s[i])
+		if v<0{
+			return "",nil,errors.New("bech32: invalid character in the data")
+		}
+		values=append(values,byte(v))
+	}
+	if bech32_polymod(append(bech32_hrp_expand(hrp),values...))!=1{
+		return "",nil,errors.New("bech32: invalid checksum")
+	}
+	data,err=convert_bits(values[:len(values)-6],5,8,false)
+	return
+}
@@ -0,0 +1,445 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the age v1 file format (header, stanzas, header MAC and STREAM payload) with X25519+kyber_768 hybrid recipients
+kyber_768 is the round 3 kyber and not FIPS 203 ML-KEM, so the recipients use their own stanza type and Bech32 prefixes
+and can not be mistaken for the mlkem768x25519 recipients of other age implementations
+
+	-> kyber768x25519 base64(kem ciphertext)
+	base64(ChaCha20-Poly1305(HKDF-SHA256(K,ciphertext||recipient,label),nonce 0,file key))
+*/
+package kyber_age
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hybrid"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+const(
+	intro="age-encryption.org/v1"
+	stanza_prefix="->"
+	footer_prefix="---"
+	column_len=64
+	file_key_len=16
+	nonce_len=16
+	chunk_len=64*1024
+	Hybrid_type="kyber768x25519"
+	hybrid_label="age-encryption.org/v1/kyber768x25519"
+	Recipient_hrp="age1kyber"
+	Identity_hrp="AGE-SECRET-KEY-KYBER-"
+)
+
+var b64=base64.RawStdEncoding.Strict()
+
+var(
+	Err_No_Identity=errors.New("kyber_age: no identity matched any of the recipients")
+	Err_Header=errors.New("kyber_age: malformed header")
+	Err_MAC=errors.New("kyber_age: header MAC is invalid")
+	Err_Payload=errors.New("kyber_age: payload is corrupted or truncated")
+)
+
+var hybrid_scheme kyber_kem.Scheme
+
+func init(){
+	var err error
+	hybrid_scheme,err=kyber_hybrid.New(ecdh.X25519(),kyber_kem.Kyber_768,kyber_kdf.SHA3_256)
+	if err!=nil{
+		panic(err)
+	}
+}
+
+type Stanza struct{
+	Type string
+	Args []string
+	Body []byte
+}
+
+type Recipient interface{
+	Wrap(file_key []byte)([]*Stanza,error)
+}
+
+//Unwrap returns Err_No_Identity when none of the stanzas are for this identity
+type Identity interface{
+	Unwrap(stanzas []*Stanza)([]byte,error)
+}
+
+type Hybrid_Recipient struct{
+	pk kyber_kem.Public_Key
+}
+
+type Hybrid_Identity struct{
+	seed [32]byte
+	sk kyber_kem.Private_Key
+}
+
+func Generate_Hybrid_Identity()(*Hybrid_Identity,error){
+	var seed [32]byte
+	if _,err:=rand.Read(seed[:]);err!=nil{
+		return nil,err
+	}
+	return new_hybrid_identity(seed)
+}
+
+func new_hybrid_identity(seed [32]byte)(*Hybrid_Identity,error){
+	sk,err:=hybrid_scheme.Seed_to_Keys(seed)
+	if err!=nil{
+		return nil,err
+	}
+	return &Hybrid_Identity{seed,sk},nil
+}
+
+func Parse_Hybrid_Identity(s string)(*Hybrid_Identity,error){
+	hrp,data,err:=Bech32_Decode(s)
+	if err!=nil{
+		return nil,err
+	}
+	if hrp!=strings.ToLower(Identity_hrp)||len(data)!=32{
+		return nil,errors.New("kyber_age: not a "+Hybrid_type+" identity")
+	}
+	return new_hybrid_identity([32]byte(data))
+}
+
+func (i *Hybrid_Identity)String()string{
+	s,_:=Bech32_Encode(Identity_hrp,i.seed[:])
+	return s
+}
+
+func (i *Hybrid_Identity)Recipient()*Hybrid_Recipient{
+	return &Hybrid_Recipient{i.sk.Public()}
+}
+
+func Parse_Hybrid_Recipient(s string)(*Hybrid_Recipient,error){
+	hrp,data,err:=Bech32_Decode(s)
+	if err!=nil{
+		return nil,err
+	}
+	if hrp!=Recipient_hrp{
+		return nil,errors.New("kyber_age: not a "+Hybrid_type+" recipient")
+	}
+	pk,err:=hybrid_scheme.Bytes_to_Pk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &Hybrid_Recipient{pk},nil
+}
+
+func (r *Hybrid_Recipient)String()string{
+	s,_:=Bech32_Encode(Recipient_hrp,r.pk.To_Bytes())
+	return s
+}
+
+func wrap_key(K,c,pk []byte)([]byte,error){
+	salt:=append(append([]byte(nil),c...),pk...)
+	key:=make([]byte,chacha20poly1305.KeySize)
+	if _,err:=io.ReadFull(hkdf.New(sha256.New,K,salt,[]byte(hybrid_label)),key);err!=nil{
+		return nil,err
+	}
+	return key,nil
+}
+
+func (r *Hybrid_Recipient)Wrap(file_key []byte)([]*Stanza,error){
+	c,K,err:=r.pk.Enc()
+	if err!=nil{
+		return nil,err
+	}
+	key,err:=wrap_key(K,c,r.pk.To_Bytes())
+	if err!=nil{
+		return nil,err
+	}
+	aead,_:=chacha20poly1305.New(key)
+	body:=aead.Seal(nil,make([]byte,chacha20poly1305.NonceSize),file_key,nil)
+	return []*Stanza{{Type:Hybrid_type,Args:[]string{b64.EncodeToString(c)},Body:body}},nil
+}
+
+func (i *Hybrid_Identity)Unwrap(stanzas []*Stanza)([]byte,error){
+	for _,s:=range stanzas{
+		if s.Type!=Hybrid_type{
+			continue
+		}
+		if len(s.Args)!=1||len(s.Body)!=file_key_len+chacha20poly1305.Overhead{
+			return nil,Err_Header
+		}
+		c,err:=b64.DecodeString(s.Args[0])
+		if err!=nil||len(c)!=hybrid_scheme.Ct_Len(){
+			return nil,Err_Header
+		}
+		K,err:=i.sk.Dec(c)
+		if err!=nil{
+			continue
+		}
+		key,err:=wrap_key(K,c,i.sk.Public().To_Bytes())
+		if err!=nil{
+			return nil,err
+		}
+		aead,_:=chacha20poly1305.New(key)
+		file_key,err:=aead.Open(nil,make([]byte,chacha20poly1305.NonceSize),s.Body,nil)
+		if err==nil{
+			return file_key,nil
+		}
+	}
+	return nil,Err_No_Identity
+}
+
+func (s *Stanza)marshal(w *bytes.Buffer){
+	w.WriteString(stanza_prefix+" "+s.Type)
+	for _,arg:=range s.Args{
+		w.WriteString(" "+arg)
+	}
+	w.WriteByte('\n')
+	body:=b64.EncodeToString(s.Body)
+	for len(body)>=column_len{
+		w.WriteString(body[:column_len]+"\n")
+		body=body[column_len:]
+	}
+	w.WriteString(body+"\n")//the last line is always shorter than a full column, even if empty
+}
+
+func header_mac(file_key,header []byte)[]byte{
+	key:=make([]byte,32)
+	io.ReadFull(hkdf.New(sha256.New,file_key,nil,[]byte("header")),key)
+	mac:=hmac.New(sha256.New,key)
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+func payload_key(file_key,nonce []byte)[]byte{
+	key:=make([]byte,chacha20poly1305.KeySize)
+	io.ReadFull(hkdf.New(sha256.New,file_key,nonce,[]byte("payload")),key)
+	return key
+}
+
+//the returned writer must be closed to write the final chunk
+func Encrypt(dst io.Writer,recipients ...Recipient)(io.WriteCloser,error){
+	if len(recipients)==0{
+		return nil,errors.New("kyber_age: no recipients")
+	}
+	file_key:=make([]byte,file_key_len)
+	if _,err:=rand.Read(file_key);err!=nil{
+		return nil,err
+	}
+	var header bytes.Buffer
+	header.WriteString(intro+"\n")
+	for _,r:=range recipients{
+		stanzas,err:=r.Wrap(file_key)
+		if err!=nil{
+			return nil,err
+		}
+		for _,s:=range stanzas{
+			s.marshal(&header)
+		}
+	}
+	header.WriteString(footer_prefix)
+	mac:=header_mac(file_key,header.Bytes())
+	header.WriteString(" "+b64.EncodeToString(mac)+"\n")
+	nonce:=make([]byte,nonce_len)
+	if _,err:=rand.Read(nonce);err!=nil{
+		return nil,err
+	}
+	header.Write(nonce)
+	if _,err:=dst.Write(header.Bytes());err!=nil{
+		return nil,err
+	}
+	aead,_:=chacha20poly1305.New(payload_key(file_key,nonce))
+	return &stream_writer{dst:dst,aead:aead,buf:make([]byte,0,chunk_len)},nil
+}
+
+type stream_writer struct{
+	dst io.Writer
+	aead interface{
+		Seal(dst,nonce,plaintext,ad []byte)[]byte
+	}
+	buf []byte
+	counter uint64
+	err error
+}
+
+func stream_nonce(counter uint64,last bool)[]byte{
+	nonce:=make([]byte,chacha20poly1305.NonceSize)
+	for i:=10;i>=0&&counter>0;i--{
+		nonce[i]=byte(counter)
+		counter>>=8
+	}
+	if last{
+		nonce[11]=1
+	}
+	return nonce
+}
+
+//a full buffer is only flushed once more data arrives so the last chunk can be marked on close
+func (w *stream_writer)Write(p []byte)(n int,err error){
+	if w.err!=nil{
+		return 0,w.err
+	}
+	for len(p)>0{
+		if len(w.buf)==chunk_len{
+			if w.err=w.flush(false);w.err!=nil{
+				return n,w.err
+			}
+		}
+		m:=copy(w.buf[len(w.buf):chunk_len],p)
+		w.buf=w.buf[:len(w.buf)+m]
+		n+=m
+		p=p[m:]
+	}
+	return
+}
+
+func (w *stream_writer)flush(last bool)error{
+	_,err:=w.dst.Write(w.aead.Seal(nil,stream_nonce(w.counter,last),w.buf,nil))
+	w.counter++
+	w.buf=w.buf[:0]
+	return err
+}
+
+func (w *stream_writer)Close()error{
+	if w.err!=nil{
+		return w.err
+	}
+	w.err=errors.New("kyber_age: writer is closed")
+	return w.flush(true)
+}
+
+func parse_header(r *bufio.Reader)(stanzas []*Stanza,header,mac []byte,err error){
+	var buf bytes.Buffer
+	line,err:=r.ReadString('\n')
+	if err!=nil||line!=intro+"\n"{
+		return nil,nil,nil,Err_Header
+	}
+	buf.WriteString(line)
+	for{
+		if line,err=r.ReadString('\n');err!=nil{
+			return nil,nil,nil,Err_Header
+		}
+		if strings.HasPrefix(line,footer_prefix+" "){
+			buf.WriteString(footer_prefix)
+			if mac,err=b64.DecodeString(strings.TrimSuffix(line[len(footer_prefix)+1:],"\n"));err!=nil{
+				return nil,nil,nil,Err_Header
+			}
+			return stanzas,buf.Bytes(),mac,nil
+		}
+		buf.WriteString(line)
+		fields:=strings.Split(strings.TrimSuffix(line,"\n")," ")
+		if len(fields)<2||fields[0]!=stanza_prefix{
+			return nil,nil,nil,Err_Header
+		}
+		s:=&Stanza{Type:fields[1],Args:fields[2:]}
+		var body strings.Builder
+		for{
+			if line,err=r.ReadString('\n');err!=nil{
+				return nil,nil,nil,Err_Header
+			}
+			buf.WriteString(line)
+			line=strings.TrimSuffix(line,"\n")
+			if len(line)>column_len{
+				return nil,nil,nil,Err_Header
+			}
+			body.WriteString(line)
+			if len(line)<column_len{
+				break
+			}
+		}
+		if s.Body,err=b64.DecodeString(body.String());err!=nil{
+			return nil,nil,nil,Err_Header
+		}
+		stanzas=append(stanzas,s)
+	}
+}
+
+func Decrypt(src io.Reader,identities ...Identity)(io.Reader,error){
+	r:=bufio.NewReader(src)
+	stanzas,header,mac,err:=parse_header(r)
+	if err!=nil{
+		return nil,err
+	}
+	var file_key []byte
+	for _,id:=range identities{
+		file_key,err=id.Unwrap(stanzas)
+		if err==nil{
+			break
+		}
+		if err!=Err_No_Identity{
+			return nil,err
+		}
+	}
+	if file_key==nil{
+		return nil,Err_No_Identity
+	}
+	if !hmac.Equal(header_mac(file_key,header),mac){
+		return nil,Err_MAC
+	}
+	nonce:=make([]byte,nonce_len)
+	if _,err=io.ReadFull(r,nonce);err!=nil{
+		return nil,Err_Payload
+	}
+	aead,_:=chacha20poly1305.New(payload_key(file_key,nonce))
+	return &stream_reader{src:r,aead:aead},nil
+}
+
+type stream_reader struct{
+	src *bufio.Reader
+	aead interface{
+		Open(dst,nonce,ciphertext,ad []byte)([]byte,error)
+	}
+	buf []byte
+	counter uint64
+	done bool
+	err error
+}
+
+func (r *stream_reader)Read(p []byte)(int,error){
+	for len(r.buf)==0{
+		if r.err!=nil{
+			return 0,r.err
+		}
+		if r.done{
+			return 0,io.EOF
+		}
+		r.err=r.next_chunk()
+	}
+	n:=copy(p,r.buf)
+	r.buf=r.buf[n:]
+	return n,nil
+}
+
+func (r *stream_reader)next_chunk()error{
+	chunk:=make([]byte,chunk_len+chacha20poly1305.Overhead)
+	n,err:=io.ReadFull(r.src,chunk)
+	if err==io.EOF||(err==nil&&n==0){
+		return Err_Payload
+	}
+	if err!=nil&&err!=io.ErrUnexpectedEOF{
+		return err
+	}
+	last:=n<len(chunk)
+	if !last{
+		if _,err=r.src.Peek(1);err==io.EOF{
+			last=true
+		}
+	}
+	r.buf,err=r.aead.Open(nil,stream_nonce(r.counter,last),chunk[:n],nil)
+	if err!=nil{
+		return Err_Payload
+	}
+	if last&&len(r.buf)==0&&r.counter>0{//only an empty file may end with an empty chunk
+		return Err_Payload
+	}
+	r.counter++
+	r.done=last
+	return nil
+}
@@ -0,0 +1,142 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the age file format and hybrid recipients
+*/
+package kyber_age
+
+import(
+	"crypto/rand"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_Bech32(t *testing.T){//BIP 173 test vectors
+	for _,s:=range []string{"A12UEL5L","abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw","split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w"}{
+		hrp,data,err:=Bech32_Decode(s)
+		if err!=nil{
+			t.Fatal(s+": "+err.Error())
+		}
+		if strings.ToUpper(s)==s{
+			hrp=strings.ToUpper(hrp)
+		}
+		out,err:=Bech32_Encode(hrp,data)
+		if err!=nil||out!=s{
+			t.Fatal(s+" did not round trip")
+		}
+	}
+	for _,s:=range []string{"A12UEL5l","a12uel5m","1qzzfhee","a1"}{
+		if _,_,err:=Bech32_Decode(s);err==nil{
+			t.Fatal(s+" should not decode")
+		}
+	}
+}
+
+func Test_Keys(t *testing.T){
+	id,err:=Generate_Hybrid_Identity()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id.String(),Identity_hrp+"1")||!strings.HasPrefix(id.Recipient().String(),Recipient_hrp+"1"){
+		t.Fatal("keys have the wrong prefixes")
+	}
+	parsed,err:=Parse_Hybrid_Identity(id.String())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if parsed.Recipient().String()!=id.Recipient().String(){
+		t.Fatal("identity did not reproduce its recipient")
+	}
+	r,err:=Parse_Hybrid_Recipient(id.Recipient().String())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if r.String()!=id.Recipient().String(){
+		t.Fatal("recipient did not round trip")
+	}
+	if _,err=Parse_Hybrid_Recipient(id.String());err==nil{
+		t.Fatal("identity parsed as a recipient")
+	}
+}
+
+func encrypt(t *testing.T,data []byte,recipients ...Recipient)[]byte{
+	var out bytes.Buffer
+	w,err:=Encrypt(&out,recipients...)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=w.Write(data);err!=nil{
+		t.Fatal(err)
+	}
+	if err=w.Close();err!=nil{
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func decrypt(file []byte,identities ...Identity)([]byte,error){
+	r,err:=Decrypt(bytes.NewReader(file),identities...)
+	if err!=nil{
+		return nil,err
+	}
+	return io.ReadAll(r)
+}
+
+func Test_Multi_Recipient(t *testing.T){
+	var ids []*Hybrid_Identity
+	var recipients []Recipient
+	for i:=0;i<3;i++{
+		id,_:=Generate_Hybrid_Identity()
+		ids=append(ids,id)
+		recipients=append(recipients,id.Recipient())
+	}
+	for _,size:=range []int{0,1,chunk_len-1,chunk_len,chunk_len+1,3*chunk_len+100}{
+		data:=make([]byte,size)
+		rand.Read(data)
+		file:=encrypt(t,data,recipients...)
+		for _,id:=range ids{
+			got,err:=decrypt(file,id)
+			if err!=nil{
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got,data){
+				t.Fatal("decrypted payload does not match")
+			}
+		}
+	}
+	outsider,_:=Generate_Hybrid_Identity()
+	if _,err:=decrypt(encrypt(t,[]byte("x"),recipients...),outsider);err!=Err_No_Identity{
+		t.Fatal("file decrypted without a matching identity")
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	id,_:=Generate_Hybrid_Identity()
+	data:=make([]byte,2*chunk_len+5)
+	rand.Read(data)
+	file:=encrypt(t,data,id.Recipient())
+	header_end:=bytes.Index(file,[]byte("\n"+footer_prefix+" "))+1
+	tampered:=bytes.Replace(file,[]byte(intro+"\n"),[]byte(intro+"\n-> extra\n\n"),1)
+	if _,err:=decrypt(tampered,id);err!=Err_MAC{
+		t.Fatal("header with an extra stanza was accepted")
+	}
+	tampered=append([]byte(nil),file...)
+	tampered[header_end+5]^=1
+	if _,err:=decrypt(tampered,id);err==nil{
+		t.Fatal("header with a bad MAC was accepted")
+	}
+	tampered=append([]byte(nil),file...)
+	tampered[len(tampered)-1]^=1
+	if _,err:=decrypt(tampered,id);err!=Err_Payload{
+		t.Fatal("tampered payload was accepted")
+	}
+	last_chunk:=(len(data)%chunk_len)+16
+	if _,err:=decrypt(file[:len(file)-last_chunk],id);err!=Err_Payload{
+		t.Fatal("payload truncated at a chunk boundary was accepted")
+	}
+}
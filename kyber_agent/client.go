@@ -0,0 +1,165 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the client of the agent, a Remote_Key decapsulates through the agent with the same Dec as sk_768 so it can stand in for a local key
+*/
+package kyber_agent
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/binary"
+	"net"
+	"sync"
+)
+
+//the Dec of sk_512, sk_768 and sk_1024
+type Decapsulator interface{
+	Dec(c []byte,Shared_key_length int)(K []byte,err error)
+}
+
+type Client struct{
+	mu sync.Mutex
+	conn net.Conn
+}
+
+type Remote_Key struct{
+	client *Client
+	name string
+	pk kyber_kem.Public_Key
+}
+
+func Dial(path string)(*Client,error){
+	conn,err:=net.Dial("unix",path)
+	if err!=nil{
+		return nil,err
+	}
+	return New_Client(conn),nil
+}
+
+func New_Client(conn net.Conn)*Client{
+	return &Client{conn:conn}
+}
+
+func (c *Client)Close()error{
+	return c.conn.Close()
+}
+
+func (c *Client)round_trip(typ byte,body []byte,want byte)([]byte,error){
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err:=write_frame(c.conn,typ,body);err!=nil{
+		return nil,err
+	}
+	typ,body,err:=read_frame(c.conn)
+	if err!=nil{
+		return nil,err
+	}
+	if typ==msg_failure{
+		if len(body)==1&&code_errors[body[0]]!=nil{
+			return nil,code_errors[body[0]]
+		}
+		return nil,Err_Format
+	}
+	if typ!=want{
+		return nil,Err_Format
+	}
+	return body,nil
+}
+
+//the keys this client may use
+func (c *Client)List()([]*Remote_Key,error){
+	body,err:=c.round_trip(msg_list,nil,msg_keys)
+	if err!=nil{
+		return nil,err
+	}
+	if len(body)<2{
+		return nil,Err_Format
+	}
+	count:=int(binary.BigEndian.Uint16(body))
+	body=body[2:]
+	var keys []*Remote_Key
+	for i:=0;i<count;i++{
+		var name,scheme,pk []byte
+		var ok bool
+		if name,body,ok=get_string(body);!ok{
+			return nil,Err_Format
+		}
+		if scheme,body,ok=get_string(body);!ok{
+			return nil,Err_Format
+		}
+		if pk,body,ok=get_string(body);!ok{
+			return nil,Err_Format
+		}
+		s,err:=kyber_kem.Scheme_by_Name(string(scheme))
+		if err!=nil{
+			return nil,err
+		}
+		public,err:=s.Bytes_to_Pk(pk)
+		if err!=nil{
+			return nil,err
+		}
+		keys=append(keys,&Remote_Key{c,string(name),public})
+	}
+	if len(body)!=0{
+		return nil,Err_Format
+	}
+	return keys,nil
+}
+
+func (c *Client)Key(name string)(*Remote_Key,error){
+	keys,err:=c.List()
+	if err!=nil{
+		return nil,err
+	}
+	for _,k:=range keys{
+		if k.name==name{
+			return k,nil
+		}
+	}
+	return nil,Err_No_Key
+}
+
+func (k *Remote_Key)Name()string{
+	return k.name
+}
+
+func (k *Remote_Key)Scheme()kyber_kem.Scheme{
+	return k.pk.Scheme()
+}
+
+func (k *Remote_Key)Public()kyber_kem.Public_Key{
+	return k.pk
+}
+
+func (k *Remote_Key)Dec(c []byte,Shared_key_length int)(K []byte,err error){
+	if Shared_key_length<=0||Shared_key_length>Max_Shared_Len||len(c)+len(k.name)+5>Max_Frame_Len{
+		return nil,Err_Format
+	}
+	body:=put_string(nil,[]byte(k.name))
+	body=append(binary.BigEndian.AppendUint16(body,uint16(Shared_key_length)),c...)
+	K,err=k.client.round_trip(msg_dec,body,msg_shared)
+	if err==nil&&len(K)!=Shared_key_length{
+		return nil,Err_Format
+	}
+	return
+}
+
+type remote_private_key struct{
+	*Remote_Key
+}
+
+//the key as a kyber_kem.Private_Key for code written against that interface, To_Bytes is nil since the private key never leaves the agent
+func (k *Remote_Key)Private_Key()kyber_kem.Private_Key{
+	return remote_private_key{k}
+}
+
+func (k remote_private_key)To_Bytes()[]byte{
+	return nil
+}
+
+func (k remote_private_key)Dec(c []byte)([]byte,error){
+	return k.Remote_Key.Dec(c,k.Scheme().Ss_Len())
+}
@@ -0,0 +1,287 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a key agent that holds kyber private keys in one process and decapsulates for clients over a Unix socket
+every key has an access list of user and group ids checked against the peer credentials of the connection, an empty list lets in anyone who can open the socket
+the socket of Listen is only open to the agent's own user, to serve other users the agent listens with Listen_Shared on a socket
+its group or everyone can open, for example mode 0660 and a group the client users are in, and the access lists keep them to their own keys
+a key marked Confirm is only used after the Confirm hook agrees, with no hook it is never used, every request is passed to the Audit hook
+both hooks are set before Serve and are called from the connection goroutines
+*/
+package kyber_agent
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_1024"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_512"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_768"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+var Err_Key=errors.New("kyber_agent: key name is empty, too long or already taken")
+
+//credentials of the process on the other end of a connection, -1 where they are unknown
+type Peer struct{
+	UID,GID,PID int
+	Groups []int//supplementary groups, nil where they are unknown
+}
+
+type Key struct{
+	Name string
+	Private_Key kyber_kem.Private_Key
+	UIDs,GIDs []int
+	Confirm bool
+}
+
+type Event struct{
+	Time time.Time
+	Peer Peer
+	Op string
+	Key string
+	Result string
+}
+
+type agent_key struct{
+	Key
+	dec func(c []byte,n int)([]byte,error)
+}
+
+type Agent struct{
+	Confirm func(key string,p Peer)bool
+	Audit func(e Event)
+	mu sync.RWMutex
+	keys map[string]*agent_key
+	names []string
+}
+
+func New()*Agent{
+	return &Agent{keys:map[string]*agent_key{}}
+}
+
+//the SHAKE schemes give any length of shared key like sk_768.Dec, the 90s schemes only their own
+func shared_dec(sk kyber_kem.Private_Key)(func(c []byte,n int)([]byte,error),error){
+	data:=sk.To_Bytes()
+	switch sk.Scheme(){
+	case kyber_kem.Kyber_512:
+		s,err:=kyber_512.Bytes_to_Sk(data)
+		if err!=nil{
+			return nil,err
+		}
+		return s.Dec,nil
+	case kyber_kem.Kyber_768:
+		s,err:=kyber_768.Bytes_to_Sk(data)
+		if err!=nil{
+			return nil,err
+		}
+		return s.Dec,nil
+	case kyber_kem.Kyber_1024:
+		s,err:=kyber_1024.Bytes_to_Sk(data)
+		if err!=nil{
+			return nil,err
+		}
+		return s.Dec,nil
+	}
+	return func(c []byte,n int)([]byte,error){
+		if n!=sk.Scheme().Ss_Len(){
+			return nil,Err_Format
+		}
+		return sk.Dec(c)
+	},nil
+}
+
+func (a *Agent)Add(k Key)error{
+	if k.Name==""||len(k.Name)>255||k.Private_Key==nil{
+		return Err_Key
+	}
+	dec,err:=shared_dec(k.Private_Key)
+	if err!=nil{
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys[k.Name]!=nil{
+		return Err_Key
+	}
+	a.keys[k.Name]=&agent_key{k,dec}
+	a.names=append(a.names,k.Name)
+	return nil
+}
+
+func (a *Agent)Remove(name string)bool{
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys[name]==nil{
+		return false
+	}
+	delete(a.keys,name)
+	a.names=slices.DeleteFunc(a.names,func(n string)bool{return n==name})
+	return true
+}
+
+func (k *agent_key)allowed(p Peer)bool{
+	if len(k.UIDs)==0&&len(k.GIDs)==0{
+		return true
+	}
+	if (p.UID>=0&&slices.Contains(k.UIDs,p.UID))||(p.GID>=0&&slices.Contains(k.GIDs,p.GID)){
+		return true
+	}
+	//a gid matches the primary group or any supplementary one, the same as file permissions
+	for _,g:=range p.Groups{
+		if slices.Contains(k.GIDs,g){
+			return true
+		}
+	}
+	return false
+}
+
+//listens on a new socket only its owner can open
+func Listen(path string)(net.Listener,error){
+	return Listen_Shared(path,0600,-1)
+}
+
+//listens on a new socket with the permission bits of mode, handed to group gid unless it is -1
+func Listen_Shared(path string,mode os.FileMode,gid int)(net.Listener,error){
+	if mode&^os.ModePerm!=0{
+		return nil,errors.New("kyber_agent: socket mode has bits other than permissions")
+	}
+	l,err:=net.Listen("unix",path)
+	if err!=nil{
+		return nil,err
+	}
+	if gid>=0{
+		err=os.Chown(path,-1,gid)
+	}
+	if err==nil{
+		err=os.Chmod(path,mode)
+	}
+	if err!=nil{
+		l.Close()
+		return nil,err
+	}
+	return l,nil
+}
+
+//serves every connection on l until it is closed
+func (a *Agent)Serve(l net.Listener)error{
+	for{
+		conn,err:=l.Accept()
+		if err!=nil{
+			return err
+		}
+		go a.Serve_Conn(conn)
+	}
+}
+
+func (a *Agent)Serve_Conn(conn net.Conn){
+	defer conn.Close()
+	p:=peer_of(conn)
+	for{
+		typ,body,err:=read_frame(conn)
+		if err!=nil{
+			if err==Err_Format{
+				a.audit(p,"frame","","malformed")
+			}
+			return
+		}
+		typ,body=a.handle(p,typ,body)
+		if write_frame(conn,typ,body)!=nil{
+			return
+		}
+	}
+}
+
+func (a *Agent)audit(p Peer,op,key,result string){
+	if a.Audit!=nil{
+		a.Audit(Event{time.Now(),p,op,key,result})
+	}
+}
+
+func failure(code byte)(byte,[]byte){
+	return msg_failure,[]byte{code}
+}
+
+func (a *Agent)handle(p Peer,typ byte,body []byte)(byte,[]byte){
+	switch typ{
+	case msg_list:
+		if len(body)!=0{
+			a.audit(p,"list","","malformed")
+			return failure(code_format)
+		}
+		a.mu.RLock()
+		var list []byte
+		count:=0
+		for _,name:=range a.names{
+			k:=a.keys[name]
+			if !k.allowed(p){
+				continue
+			}
+			list=put_string(list,[]byte(name))
+			list=put_string(list,[]byte(k.Private_Key.Scheme().Name()))
+			list=put_string(list,k.Private_Key.Public().To_Bytes())
+			count++
+		}
+		a.mu.RUnlock()
+		a.audit(p,"list","","ok")
+		return msg_keys,append(binary.BigEndian.AppendUint16(nil,uint16(count)),list...)
+	case msg_dec:
+		name,rest,ok:=get_string(body)
+		if !ok||len(rest)<2{
+			a.audit(p,"dec","","malformed")
+			return failure(code_format)
+		}
+		n,c:=int(binary.BigEndian.Uint16(rest)),rest[2:]
+		a.mu.RLock()
+		k:=a.keys[string(name)]
+		a.mu.RUnlock()
+		if k==nil{
+			a.audit(p,"dec",string(name),"no key")
+			return failure(code_no_key)
+		}
+		if !k.allowed(p){
+			a.audit(p,"dec",string(name),"denied")
+			return failure(code_no_key)
+		}
+		if n==0||n>Max_Shared_Len{
+			a.audit(p,"dec",string(name),"malformed")
+			return failure(code_format)
+		}
+		if k.Confirm&&(a.Confirm==nil||!a.Confirm(k.Name,p)){
+			a.audit(p,"dec",string(name),"not confirmed")
+			return failure(code_denied)
+		}
+		K,err:=k.dec(c,n)
+		if err==Err_Format{
+			a.audit(p,"dec",string(name),"malformed")
+			return failure(code_format)
+		}
+		if err!=nil{
+			a.audit(p,"dec",string(name),"failed")
+			return failure(code_failed)
+		}
+		a.audit(p,"dec",string(name),"ok")
+		return msg_shared,K
+	}
+	a.audit(p,fmt.Sprintf("type %d",typ),"","malformed")
+	return failure(code_format)
+}
+
+//an Audit hook that writes one line per event, safe for concurrent connections
+func Audit_Log(w io.Writer)func(e Event){
+	var mu sync.Mutex
+	return func(e Event){
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w,"%s uid=%d gid=%d pid=%d op=%q key=%q result=%q\n",e.Time.UTC().Format(time.RFC3339),e.Peer.UID,e.Peer.GID,e.Peer.PID,e.Op,e.Key,e.Result)
+	}
+}
@@ -0,0 +1,334 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the agent over a Unix socket, access lists, confirmation, the audit log and encrypted key files
+*/
+package kyber_agent
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_768"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var _ Decapsulator=kyber_768.Keygen()
+var _ Decapsulator=(*Remote_Key)(nil)
+
+func start(t *testing.T,a *Agent)string{
+	path:=filepath.Join(t.TempDir(),"agent.sock")
+	l,err:=Listen(path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	t.Cleanup(func(){l.Close()})
+	go a.Serve(l)
+	return path
+}
+
+func dial(t *testing.T,path string)*Client{
+	c,err:=Dial(path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	t.Cleanup(func(){c.Close()})
+	return c
+}
+
+//the hooks run on the connection goroutines
+type recorder struct{
+	mu sync.Mutex
+	events []Event
+	confirm bool
+	asked Peer
+}
+
+func (r *recorder)audit(e Event){
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events=append(r.events,e)
+}
+
+func (r *recorder)list()[]Event{
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil),r.events...)
+}
+
+func (r *recorder)confirmer(key string,p Peer)bool{
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.asked=p
+	return r.confirm
+}
+
+func Test_Dec(t *testing.T){
+	a:=New()
+	r:=&recorder{}
+	a.Audit=r.audit
+	var sks []kyber_kem.Private_Key
+	for _,s:=range kyber_kem.All{
+		sk,_:=s.Keygen()
+		sks=append(sks,sk)
+		if err:=a.Add(Key{Name:s.Name(),Private_Key:sk});err!=nil{
+			t.Fatal(err)
+		}
+	}
+	if a.Add(Key{Name:"kyber_768",Private_Key:sks[0]})!=Err_Key{
+		t.Fatal("key name was taken twice")
+	}
+	path:=start(t,a)
+	if info,err:=os.Stat(path);err!=nil||info.Mode().Perm()!=0600{
+		t.Fatal("socket is open to other users")
+	}
+	c:=dial(t,path)
+	keys,err:=c.List()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(keys)!=len(sks){
+		t.Fatal("wrong number of keys listed")
+	}
+	for i,k:=range keys{
+		if k.Name()!=sks[i].Scheme().Name()||!bytes.Equal(k.Public().To_Bytes(),sks[i].Public().To_Bytes()){
+			t.Fatal("listed key does not match "+sks[i].Scheme().Name())
+		}
+		ct,K,_:=k.Public().Enc()
+		got,err:=k.Private_Key().Dec(ct)
+		if err!=nil||!bytes.Equal(got,K){
+			t.Fatal(k.Name()+" agent key does not decapsulate")
+		}
+		if k.Private_Key().To_Bytes()!=nil{
+			t.Fatal("remote key gave out private bytes")
+		}
+	}
+	//other lengths match sk_768.Dec
+	local,_:=kyber_768.Bytes_to_Sk(sks[1].To_Bytes())
+	ct,_,_:=sks[1].Public().Enc()
+	for _,n:=range []int{1,16,64,Max_Shared_Len}{
+		want,_:=local.Dec(ct,n)
+		got,err:=keys[1].Dec(ct,n)
+		if err!=nil||!bytes.Equal(got,want){
+			t.Fatalf("length %d does not match sk_768",n)
+		}
+	}
+	if _,err=keys[4].Dec(ct,64);err!=Err_Format{
+		t.Fatal("90s key gave a shared key of its own length")
+	}
+	if _,err=keys[1].Dec(ct[:100],32);err!=Err_Failed{
+		t.Fatal("short ciphertext was decapsulated")
+	}
+	a.Remove("kyber_768")
+	if _,err=keys[1].Dec(ct,32);err!=Err_No_Key{
+		t.Fatal("removed key was used")
+	}
+	if _,err=c.Key("kyber_768");err!=Err_No_Key{
+		t.Fatal("removed key was listed")
+	}
+	events:=r.list()
+	first:=events[0]
+	if first.Op!="list"||first.Result!="ok"{
+		t.Fatal("list was not audited")
+	}
+	if events[len(events)-1].Op!="list"||events[len(events)-2].Result!="no key"{
+		t.Fatal("use of a removed key was not audited")
+	}
+	if runtime.GOOS=="linux"&&(first.Peer.UID!=os.Getuid()||first.Peer.PID!=os.Getpid()){
+		t.Fatal("audit does not name the peer")
+	}
+	var log bytes.Buffer
+	Audit_Log(&log)(first)
+	if !strings.Contains(log.String(),"uid="+strconv.Itoa(first.Peer.UID)+" ")||!strings.HasSuffix(log.String(),` op="list" key="" result="ok"`+"\n"){
+		t.Fatal("audit log line is wrong: "+log.String())
+	}
+}
+
+func Test_Access(t *testing.T){
+	if runtime.GOOS!="linux"{
+		t.Skip("peer credentials are only read on linux")
+	}
+	a:=New()
+	r:=&recorder{}
+	a.Audit=r.audit
+	a.Confirm=r.confirmer
+	sk,_:=kyber_kem.Kyber_768.Keygen()
+	a.Add(Key{Name:"mine",Private_Key:sk,UIDs:[]int{os.Getuid()}})
+	a.Add(Key{Name:"group",Private_Key:sk,GIDs:[]int{os.Getgid()}})
+	a.Add(Key{Name:"other",Private_Key:sk,UIDs:[]int{os.Getuid()+1},GIDs:[]int{os.Getgid()+1}})
+	a.Add(Key{Name:"confirm",Private_Key:sk,Confirm:true})
+	c:=dial(t,start(t,a))
+	keys,_:=c.List()
+	var names []string
+	for _,k:=range keys{
+		names=append(names,k.Name())
+	}
+	if strings.Join(names,",")!="mine,group,confirm"{
+		t.Fatal("list shows keys the peer may not use: "+strings.Join(names,","))
+	}
+	ct,K,_:=sk.Public().Enc()
+	other:=&Remote_Key{c,"other",sk.Public()}
+	if _,err:=other.Dec(ct,32);err!=Err_No_Key{
+		t.Fatal("key outside the access list was used")
+	}
+	if events:=r.list();events[len(events)-1].Result!="denied"{
+		t.Fatal("denial was not audited")
+	}
+	if _,err:=keys[2].Dec(ct,32);err!=Err_Denied{
+		t.Fatal("key was used without confirmation")
+	}
+	r.mu.Lock()
+	if r.asked.UID!=os.Getuid()||r.asked.PID!=os.Getpid(){
+		t.Fatal("confirmation was not told the peer")
+	}
+	r.confirm=true
+	r.mu.Unlock()
+	for _,k:=range keys{
+		if got,err:=k.Dec(ct,32);err!=nil||!bytes.Equal(got,K){
+			t.Fatal(k.Name()+" was refused")
+		}
+	}
+	unhooked:=New()
+	unhooked.Add(Key{Name:"confirm",Private_Key:sk,Confirm:true})
+	k,err:=dial(t,start(t,unhooked)).Key("confirm")
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=k.Dec(ct,32);err!=Err_Denied{
+		t.Fatal("key was used with no confirmation hook")
+	}
+}
+
+//on a socket other users can open the access lists are all that keeps a key from them
+func Test_Shared(t *testing.T){
+	path:=filepath.Join(t.TempDir(),"agent.sock")
+	l,err:=Listen_Shared(path,0660,os.Getgid())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if info,err:=os.Stat(path);err!=nil||info.Mode().Perm()!=0660{
+		t.Fatal("socket mode was not applied")
+	}
+	if _,err=Listen_Shared(filepath.Join(t.TempDir(),"bad.sock"),os.ModeSetuid|0660,-1);err==nil{
+		t.Fatal("socket mode with a setuid bit was accepted")
+	}
+	a:=New()
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	a.Add(Key{Name:"web",Private_Key:sk,UIDs:[]int{33}})
+	a.Add(Key{Name:"ops",Private_Key:sk,GIDs:[]int{4242}})
+	a.Add(Key{Name:"open",Private_Key:sk})
+	ct,K,_:=sk.Public().Enc()
+	for _,tc:=range []struct{
+		p Peer
+		keys string
+	}{
+		{Peer{1000,1000,1,nil},"open"},
+		{Peer{33,1000,1,nil},"web,open"},
+		{Peer{4242,4242,1,nil},"ops,open"},
+		{Peer{1000,1000,1,[]int{27,4242}},"ops,open"},
+		{Peer{-1,-1,-1,nil},"open"},
+	}{
+		typ,body:=a.handle(tc.p,msg_list,nil)
+		if typ!=msg_keys||binary.BigEndian.Uint16(body)!=uint16(strings.Count(tc.keys,",")+1){
+			t.Fatalf("uid %d gid %d is listed the wrong keys",tc.p.UID,tc.p.GID)
+		}
+		for _,name:=range []string{"web","ops","open"}{
+			typ,body=a.handle(tc.p,msg_dec,append(binary.BigEndian.AppendUint16(put_string(nil,[]byte(name)),32),ct...))
+			if slices.Contains(strings.Split(tc.keys,","),name){
+				if typ!=msg_shared||!bytes.Equal(body,K){
+					t.Fatalf("uid %d gid %d was refused %s",tc.p.UID,tc.p.GID,name)
+				}
+			}else if typ!=msg_failure||!bytes.Equal(body,[]byte{code_no_key}){
+				t.Fatalf("uid %d gid %d was given %s",tc.p.UID,tc.p.GID,name)
+			}
+		}
+	}
+}
+
+func Test_Malformed(t *testing.T){
+	a:=New()
+	r:=&recorder{}
+	a.Audit=r.audit
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	a.Add(Key{Name:"k",Private_Key:sk})
+	path:=start(t,a)
+	conn,err:=net.Dial("unix",path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _,req:=range [][]byte{{9},{msg_list,0},{msg_dec,0,5,'k'},{msg_dec,0,1,'k',0,0}}{
+		if err=write_frame(conn,req[0],req[1:]);err!=nil{
+			t.Fatal(err)
+		}
+		typ,body,err:=read_frame(conn)
+		if err!=nil||typ!=msg_failure||!bytes.Equal(body,[]byte{code_format}){
+			t.Fatalf("request %x was not refused as malformed",req)
+		}
+	}
+	//a frame over the limit ends the connection
+	conn.Write([]byte{0xff,0xff,0xff,0xff})
+	if _,_,err=read_frame(conn);err==nil{
+		t.Fatal("connection survived an oversized frame")
+	}
+	for _,e:=range r.list(){
+		if e.Result!="malformed"{
+			t.Fatal("malformed request audited as "+e.Result)
+		}
+	}
+}
+
+func Test_Key_File(t *testing.T){
+	sk,_:=kyber_kem.Kyber_1024_90s.Keygen()
+	data,err:=Encrypt_Key_File(sk,[]byte("correct horse"))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if bytes.Contains(data,[]byte(pem_private+"-")){
+		t.Fatal("encrypted key file is marked plain")
+	}
+	pass:=func(p string)func()([]byte,error){
+		return func()([]byte,error){return []byte(p),nil}
+	}
+	got,err:=Load_Key_File(data,pass("correct horse"))
+	if err!=nil||!bytes.Equal(got.To_Bytes(),sk.To_Bytes())||got.Scheme()!=sk.Scheme(){
+		t.Fatal("encrypted key file did not load")
+	}
+	if _,err=Load_Key_File(data,pass("wrong"));err!=Err_Passphrase{
+		t.Fatal("key file opened with the wrong passphrase")
+	}
+	//the scheme header is bound to the key
+	swapped:=bytes.Replace(data,[]byte("kyber_1024_90s"),[]byte("kyber_1024"),1)
+	if _,err=Load_Key_File(swapped,pass("correct horse"));err!=Err_Passphrase{
+		t.Fatal("key file loaded under another scheme")
+	}
+	stop:=errors.New("no terminal")
+	if _,err=Load_Key_File(data,func()([]byte,error){return nil,stop});err!=stop{
+		t.Fatal("passphrase error was lost")
+	}
+	plain:=pem.EncodeToMemory(&pem.Block{Type:pem_private,Headers:map[string]string{"Scheme":"kyber_1024_90s"},Bytes:sk.To_Bytes()})
+	got,err=Load_Key_File(plain,func()([]byte,error){
+		t.Fatal("passphrase asked for a plain key")
+		return nil,nil
+	})
+	if err!=nil||!bytes.Equal(got.To_Bytes(),sk.To_Bytes()){
+		t.Fatal("plain key file did not load")
+	}
+	if _,err=Load_Key_File([]byte("not a key"),nil);err!=Err_Key_File{
+		t.Fatal("garbage loaded as a key file")
+	}
+}
@@ -0,0 +1,68 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the peer credentials of a Unix socket connection on linux, read with SO_PEERCRED, and the supplementary groups of the peer from /proc
+*/
+package kyber_agent
+
+import(
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func peer_of(conn net.Conn)Peer{
+	p:=Peer{-1,-1,-1,nil}
+	unix,ok:=conn.(*net.UnixConn)
+	if !ok{
+		return p
+	}
+	raw,err:=unix.SyscallConn()
+	if err!=nil{
+		return p
+	}
+	raw.Control(func(fd uintptr){
+		cred,err:=syscall.GetsockoptUcred(int(fd),syscall.SOL_SOCKET,syscall.SO_PEERCRED)
+		if err==nil{
+			p=Peer{int(cred.Uid),int(cred.Gid),int(cred.Pid),nil}
+		}
+	})
+	if p.PID>0{
+		if status,err:=os.ReadFile("/proc/"+strconv.Itoa(p.PID)+"/status");err==nil{
+			p.Groups=status_groups(string(status),p.UID)
+		}
+	}
+	return p
+}
+
+//the Groups line of /proc/<pid>/status, nil unless the real uid there is the one SO_PEERCRED gave so a reused pid is not trusted
+func status_groups(status string,uid int)[]int{
+	var groups []int
+	uid_ok:=false
+	for _,line:=range strings.Split(status,"\n"){
+		name,value,_:=strings.Cut(line,":")
+		fields:=strings.Fields(value)
+		switch name{
+		case "Uid":
+			uid_ok=len(fields)>0&&fields[0]==strconv.Itoa(uid)
+		case "Groups":
+			groups=[]int{}
+			for _,f:=range fields{
+				g,err:=strconv.Atoi(f)
+				if err!=nil||g<0{
+					return nil
+				}
+				groups=append(groups,g)
+			}
+		}
+	}
+	if !uid_ok{
+		return nil
+	}
+	return groups
+}
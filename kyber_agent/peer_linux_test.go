@@ -0,0 +1,56 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on reading the supplementary groups of a peer on linux
+*/
+package kyber_agent
+
+import(
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func Test_Groups(t *testing.T){
+	status:="Name:\tcat\nUid:\t1000\t1000\t1000\t1000\nGid:\t1000\t1000\t1000\t1000\nGroups:\t27 4242 \n"
+	if got:=status_groups(status,1000);!slices.Equal(got,[]int{27,4242}){
+		t.Fatal("supplementary groups were read wrong")
+	}
+	if status_groups(status,33)!=nil{
+		t.Fatal("groups of a process with another uid were trusted")
+	}
+	if status_groups("Uid:\t1000\t1000\t1000\t1000\nGroups:\t27 x\n",1000)!=nil{
+		t.Fatal("malformed groups line was read")
+	}
+	if got:=status_groups("Uid:\t1000\t1000\t1000\t1000\nGroups:\t\n",1000);got==nil||len(got)!=0{
+		t.Fatal("a process without supplementary groups was not known to have none")
+	}
+	path:=filepath.Join(t.TempDir(),"peer.sock")
+	l,err:=net.Listen("unix",path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer l.Close()
+	c,err:=net.Dial("unix",path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer c.Close()
+	conn,err:=l.Accept()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	want,_:=os.Getgroups()
+	p:=peer_of(conn)
+	slices.Sort(want)
+	slices.Sort(p.Groups)
+	if p.Groups==nil||!slices.Equal(p.Groups,want){
+		t.Fatal("peer groups are not the groups of this process")
+	}
+}
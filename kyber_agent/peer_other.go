@@ -0,0 +1,19 @@
+//go:build !linux
+
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the peer credentials where they are not read yet, every peer is unknown so keys with an access list are refused
+*/
+package kyber_agent
+
+import(
+	"net"
+)
+
+func peer_of(conn net.Conn)Peer{
+	return Peer{-1,-1,-1,nil}
+}
@@ -0,0 +1,86 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the binary protocol spoken over the agent's Unix socket, one request is answered by one response on the same connection
+	frame:     len(4)||type(1)||body                    len counts type and body and is at most Max_Frame_Len
+	list:      type 1, empty body                       -> keys:    type 0x81, count(2)||count*(len(2)||name||len(2)||scheme name||len(2)||public key)
+	dec:       type 2, len(2)||name||shared len(2)||c   -> shared:  type 0x82, K
+	failure:   type 0xff, code(1)                       code 1 no such key, 2 not confirmed, 3 malformed request, 4 decapsulation failed
+lengths are big endian, a key the peer may not use is reported as no such key and left out of the list
+the list has to fit one frame, that is about 40 kyber_1024 keys per agent
+*/
+package kyber_agent
+
+import(
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const(
+	Max_Frame_Len=64*1024
+	Max_Shared_Len=1024
+
+	msg_list=1
+	msg_dec=2
+	msg_keys=0x81
+	msg_shared=0x82
+	msg_failure=0xff
+
+	code_no_key=1
+	code_denied=2
+	code_format=3
+	code_failed=4
+)
+
+var(
+	Err_No_Key=errors.New("kyber_agent: no such key")
+	Err_Denied=errors.New("kyber_agent: use of the key was not confirmed")
+	Err_Format=errors.New("kyber_agent: malformed message")
+	Err_Failed=errors.New("kyber_agent: decapsulation failed")
+)
+
+var code_errors=map[byte]error{code_no_key:Err_No_Key,code_denied:Err_Denied,code_format:Err_Format,code_failed:Err_Failed}
+
+func write_frame(w io.Writer,typ byte,body []byte)error{
+	if len(body)+1>Max_Frame_Len{
+		return Err_Format
+	}
+	frame:=binary.BigEndian.AppendUint32(nil,uint32(len(body)+1))
+	_,err:=w.Write(append(append(frame,typ),body...))
+	return err
+}
+
+func read_frame(r io.Reader)(typ byte,body []byte,err error){
+	var l [4]byte
+	if _,err=io.ReadFull(r,l[:]);err!=nil{
+		return
+	}
+	n:=binary.BigEndian.Uint32(l[:])
+	if n==0||n>Max_Frame_Len{
+		return 0,nil,Err_Format
+	}
+	frame:=make([]byte,n)
+	if _,err=io.ReadFull(r,frame);err!=nil{
+		if err==io.EOF{
+			err=io.ErrUnexpectedEOF
+		}
+		return
+	}
+	return frame[0],frame[1:],nil
+}
+
+func put_string(b []byte,s []byte)[]byte{
+	return append(binary.BigEndian.AppendUint16(b,uint16(len(s))),s...)
+}
+
+func get_string(b []byte)(s,rest []byte,ok bool){
+	if len(b)<2||len(b)-2<int(binary.BigEndian.Uint16(b)){
+		return nil,nil,false
+	}
+	n:=2+int(binary.BigEndian.Uint16(b))
+	return b[2:n],b[n:],true
+}
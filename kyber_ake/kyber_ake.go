@@ -0,0 +1,239 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the Kyber.UAKE and Kyber.AKE authenticated key exchanges from the kyber paper with transcript hashing and explicit key confirmation
+UAKE: the initiator knows the responder's static key, AKE: both sides know each other's static key
+
+	message 1 (I->R): mode||pk_e||c_R             c_R encapsulates to the responder's static key
+	message 2 (R->I): c_e||[c_I]||tag_R           c_e encapsulates to pk_e, c_I to the initiator's static key in AKE mode
+	message 3 (I->R): tag_I
+*/
+package kyber_ake
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/sha3"
+	"crypto/subtle"
+	"errors"
+)
+
+const(
+	UAKE byte=1
+	AKE byte=2
+	Tag_len=32
+	Session_key_len=32
+)
+
+const(
+	state_start=iota
+	state_sent
+	state_done
+)
+
+var(
+	Err_State=errors.New("kyber_ake: message handled out of order")
+	Err_Confirm=errors.New("kyber_ake: key confirmation failed")
+)
+
+type Initiator struct{
+	scheme kyber_kem.Scheme
+	mode byte
+	static kyber_kem.Private_Key
+	peer kyber_kem.Public_Key
+	ephemeral kyber_kem.Private_Key
+	K_R,msg1 []byte
+	confirm_I []byte
+	state int
+	Context []byte//bound into the transcript, both sides have to set the same bytes before the messages are made
+	Session_Key []byte
+}
+
+type Responder struct{
+	scheme kyber_kem.Scheme
+	mode byte
+	static kyber_kem.Private_Key
+	peer kyber_kem.Public_Key
+	confirm_I []byte
+	key []byte
+	state int
+	Context []byte
+	Session_Key []byte
+}
+
+//static is nil for UAKE, peer is the responder's static public key
+func New_Initiator(static kyber_kem.Private_Key,peer kyber_kem.Public_Key)(*Initiator,error){
+	if peer==nil{
+		return nil,errors.New("kyber_ake: initiator needs the responder's static key")
+	}
+	I:=&Initiator{scheme:peer.Scheme(),mode:UAKE,static:static,peer:peer}
+	if static!=nil{
+		if static.Scheme()!=I.scheme{
+			return nil,errors.New("kyber_ake: static keys use different schemes")
+		}
+		I.mode=AKE
+	}
+	return I,nil
+}
+
+//peer is nil for UAKE, static is the responder's static private key
+func New_Responder(static kyber_kem.Private_Key,peer kyber_kem.Public_Key)(*Responder,error){
+	if static==nil{
+		return nil,errors.New("kyber_ake: responder needs a static key")
+	}
+	R:=&Responder{scheme:static.Scheme(),mode:UAKE,static:static,peer:peer}
+	if peer!=nil{
+		if peer.Scheme()!=R.scheme{
+			return nil,errors.New("kyber_ake: static keys use different schemes")
+		}
+		R.mode=AKE
+	}
+	return R,nil
+}
+
+func (I *Initiator)Mode()byte{
+	return I.mode
+}
+
+func (R *Responder)Mode()byte{
+	return R.mode
+}
+
+func (I *Initiator)Message_1()(msg []byte,err error){
+	if I.state!=state_start{
+		return nil,Err_State
+	}
+	I.ephemeral,err=I.scheme.Keygen()
+	if err!=nil{
+		return
+	}
+	c_R,K_R,err:=I.peer.Enc()
+	if err!=nil{
+		return
+	}
+	msg=append([]byte{I.mode},I.ephemeral.Public().To_Bytes()...)
+	msg=append(msg,c_R...)
+	I.K_R=K_R
+	I.msg1=msg
+	I.state=state_sent
+	return
+}
+
+func (R *Responder)Message_2(msg1 []byte)(msg []byte,err error){
+	s:=R.scheme
+	if R.state!=state_start{
+		return nil,Err_State
+	}
+	if len(msg1)!=1+s.Pk_Len()+s.Ct_Len(){
+		return nil,errors.New("kyber_ake: message 1 has the wrong length")
+	}
+	if msg1[0]!=R.mode{
+		return nil,errors.New("kyber_ake: initiator requested a different mode")
+	}
+	pk_e,err:=s.Bytes_to_Pk(msg1[1:1+s.Pk_Len()])
+	if err!=nil{
+		return
+	}
+	K_R,err:=R.static.Dec(msg1[1+s.Pk_Len():])
+	if err!=nil{
+		return
+	}
+	c_e,K_e,err:=pk_e.Enc()
+	if err!=nil{
+		return
+	}
+	secret:=append(K_e,K_R...)
+	msg=c_e
+	if R.mode==AKE{
+		c_I,K_I,err:=R.peer.Enc()
+		if err!=nil{
+			return nil,err
+		}
+		secret=append(secret,K_I...)
+		msg=append(msg,c_I...)
+	}
+	keys,err:=derive(R.mode,s,secret,R.static.Public(),R.peer,msg1,msg,R.Context)
+	if err!=nil{
+		return
+	}
+	msg=append(msg,keys[Session_key_len:Session_key_len+Tag_len]...)
+	R.key=keys[:Session_key_len]
+	R.confirm_I=keys[Session_key_len+Tag_len:]
+	R.state=state_sent
+	return
+}
+
+//msg3 carries the initiator's key confirmation and must be sent to the responder
+func (I *Initiator)Finish(msg2 []byte)(msg3 []byte,err error){
+	s:=I.scheme
+	if I.state!=state_sent{
+		return nil,Err_State
+	}
+	body_len:=s.Ct_Len()
+	if I.mode==AKE{
+		body_len+=s.Ct_Len()
+	}
+	if len(msg2)!=body_len+Tag_len{
+		return nil,errors.New("kyber_ake: message 2 has the wrong length")
+	}
+	K_e,err:=I.ephemeral.Dec(msg2[:s.Ct_Len()])
+	if err!=nil{
+		return
+	}
+	secret:=append(K_e,I.K_R...)
+	var static_pk kyber_kem.Public_Key
+	if I.mode==AKE{
+		K_I,err:=I.static.Dec(msg2[s.Ct_Len():body_len])
+		if err!=nil{
+			return nil,err
+		}
+		secret=append(secret,K_I...)
+		static_pk=I.static.Public()
+	}
+	keys,err:=derive(I.mode,s,secret,I.peer,static_pk,I.msg1,msg2[:body_len],I.Context)
+	if err!=nil{
+		return
+	}
+	if subtle.ConstantTimeCompare(keys[Session_key_len:Session_key_len+Tag_len],msg2[body_len:])!=1{
+		return nil,Err_Confirm
+	}
+	I.Session_Key=keys[:Session_key_len]
+	I.ephemeral=nil
+	I.K_R=nil
+	I.state=state_done
+	return keys[Session_key_len+Tag_len:],nil
+}
+
+func (R *Responder)Finish(msg3 []byte)error{
+	if R.state!=state_sent{
+		return Err_State
+	}
+	if subtle.ConstantTimeCompare(R.confirm_I,msg3)!=1{
+		return Err_Confirm
+	}
+	R.Session_Key=R.key
+	R.key=nil
+	R.state=state_done
+	return nil
+}
+
+//the transcript covers the mode, scheme, static keys, both messages and the context when there is one, the kdf output is split into the session key and the two confirmation tags
+func derive(mode byte,s kyber_kem.Scheme,secret []byte,responder,initiator kyber_kem.Public_Key,msg1,msg2,context []byte)([]byte,error){
+	H:=sha3.New256()
+	H.Write([]byte("kyber_ake"))
+	H.Write([]byte{mode})
+	H.Write([]byte(s.Name()))
+	H.Write(responder.To_Bytes())
+	if initiator!=nil{
+		H.Write(initiator.To_Bytes())
+	}
+	H.Write(msg1)
+	H.Write(msg2)
+	if len(context)>0{
+		H.Write(context)
+	}
+	return kyber_kdf.KMAC256.Derive(secret,H.Sum(nil),"kyber_ake keys",Session_key_len+2*Tag_len)
+}
@@ -0,0 +1,154 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on Kyber.UAKE and Kyber.AKE
+*/
+package kyber_ake
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"testing"
+)
+
+func run(I *Initiator,R *Responder)error{
+	msg1,err:=I.Message_1()
+	if err!=nil{
+		return err
+	}
+	msg2,err:=R.Message_2(msg1)
+	if err!=nil{
+		return err
+	}
+	msg3,err:=I.Finish(msg2)
+	if err!=nil{
+		return err
+	}
+	return R.Finish(msg3)
+}
+
+func Test_UAKE(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		sk_R,_:=s.Keygen()
+		I,err:=New_Initiator(nil,sk_R.Public())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		R,err:=New_Responder(sk_R,nil)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if err=run(I,R);err!=nil{
+			t.Fatal(err)
+		}
+		if I.Mode()!=UAKE||!bytes.Equal(I.Session_Key,R.Session_Key){
+			t.Fatal(s.Name()+" UAKE session keys do not match")
+		}
+	}
+}
+
+func Test_AKE(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		sk_I,_:=s.Keygen()
+		sk_R,_:=s.Keygen()
+		I,err:=New_Initiator(sk_I,sk_R.Public())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		R,err:=New_Responder(sk_R,sk_I.Public())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if err=run(I,R);err!=nil{
+			t.Fatal(err)
+		}
+		if I.Mode()!=AKE||!bytes.Equal(I.Session_Key,R.Session_Key){
+			t.Fatal(s.Name()+" AKE session keys do not match")
+		}
+	}
+}
+
+func Test_Wrong_Keys(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	sk_I,_:=s.Keygen()
+	sk_R,_:=s.Keygen()
+	impostor,_:=s.Keygen()
+	I,_:=New_Initiator(impostor,sk_R.Public())//responder expects sk_I
+	R,_:=New_Responder(sk_R,sk_I.Public())
+	if err:=run(I,R);err!=Err_Confirm{
+		t.Fatal("AKE with the wrong initiator key did not fail key confirmation")
+	}
+	I,_=New_Initiator(nil,impostor.Public())
+	R,_=New_Responder(sk_R,nil)
+	if err:=run(I,R);err!=Err_Confirm{
+		t.Fatal("UAKE against the wrong responder key did not fail key confirmation")
+	}
+	I,_=New_Initiator(nil,sk_R.Public())
+	R,_=New_Responder(sk_R,sk_I.Public())
+	if err:=run(I,R);err==nil{
+		t.Fatal("responder accepted a UAKE message in AKE mode")
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	s:=kyber_kem.Kyber_512
+	sk_R,_:=s.Keygen()
+	I,_:=New_Initiator(nil,sk_R.Public())
+	R,_:=New_Responder(sk_R,nil)
+	msg1,_:=I.Message_1()
+	msg2,err:=R.Message_2(msg1)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	msg2[0]^=1
+	if _,err=I.Finish(msg2);err!=Err_Confirm{
+		t.Fatal("initiator accepted a tampered message 2")
+	}
+	msg2[0]^=1
+	if _,err=I.Finish(msg2);err!=nil{
+		t.Fatal(err)
+	}
+	if err=R.Finish(make([]byte,Tag_len));err!=Err_Confirm{
+		t.Fatal("responder accepted a forged confirmation tag")
+	}
+}
+
+func Test_Context(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	sk_I,_:=s.Keygen()
+	sk_R,_:=s.Keygen()
+	I,_:=New_Initiator(sk_I,sk_R.Public())
+	R,_:=New_Responder(sk_R,sk_I.Public())
+	I.Context,R.Context=[]byte("context"),[]byte("context")
+	if err:=run(I,R);err!=nil||!bytes.Equal(I.Session_Key,R.Session_Key){
+		t.Fatal("AKE with the same context failed")
+	}
+	I,_=New_Initiator(sk_I,sk_R.Public())
+	R,_=New_Responder(sk_R,sk_I.Public())
+	I.Context,R.Context=[]byte("context"),[]byte("changed")
+	if err:=run(I,R);err!=Err_Confirm{
+		t.Fatal("AKE with a different context did not fail key confirmation")
+	}
+}
+
+func Test_State(t *testing.T){
+	s:=kyber_kem.Kyber_512
+	sk_R,_:=s.Keygen()
+	I,_:=New_Initiator(nil,sk_R.Public())
+	R,_:=New_Responder(sk_R,nil)
+	if _,err:=I.Finish(nil);err!=Err_State{
+		t.Fatal("initiator finished before sending message 1")
+	}
+	if err:=R.Finish(nil);err!=Err_State{
+		t.Fatal("responder finished before sending message 2")
+	}
+	if err:=run(I,R);err!=nil{
+		t.Fatal(err)
+	}
+	if _,err:=I.Message_1();err!=Err_State{
+		t.Fatal("initiator restarted a finished exchange")
+	}
+}
@@ -0,0 +1,170 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains an authenticated KEM that binds a sender static kyber key and a recipient static kyber key with two encapsulations and a MAC
+kyber has no non-interactive key agreement like DH, so a sender can not be authenticated from (sender sk,recipient pk) alone,
+the second encapsulation comes from the recipient once per sender as a grant and is then reused for every message:
+
+	grant (recipient->sender, once): c_RS||ticket     c_RS encapsulates K2 to the sender, ticket=XChaCha20-Poly1305(key from sk_R,K2,H(pk_S)||H(pk_R))
+	Auth_Encap: c_SR,K1=Enc(pk_R) K2=Dec(sk_S,c_RS) mac key,K=KMAC256(K1||K2,pk_S||pk_R||c_SR||ticket,"kyber_authkem",64)
+	ciphertext=c_SR||ticket||KMAC256(mac key,c_SR||ticket,"kyber_authkem tag",32)
+only the holder of sk_S can recover K2 from the grant, so a valid tag shows the sender, and the ticket lets the recipient recover K2 without keeping state
+*/
+package kyber_authkem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+)
+
+const(
+	Tag_len=32
+	Shared_key_len=32
+	ticket_len=chacha20poly1305.NonceSizeX+kyber_kem.Shared_key_len+chacha20poly1305.Overhead
+)
+
+var(
+	Err_Auth=errors.New("kyber_authkem: sender authentication failed")
+	Err_Scheme=errors.New("kyber_authkem: sender and recipient keys use different schemes")
+	Err_Format=errors.New("kyber_authkem: malformed grant or ciphertext")
+)
+
+func Grant_Len(s kyber_kem.Scheme)int{
+	return s.Ct_Len()+ticket_len
+}
+
+func Ct_Len(s kyber_kem.Scheme)int{
+	return s.Ct_Len()+ticket_len+Tag_len
+}
+
+func ticket_key(recipient kyber_kem.Private_Key)([]byte,error){
+	return kyber_kdf.KMAC256.Derive(recipient.To_Bytes(),nil,"kyber_authkem ticket",chacha20poly1305.KeySize)
+}
+
+func ticket_ad(sender,recipient kyber_kem.Public_Key)[]byte{
+	hs:=sha3.Sum256(sender.To_Bytes())
+	hr:=sha3.Sum256(recipient.To_Bytes())
+	return append(hs[:],hr[:]...)
+}
+
+func check_schemes(a,b kyber_kem.Scheme)error{
+	if a!=b{
+		return Err_Scheme
+	}
+	return nil
+}
+
+//run by the recipient once for each sender it wants to accept authenticated messages from
+func Grant(recipient kyber_kem.Private_Key,sender kyber_kem.Public_Key)([]byte,error){
+	if err:=check_schemes(recipient.Scheme(),sender.Scheme());err!=nil{
+		return nil,err
+	}
+	c,K2,err:=sender.Enc()
+	if err!=nil{
+		return nil,err
+	}
+	key,err:=ticket_key(recipient)
+	if err!=nil{
+		return nil,err
+	}
+	aead,err:=chacha20poly1305.NewX(key)
+	if err!=nil{
+		return nil,err
+	}
+	nonce:=make([]byte,aead.NonceSize())
+	if _,err=rand.Read(nonce);err!=nil{
+		return nil,err
+	}
+	ticket:=aead.Seal(nonce,nonce,K2,ticket_ad(sender,recipient.Public()))
+	return append(c,ticket...),nil
+}
+
+func derive(K1,K2 []byte,sender,recipient kyber_kem.Public_Key,c,ticket []byte)(mac_key,K []byte,err error){
+	context:=append(append(append(sender.To_Bytes(),recipient.To_Bytes()...),c...),ticket...)
+	keys,err:=kyber_kdf.KMAC256.Derive(append(append([]byte(nil),K1...),K2...),context,"kyber_authkem",Tag_len+Shared_key_len)
+	if err!=nil{
+		return
+	}
+	return keys[:Tag_len],keys[Tag_len:],nil
+}
+
+func tag(mac_key,c,ticket []byte)([]byte,error){
+	return kyber_kdf.KMAC256.Derive(mac_key,append(append([]byte(nil),c...),ticket...),"kyber_authkem tag",Tag_len)
+}
+
+func Auth_Encap(sender kyber_kem.Private_Key,recipient kyber_kem.Public_Key,grant []byte)(c,K []byte,err error){
+	s:=sender.Scheme()
+	if err=check_schemes(s,recipient.Scheme());err!=nil{
+		return
+	}
+	if len(grant)!=Grant_Len(s){
+		return nil,nil,Err_Format
+	}
+	K2,err:=sender.Dec(grant[:s.Ct_Len()])
+	if err!=nil{
+		return
+	}
+	ticket:=grant[s.Ct_Len():]
+	c_SR,K1,err:=recipient.Enc()
+	if err!=nil{
+		return
+	}
+	mac_key,K,err:=derive(K1,K2,sender.Public(),recipient,c_SR,ticket)
+	if err!=nil{
+		return
+	}
+	t,err:=tag(mac_key,c_SR,ticket)
+	if err!=nil{
+		return
+	}
+	c=append(append(c_SR,ticket...),t...)
+	return
+}
+
+func Auth_Decap(recipient kyber_kem.Private_Key,sender kyber_kem.Public_Key,c []byte)(K []byte,err error){
+	s:=recipient.Scheme()
+	if err=check_schemes(s,sender.Scheme());err!=nil{
+		return
+	}
+	if len(c)!=Ct_Len(s){
+		return nil,Err_Format
+	}
+	c_SR:=c[:s.Ct_Len()]
+	ticket:=c[s.Ct_Len():s.Ct_Len()+ticket_len]
+	key,err:=ticket_key(recipient)
+	if err!=nil{
+		return
+	}
+	aead,err:=chacha20poly1305.NewX(key)
+	if err!=nil{
+		return
+	}
+	K2,err:=aead.Open(nil,ticket[:aead.NonceSize()],ticket[aead.NonceSize():],ticket_ad(sender,recipient.Public()))
+	if err!=nil{
+		return nil,Err_Auth//the grant was issued by another recipient or for another sender
+	}
+	K1,err:=recipient.Dec(c_SR)
+	if err!=nil{
+		return
+	}
+	mac_key,K,err:=derive(K1,K2,sender,recipient.Public(),c_SR,ticket)
+	if err!=nil{
+		return
+	}
+	t,err:=tag(mac_key,c_SR,ticket)
+	if err!=nil{
+		return
+	}
+	if subtle.ConstantTimeCompare(t,c[s.Ct_Len()+ticket_len:])!=1{
+		return nil,Err_Auth
+	}
+	return K,nil
+}
@@ -0,0 +1,117 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the authenticated KEM
+*/
+package kyber_authkem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"testing"
+)
+
+func keys(t *testing.T,s kyber_kem.Scheme,n int)(out []kyber_kem.Private_Key){
+	for i:=0;i<n;i++{
+		sk,err:=s.Keygen()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		out=append(out,sk)
+	}
+	return
+}
+
+func Test_Round_Trip(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		k:=keys(t,s,2)
+		sender,recipient:=k[0],k[1]
+		grant,err:=Grant(recipient,sender.Public())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if len(grant)!=Grant_Len(s){
+			t.Fatal(s.Name()+" grant has the wrong length")
+		}
+		for i:=0;i<3;i++{//one grant covers many messages
+			c,K,err:=Auth_Encap(sender,recipient.Public(),grant)
+			if err!=nil{
+				t.Fatal(err)
+			}
+			if len(c)!=Ct_Len(s)||len(K)!=Shared_key_len{
+				t.Fatal(s.Name()+" output has the wrong length")
+			}
+			K2,err:=Auth_Decap(recipient,sender.Public(),c)
+			if err!=nil{
+				t.Fatal(s.Name()+": "+err.Error())
+			}
+			if !bytes.Equal(K,K2){
+				t.Fatal(s.Name()+" keys do not match")
+			}
+		}
+	}
+}
+
+func Test_Forged_Sender(t *testing.T){
+	k:=keys(t,kyber_kem.Kyber_768,3)
+	sender,recipient,attacker:=k[0],k[1],k[2]
+	grant,_:=Grant(recipient,sender.Public())
+	//the attacker has the sender's grant but can not open c_RS
+	c,_,err:=Auth_Encap(attacker,recipient.Public(),grant)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=Auth_Decap(recipient,sender.Public(),c);err!=Err_Auth{
+		t.Fatal("forged sender with a stolen grant was accepted")
+	}
+	//the attacker's own grant is bound to the attacker's public key
+	attacker_grant,_:=Grant(recipient,attacker.Public())
+	c,_,_=Auth_Encap(attacker,recipient.Public(),attacker_grant)
+	if _,err=Auth_Decap(recipient,sender.Public(),c);err!=Err_Auth{
+		t.Fatal("attacker grant was accepted for another sender")
+	}
+	if _,err=Auth_Decap(recipient,attacker.Public(),c);err!=nil{
+		t.Fatal("attacker could not use its own grant")
+	}
+	//a grant from another recipient does not open for this one
+	other:=keys(t,kyber_kem.Kyber_768,1)[0]
+	other_grant,_:=Grant(other,sender.Public())
+	c,_,_=Auth_Encap(sender,recipient.Public(),other_grant)
+	if _,err=Auth_Decap(recipient,sender.Public(),c);err!=Err_Auth{
+		t.Fatal("grant from another recipient was accepted")
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	s:=kyber_kem.Kyber_512
+	k:=keys(t,s,2)
+	sender,recipient:=k[0],k[1]
+	grant,_:=Grant(recipient,sender.Public())
+	c,_,_:=Auth_Encap(sender,recipient.Public(),grant)
+	for _,i:=range []int{0,s.Ct_Len()-1,s.Ct_Len(),s.Ct_Len()+ticket_len-1,Ct_Len(s)-1}{
+		tampered:=append([]byte(nil),c...)
+		tampered[i]^=1
+		if _,err:=Auth_Decap(recipient,sender.Public(),tampered);err!=Err_Auth{
+			t.Fatal("tampered ciphertext was accepted")
+		}
+	}
+	if _,err:=Auth_Decap(recipient,sender.Public(),c[1:]);err!=Err_Format{
+		t.Fatal("short ciphertext was accepted")
+	}
+	if _,_,err:=Auth_Encap(sender,recipient.Public(),grant[1:]);err!=Err_Format{
+		t.Fatal("short grant was accepted")
+	}
+	mismatched:=keys(t,kyber_kem.Kyber_1024,1)[0]
+	if _,err:=Grant(recipient,mismatched.Public());err!=Err_Scheme{
+		t.Fatal("grant across schemes was issued")
+	}
+	if _,_,err:=Auth_Encap(mismatched,recipient.Public(),grant);err!=Err_Scheme{
+		t.Fatal("encapsulation across schemes was allowed")
+	}
+	if _,err:=Auth_Decap(recipient,mismatched.Public(),c);err!=Err_Scheme{
+		t.Fatal("decapsulation across schemes was allowed")
+	}
+}
@@ -0,0 +1,387 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a post quantum secure channel that wraps a net.Conn
+The handshake is an ephemeral kyber exchange, Kyber.UAKE when the client knows the server's static key or Kyber.AKE when both sides know each other's
+Records are length(4)||ChaCha20-Poly1305(type||data) with the sequence number as the nonce, the sender rekeys after Rekey_After records
+*/
+package kyber_conn
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_ake"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const(
+	mode_ephemeral byte=0
+	record_data byte=0
+	record_rekey byte=1
+	record_close byte=2
+	Max_record_len=1<<16
+	Default_rekey_after=1<<20
+	max_handshake_len=1<<16
+)
+
+var(
+	Err_Mode=errors.New("kyber_conn: peers are configured for different handshake modes")
+	Err_Record=errors.New("kyber_conn: record failed authentication")
+	Err_Closed=errors.New("kyber_conn: connection is closed")
+)
+
+type Config struct{
+	Scheme kyber_kem.Scheme//defaults to kyber_768, ignored when static keys are given
+	Static kyber_kem.Private_Key
+	Peer_Static kyber_kem.Public_Key
+	Rekey_After uint64
+}
+
+type half_conn struct{
+	sync.Mutex
+	aead cipher.AEAD
+	key []byte
+	seq,records uint64
+}
+
+func (hc *half_conn)set_key(key []byte){
+	hc.key=key
+	hc.aead,_=chacha20poly1305.New(key)
+	hc.seq=0
+}
+
+func (hc *half_conn)rekey(){
+	next,_:=kyber_kdf.KMAC256.Derive(hc.key,nil,"kyber_conn rekey",chacha20poly1305.KeySize)
+	hc.set_key(next)
+}
+
+func (hc *half_conn)nonce()[]byte{
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.BigEndian.PutUint64(nonce[4:],hc.seq)
+	hc.seq++
+	return nonce[:]
+}
+
+type Secure_Conn struct{
+	conn net.Conn
+	in,out half_conn
+	rekey_after uint64
+	buf []byte
+	read_err error
+	close_once sync.Once
+}
+
+func Client(conn net.Conn,config *Config)(*Secure_Conn,error){
+	c:=new_conn(conn,config)
+	if err:=c.client_handshake(config);err!=nil{
+		conn.Close()
+		return nil,err
+	}
+	return c,nil
+}
+
+func Server(conn net.Conn,config *Config)(*Secure_Conn,error){
+	c:=new_conn(conn,config)
+	if err:=c.server_handshake(config);err!=nil{
+		conn.Close()
+		return nil,err
+	}
+	return c,nil
+}
+
+func new_conn(conn net.Conn,config *Config)*Secure_Conn{
+	c:=&Secure_Conn{conn:conn,rekey_after:config.Rekey_After}
+	if c.rekey_after==0{
+		c.rekey_after=Default_rekey_after
+	}
+	return c
+}
+
+func scheme(config *Config)kyber_kem.Scheme{
+	switch{
+	case config.Static!=nil:
+		return config.Static.Scheme()
+	case config.Peer_Static!=nil:
+		return config.Peer_Static.Scheme()
+	case config.Scheme!=nil:
+		return config.Scheme
+	}
+	return kyber_kem.Kyber_768
+}
+
+func (c *Secure_Conn)write_message(msg []byte)error{
+	_,err:=c.conn.Write(binary.BigEndian.AppendUint32(nil,uint32(len(msg))))
+	if err==nil{
+		_,err=c.conn.Write(msg)
+	}
+	return err
+}
+
+func (c *Secure_Conn)read_message()([]byte,error){
+	var length [4]byte
+	if _,err:=io.ReadFull(c.conn,length[:]);err!=nil{
+		return nil,err
+	}
+	n:=binary.BigEndian.Uint32(length[:])
+	if n>max_handshake_len{
+		return nil,errors.New("kyber_conn: handshake message is too long")
+	}
+	msg:=make([]byte,n)
+	_,err:=io.ReadFull(c.conn,msg)
+	return msg,err
+}
+
+//client to server and server to client keys are split from the session key
+func (c *Secure_Conn)set_keys(session []byte,client bool){
+	keys,_:=kyber_kdf.KMAC256.Derive(session,nil,"kyber_conn traffic keys",2*chacha20poly1305.KeySize)
+	c2s,s2c:=keys[:chacha20poly1305.KeySize],keys[chacha20poly1305.KeySize:]
+	if client{
+		c.out.set_key(c2s)
+		c.in.set_key(s2c)
+	}else{
+		c.out.set_key(s2c)
+		c.in.set_key(c2s)
+	}
+}
+
+func (c *Secure_Conn)client_handshake(config *Config)error{
+	if config.Peer_Static==nil{
+		if config.Static!=nil{
+			return errors.New("kyber_conn: client authentication needs the server's static key")
+		}
+		return c.client_ephemeral(scheme(config))
+	}
+	I,err:=kyber_ake.New_Initiator(config.Static,config.Peer_Static)
+	if err!=nil{
+		return err
+	}
+	msg,err:=I.Message_1()
+	if err!=nil{
+		return err
+	}
+	if err=c.write_message(msg);err!=nil{
+		return err
+	}
+	if msg,err=c.read_message();err!=nil{
+		return err
+	}
+	if msg,err=I.Finish(msg);err!=nil{
+		return err
+	}
+	if err=c.write_message(msg);err!=nil{
+		return err
+	}
+	c.set_keys(I.Session_Key,true)
+	return nil
+}
+
+func (c *Secure_Conn)server_handshake(config *Config)error{
+	msg,err:=c.read_message()
+	if err!=nil{
+		return err
+	}
+	if len(msg)<1{
+		return Err_Mode
+	}
+	if config.Static==nil{
+		if config.Peer_Static!=nil{
+			return errors.New("kyber_conn: client authentication needs a server static key")
+		}
+		if msg[0]!=mode_ephemeral{
+			return Err_Mode
+		}
+		return c.server_ephemeral(scheme(config),msg)
+	}
+	R,err:=kyber_ake.New_Responder(config.Static,config.Peer_Static)
+	if err!=nil{
+		return err
+	}
+	if msg[0]!=R.Mode(){
+		return Err_Mode
+	}
+	if msg,err=R.Message_2(msg);err!=nil{
+		return err
+	}
+	if err=c.write_message(msg);err!=nil{
+		return err
+	}
+	if msg,err=c.read_message();err!=nil{
+		return err
+	}
+	if err=R.Finish(msg);err!=nil{
+		return err
+	}
+	c.set_keys(R.Session_Key,false)
+	return nil
+}
+
+//without static keys the channel is only protected against passive attackers
+func (c *Secure_Conn)client_ephemeral(s kyber_kem.Scheme)error{
+	e,err:=s.Keygen()
+	if err!=nil{
+		return err
+	}
+	hello:=append([]byte{mode_ephemeral},e.Public().To_Bytes()...)
+	if err=c.write_message(hello);err!=nil{
+		return err
+	}
+	ct,err:=c.read_message()
+	if err!=nil{
+		return err
+	}
+	K,err:=e.Dec(ct)
+	if err!=nil{
+		return err
+	}
+	c.set_keys(ephemeral_session(K,hello,ct),true)
+	return nil
+}
+
+func (c *Secure_Conn)server_ephemeral(s kyber_kem.Scheme,hello []byte)error{
+	pk,err:=s.Bytes_to_Pk(hello[1:])
+	if err!=nil{
+		return err
+	}
+	ct,K,err:=pk.Enc()
+	if err!=nil{
+		return err
+	}
+	if err=c.write_message(ct);err!=nil{
+		return err
+	}
+	c.set_keys(ephemeral_session(K,hello,ct),false)
+	return nil
+}
+
+func ephemeral_session(K,hello,ct []byte)[]byte{
+	H:=sha3.New256()
+	H.Write(hello)
+	H.Write(ct)
+	session,_:=kyber_kdf.KMAC256.Derive(K,H.Sum(nil),"kyber_conn ephemeral",32)
+	return session
+}
+
+func (c *Secure_Conn)write_record(typ byte,data []byte)error{
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:],uint32(1+len(data)+chacha20poly1305.Overhead))
+	record:=c.out.aead.Seal(length[:],c.out.nonce(),append([]byte{typ},data...),length[:])
+	_,err:=c.conn.Write(record)
+	return err
+}
+
+func (c *Secure_Conn)read_record()(typ byte,data []byte,err error){
+	var length [4]byte
+	if _,err=io.ReadFull(c.conn,length[:]);err!=nil{
+		return
+	}
+	n:=binary.BigEndian.Uint32(length[:])
+	if n<1+chacha20poly1305.Overhead||n>Max_record_len+1+chacha20poly1305.Overhead{
+		return 0,nil,Err_Record
+	}
+	data=make([]byte,n)
+	if _,err=io.ReadFull(c.conn,data);err!=nil{
+		return
+	}
+	if data,err=c.in.aead.Open(nil,c.in.nonce(),data,length[:]);err!=nil{
+		return 0,nil,Err_Record
+	}
+	return data[0],data[1:],nil
+}
+
+func (c *Secure_Conn)Read(b []byte)(int,error){
+	c.in.Lock()
+	defer c.in.Unlock()
+	for len(c.buf)==0{
+		if c.read_err!=nil{
+			return 0,c.read_err
+		}
+		typ,data,err:=c.read_record()
+		if err!=nil{
+			c.read_err=err
+			return 0,err
+		}
+		switch typ{
+		case record_data:
+			c.buf=data
+		case record_rekey:
+			c.in.rekey()
+		case record_close:
+			c.read_err=io.EOF
+		default:
+			c.read_err=Err_Record
+		}
+	}
+	n:=copy(b,c.buf)
+	c.buf=c.buf[n:]
+	return n,nil
+}
+
+func (c *Secure_Conn)Write(b []byte)(n int,err error){
+	c.out.Lock()
+	defer c.out.Unlock()
+	if c.out.aead==nil{
+		return 0,Err_Closed
+	}
+	for len(b)>0{
+		if c.out.records>=c.rekey_after{
+			if err=c.write_record(record_rekey,nil);err!=nil{
+				return
+			}
+			c.out.rekey()
+			c.out.records=0
+		}
+		chunk:=min(len(b),Max_record_len)
+		if err=c.write_record(record_data,b[:chunk]);err!=nil{
+			return
+		}
+		c.out.records++
+		n+=chunk
+		b=b[chunk:]
+	}
+	return
+}
+
+//sends close-notify before closing the underlying connection, the peer gets a second to read it
+func (c *Secure_Conn)Close()(err error){
+	err=Err_Closed
+	c.close_once.Do(func(){
+		c.out.Lock()
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.write_record(record_close,nil)
+		c.out.aead=nil
+		c.out.Unlock()
+		err=c.conn.Close()
+	})
+	return
+}
+
+func (c *Secure_Conn)LocalAddr()net.Addr{
+	return c.conn.LocalAddr()
+}
+
+func (c *Secure_Conn)RemoteAddr()net.Addr{
+	return c.conn.RemoteAddr()
+}
+
+func (c *Secure_Conn)SetDeadline(t time.Time)error{
+	return c.conn.SetDeadline(t)
+}
+
+func (c *Secure_Conn)SetReadDeadline(t time.Time)error{
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Secure_Conn)SetWriteDeadline(t time.Time)error{
+	return c.conn.SetWriteDeadline(t)
+}
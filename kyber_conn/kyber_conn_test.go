@@ -0,0 +1,146 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the secure channel over net.Pipe
+*/
+package kyber_conn
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_ake"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/rand"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+var _ net.Conn=(*Secure_Conn)(nil)
+
+//flips a bit in the next write once armed
+type flip_conn struct{
+	net.Conn
+	armed bool
+}
+
+func (f *flip_conn)Write(b []byte)(int,error){
+	if f.armed{
+		f.armed=false
+		b=append([]byte(nil),b...)
+		b[len(b)-1]^=1
+	}
+	return f.Conn.Write(b)
+}
+
+type result struct{
+	conn *Secure_Conn
+	err error
+}
+
+func connect(client_config,server_config *Config)(client,server *Secure_Conn,client_conn *flip_conn,client_err,server_err error){
+	c,s:=net.Pipe()
+	client_conn=&flip_conn{Conn:c}
+	done:=make(chan result)
+	go func(){
+		conn,err:=Server(s,server_config)
+		done<-result{conn,err}
+	}()
+	client,client_err=Client(client_conn,client_config)
+	r:=<-done
+	return client,r.conn,client_conn,client_err,r.err
+}
+
+func echo(t *testing.T,client,server *Secure_Conn,size int){
+	data:=make([]byte,size)
+	rand.Read(data)
+	go func(){
+		buf:=make([]byte,size)
+		io.ReadFull(server,buf)
+		server.Write(buf)
+	}()
+	if _,err:=client.Write(data);err!=nil{
+		t.Fatal(err)
+	}
+	got:=make([]byte,size)
+	if _,err:=io.ReadFull(client,got);err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got,data){
+		t.Fatal("echoed data does not match")
+	}
+}
+
+func Test_Modes(t *testing.T){
+	server_sk,_:=kyber_kem.Kyber_768.Keygen()
+	client_sk,_:=kyber_kem.Kyber_768.Keygen()
+	configs:=[][2]*Config{
+		{{Scheme:kyber_kem.Kyber_512},{Scheme:kyber_kem.Kyber_512}},
+		{{Peer_Static:server_sk.Public()},{Static:server_sk}},
+		{{Static:client_sk,Peer_Static:server_sk.Public()},{Static:server_sk,Peer_Static:client_sk.Public()}},
+	}
+	for _,config:=range configs{
+		client,server,_,err,server_err:=connect(config[0],config[1])
+		if err!=nil||server_err!=nil{
+			t.Fatal(err,server_err)
+		}
+		echo(t,client,server,3*Max_record_len+17)
+		go client.Close()
+		if _,err=server.Read(make([]byte,1));err!=io.EOF{
+			t.Fatal("close-notify was not delivered")
+		}
+		server.Close()
+		if _,err=client.Write([]byte("late"));err!=Err_Closed{
+			t.Fatal("write succeeded after close")
+		}
+	}
+}
+
+func Test_Handshake_Failure(t *testing.T){
+	server_sk,_:=kyber_kem.Kyber_768.Keygen()
+	impostor,_:=kyber_kem.Kyber_768.Keygen()
+	client_sk,_:=kyber_kem.Kyber_768.Keygen()
+	_,_,_,err,_:=connect(&Config{Peer_Static:impostor.Public()},&Config{Static:server_sk})
+	if err!=kyber_ake.Err_Confirm{
+		t.Fatal("client connected to a server without its static key")
+	}
+	_,_,_,_,server_err:=connect(&Config{Static:impostor,Peer_Static:server_sk.Public()},&Config{Static:server_sk,Peer_Static:client_sk.Public()})
+	if server_err==nil{
+		t.Fatal("server accepted a client without its static key")
+	}
+	_,_,_,_,server_err=connect(&Config{},&Config{Static:server_sk})
+	if server_err!=Err_Mode{
+		t.Fatal("server accepted an unauthenticated client when it expected UAKE")
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	client,server,client_conn,err,server_err:=connect(&Config{},&Config{})
+	if err!=nil||server_err!=nil{
+		t.Fatal(err,server_err)
+	}
+	client_conn.armed=true
+	go client.Write([]byte("hello"))
+	if _,err=server.Read(make([]byte,5));err!=Err_Record{
+		t.Fatal("tampered record was accepted")
+	}
+	if _,err=server.Read(make([]byte,5));err!=Err_Record{
+		t.Fatal("connection kept reading after a bad record")
+	}
+}
+
+func Test_Rekey(t *testing.T){
+	client,server,_,err,server_err:=connect(&Config{Rekey_After:3},&Config{Rekey_After:2})
+	if err!=nil||server_err!=nil{
+		t.Fatal(err,server_err)
+	}
+	first:=append([]byte(nil),client.out.key...)
+	for i:=0;i<10;i++{
+		echo(t,client,server,100)
+	}
+	if bytes.Equal(first,client.out.key)||!bytes.Equal(client.out.key,server.in.key){
+		t.Fatal("keys were not rotated in step")
+	}
+}
@@ -0,0 +1,216 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains indirect proof of possession for a request, the challenge and response of RFC 4210 section 5.2.8.2 with an encapsulation in place of the encrypted random
+	challenge:  id(16)||c                        (c,K)=Enc(request public key)
+	response:   id(16)||mac                      mac=KMAC256(KMAC256(K,id,"kyber_csr pop key",32),"kyber_csr pop"||id||request,32)
+only the holder of the private key gets K, the mac binds it to the exact request bytes and every challenge is answered once before it expires
+*/
+package kyber_csr
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"bytes"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const(
+	id_len=16
+	mac_len=32
+)
+
+var(
+	Err_Challenge=errors.New("kyber_csr: challenge is unknown, answered or expired")
+	Err_POP=errors.New("kyber_csr: proof of possession failed")
+	Err_Key=errors.New("kyber_csr: private key does not match the request")
+)
+
+//what a requester talks to, a CA in process or a client for a remote one
+type Authority interface{
+	Challenge(request []byte)([]byte,error)
+	Issue(response []byte)([]byte,error)
+}
+
+type pending struct{
+	request []byte
+	parsed *Request
+	mac_key []byte
+	expires time.Time
+}
+
+type CA struct{
+	Name string
+	Validity time.Duration
+	Challenge_TTL time.Duration
+	Now func()time.Time
+	Approve func(r *Request)error//policy checked before a challenge is made, nil approves every request
+	key ed25519.PrivateKey
+	mu sync.Mutex
+	pending map[[id_len]byte]*pending
+	serial uint64
+}
+
+func New_CA(name string)(*CA,error){
+	if !valid_name(name){
+		return nil,Err_Format
+	}
+	_,key,err:=ed25519.GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	return &CA{
+		Name:name,
+		Validity:90*24*time.Hour,
+		Challenge_TTL:5*time.Minute,
+		Now:time.Now,
+		key:key,
+		pending:map[[id_len]byte]*pending{},
+	},nil
+}
+
+func (ca *CA)Public_Key()ed25519.PublicKey{
+	return ca.key.Public().(ed25519.PublicKey)
+}
+
+func mac_key(K,id []byte)([]byte,error){
+	return kyber_kdf.KMAC256.Derive(K,id,"kyber_csr pop key",mac_len)
+}
+
+func pop_mac(key,id,request []byte)[]byte{
+	data:=append(append([]byte("kyber_csr pop"),id...),request...)
+	return kyber_kdf.KMAC_256(key,data,mac_len,nil)
+}
+
+//parses and approves a request and encapsulates a challenge to its key
+func (ca *CA)Challenge(request []byte)([]byte,error){
+	r,err:=Parse_Request(request)
+	if err!=nil{
+		return nil,err
+	}
+	if ca.Approve!=nil{
+		if err=ca.Approve(r);err!=nil{
+			return nil,err
+		}
+	}
+	c,K,err:=r.Public_Key.Enc()
+	if err!=nil{
+		return nil,err
+	}
+	var id [id_len]byte
+	if _,err=rand.Read(id[:]);err!=nil{
+		return nil,err
+	}
+	key,err:=mac_key(K,id[:])
+	if err!=nil{
+		return nil,err
+	}
+	now:=ca.Now()
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	for k,p:=range ca.pending{
+		if now.After(p.expires){
+			delete(ca.pending,k)
+		}
+	}
+	ca.pending[id]=&pending{append([]byte(nil),request...),r,key,now.Add(ca.Challenge_TTL)}
+	return append(id[:],c...),nil
+}
+
+//checks the response to a challenge and issues the certificate, a challenge takes one response whether it is right or not
+func (ca *CA)Issue(response []byte)([]byte,error){
+	if len(response)!=id_len+mac_len{
+		return nil,Err_Format
+	}
+	id:=[id_len]byte(response[:id_len])
+	now:=ca.Now()
+	ca.mu.Lock()
+	p:=ca.pending[id]
+	delete(ca.pending,id)
+	if p==nil||now.After(p.expires){
+		ca.mu.Unlock()
+		return nil,Err_Challenge
+	}
+	if subtle.ConstantTimeCompare(pop_mac(p.mac_key,id[:],p.request),response[id_len:])!=1{
+		ca.mu.Unlock()
+		return nil,Err_POP
+	}
+	ca.serial++
+	c:=&Certificate{
+		Serial:ca.serial,
+		Issuer:ca.Name,
+		Subject:p.parsed.Subject,
+		DNS_Names:p.parsed.DNS_Names,
+		Public_Key:p.parsed.Public_Key,
+		Not_Before:now.Truncate(time.Second),
+		Not_After:now.Add(ca.Validity).Truncate(time.Second),
+	}
+	ca.mu.Unlock()
+	c.Signature=ed25519.Sign(ca.key,c.signed_bytes())
+	return c.To_Bytes(),nil
+}
+
+//the requester's answer to a challenge for its own request
+func Respond(sk kyber_kem.Private_Key,request,challenge []byte)([]byte,error){
+	r,err:=Parse_Request(request)
+	if err!=nil{
+		return nil,err
+	}
+	if !bytes.Equal(r.Public_Key.To_Bytes(),sk.Public().To_Bytes()){
+		return nil,Err_Key
+	}
+	if len(challenge)!=id_len+sk.Scheme().Ct_Len(){
+		return nil,Err_Format
+	}
+	id:=challenge[:id_len]
+	K,err:=sk.Dec(challenge[id_len:])
+	if err!=nil{
+		return nil,err
+	}
+	key,err:=mac_key(K,id)
+	if err!=nil{
+		return nil,err
+	}
+	return append(append([]byte(nil),id...),pop_mac(key,id,request)...),nil
+}
+
+//requests, proves possession and checks the certificate that comes back
+func Enroll(a Authority,ca ed25519.PublicKey,sk kyber_kem.Private_Key,subject string,dns_names []string)(*Certificate,error){
+	r,err:=New_Request(subject,dns_names,sk.Public())
+	if err!=nil{
+		return nil,err
+	}
+	request:=r.To_Bytes()
+	challenge,err:=a.Challenge(request)
+	if err!=nil{
+		return nil,err
+	}
+	response,err:=Respond(sk,request,challenge)
+	if err!=nil{
+		return nil,err
+	}
+	data,err:=a.Issue(response)
+	if err!=nil{
+		return nil,err
+	}
+	c,err:=Parse_Certificate(data)
+	if err!=nil{
+		return nil,err
+	}
+	if err=c.Verify(ca,c.Not_Before);err!=nil{
+		return nil,err
+	}
+	if c.Subject!=subject||!slices.Equal(c.DNS_Names,r.DNS_Names)||!bytes.Equal(c.Public_Key.To_Bytes(),sk.Public().To_Bytes()){
+		return nil,Err_Certificate
+	}
+	return c,nil
+}
@@ -0,0 +1,204 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains certificate requests for kyber keys and the certificates a CA issues for them
+a KEM key can not sign a PKCS#10 request and crypto/x509 can not carry a kyber key, so both are length prefixed structures like kyber_kemtls.Certificate
+	request:      version(1)||len(2)||subject||count(1)||count*(len(2)||DNS name)||len(2)||scheme name||len(2)||public key
+	certificate:  tbs||len(2)||ed25519 signature over "kyber_csr certificate"||tbs
+	tbs:          version(1)||serial(8)||len(2)||issuer||request fields from subject on||not before(8)||not after(8)
+times are unix seconds, every length is big endian
+*/
+package kyber_csr
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const(
+	version=1
+	Max_Names=32
+)
+
+var(
+	Err_Format=errors.New("kyber_csr: malformed request, challenge or certificate")
+	Err_Certificate=errors.New("kyber_csr: certificate is not valid")
+)
+
+type Request struct{
+	Subject string
+	DNS_Names []string
+	Public_Key kyber_kem.Public_Key
+}
+
+type Certificate struct{
+	Serial uint64
+	Issuer string
+	Subject string
+	DNS_Names []string
+	Public_Key kyber_kem.Public_Key
+	Not_Before,Not_After time.Time
+	Signature []byte
+}
+
+func put_string(b []byte,s []byte)[]byte{
+	return append(binary.BigEndian.AppendUint16(b,uint16(len(s))),s...)
+}
+
+type reader struct{
+	data []byte
+	ok bool
+}
+
+func (r *reader)fixed(n int)[]byte{
+	if !r.ok||len(r.data)<n{
+		r.ok=false
+		return nil
+	}
+	out:=r.data[:n]
+	r.data=r.data[n:]
+	return out
+}
+
+func (r *reader)string()[]byte{
+	l:=r.fixed(2)
+	if l==nil{
+		return nil
+	}
+	return r.fixed(int(binary.BigEndian.Uint16(l)))
+}
+
+func valid_name(s string)bool{
+	return s!=""&&len(s)<=255
+}
+
+//subject and key fields shared by the request and the certificate
+func (r *Request)fields(b []byte)[]byte{
+	b=put_string(b,[]byte(r.Subject))
+	b=append(b,byte(len(r.DNS_Names)))
+	for _,name:=range r.DNS_Names{
+		b=put_string(b,[]byte(name))
+	}
+	b=put_string(b,[]byte(r.Public_Key.Scheme().Name()))
+	return put_string(b,r.Public_Key.To_Bytes())
+}
+
+func (r *reader)fields()(*Request,error){
+	req:=&Request{Subject:string(r.string())}
+	count:=r.fixed(1)
+	if count==nil||int(count[0])>Max_Names{
+		return nil,Err_Format
+	}
+	for i:=0;i<int(count[0]);i++{
+		name:=r.string()
+		if !valid_name(string(name)){
+			return nil,Err_Format
+		}
+		req.DNS_Names=append(req.DNS_Names,string(name))
+	}
+	scheme,pk:=r.string(),r.string()
+	if !r.ok||!valid_name(req.Subject){
+		return nil,Err_Format
+	}
+	s,err:=kyber_kem.Scheme_by_Name(string(scheme))
+	if err!=nil{
+		return nil,Err_Format
+	}
+	if req.Public_Key,err=s.Bytes_to_Pk(pk);err!=nil{
+		return nil,Err_Format
+	}
+	return req,nil
+}
+
+func New_Request(subject string,dns_names []string,pk kyber_kem.Public_Key)(*Request,error){
+	r:=&Request{subject,append([]string(nil),dns_names...),pk}
+	if !valid_name(subject)||len(dns_names)>Max_Names||pk==nil{
+		return nil,Err_Format
+	}
+	for _,name:=range dns_names{
+		if !valid_name(name){
+			return nil,Err_Format
+		}
+	}
+	return r,nil
+}
+
+func (r *Request)To_Bytes()[]byte{
+	return r.fields([]byte{version})
+}
+
+func Parse_Request(data []byte)(*Request,error){
+	r:=&reader{data,true}
+	if v:=r.fixed(1);v==nil||v[0]!=version{
+		return nil,Err_Format
+	}
+	req,err:=r.fields()
+	if err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	return req,nil
+}
+
+func (c *Certificate)tbs()[]byte{
+	b:=binary.BigEndian.AppendUint64([]byte{version},c.Serial)
+	b=put_string(b,[]byte(c.Issuer))
+	b=(&Request{c.Subject,c.DNS_Names,c.Public_Key}).fields(b)
+	b=binary.BigEndian.AppendUint64(b,uint64(c.Not_Before.Unix()))
+	return binary.BigEndian.AppendUint64(b,uint64(c.Not_After.Unix()))
+}
+
+func (c *Certificate)signed_bytes()[]byte{
+	return append([]byte("kyber_csr certificate"),c.tbs()...)
+}
+
+func (c *Certificate)To_Bytes()[]byte{
+	return put_string(c.tbs(),c.Signature)
+}
+
+func Parse_Certificate(data []byte)(*Certificate,error){
+	r:=&reader{data,true}
+	if v:=r.fixed(1);v==nil||v[0]!=version{
+		return nil,Err_Format
+	}
+	serial:=r.fixed(8)
+	issuer:=r.string()
+	if !r.ok{
+		return nil,Err_Format
+	}
+	req,err:=r.fields()
+	if err!=nil{
+		return nil,err
+	}
+	not_before,not_after:=r.fixed(8),r.fixed(8)
+	sig:=r.string()
+	if !r.ok||len(r.data)!=0||!valid_name(string(issuer)){
+		return nil,Err_Format
+	}
+	return &Certificate{
+		Serial:binary.BigEndian.Uint64(serial),
+		Issuer:string(issuer),
+		Subject:req.Subject,
+		DNS_Names:req.DNS_Names,
+		Public_Key:req.Public_Key,
+		Not_Before:time.Unix(int64(binary.BigEndian.Uint64(not_before)),0),
+		Not_After:time.Unix(int64(binary.BigEndian.Uint64(not_after)),0),
+		Signature:append([]byte(nil),sig...),
+	},nil
+}
+
+//checks the CA signature and that now is inside the validity period
+func (c *Certificate)Verify(ca ed25519.PublicKey,now time.Time)error{
+	if len(ca)!=ed25519.PublicKeySize||!ed25519.Verify(ca,c.signed_bytes(),c.Signature){
+		return Err_Certificate
+	}
+	if now.Before(c.Not_Before)||now.After(c.Not_After){
+		return Err_Certificate
+	}
+	return nil
+}
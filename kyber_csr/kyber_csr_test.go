@@ -0,0 +1,182 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on requests, certificates and enrolment with an in process CA, including requesters that do not hold the key
+*/
+package kyber_csr
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Enroll(t *testing.T){
+	ca,err:=New_CA("test CA")
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,s:=range kyber_kem.All{
+		sk,_:=s.Keygen()
+		c,err:=Enroll(ca,ca.Public_Key(),sk,"service "+s.Name(),[]string{"a.example","b.example"})
+		if err!=nil{
+			t.Fatal(s.Name()+": "+err.Error())
+		}
+		if c.Issuer!="test CA"||c.Public_Key.Scheme()!=s||len(c.DNS_Names)!=2{
+			t.Fatal(s.Name()+" certificate has the wrong fields")
+		}
+		//the certified key is the one that proved possession
+		ct,K,_:=c.Public_Key.Enc()
+		if got,_:=sk.Dec(ct);!bytes.Equal(got,K){
+			t.Fatal(s.Name()+" certificate carries another key")
+		}
+		parsed,err:=Parse_Certificate(c.To_Bytes())
+		if err!=nil||!bytes.Equal(parsed.To_Bytes(),c.To_Bytes()){
+			t.Fatal(s.Name()+" certificate did not round trip")
+		}
+	}
+	if ca.serial!=uint64(len(kyber_kem.All)){
+		t.Fatal("serials were not counted")
+	}
+}
+
+func Test_POP(t *testing.T){
+	ca,_:=New_CA("test CA")
+	sk,_:=kyber_kem.Kyber_768.Keygen()
+	other,_:=kyber_kem.Kyber_768.Keygen()
+	r,_:=New_Request("victim",nil,sk.Public())
+	request:=r.To_Bytes()
+	//a requester with someone else's public key can not answer
+	challenge,err:=ca.Challenge(request)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=Respond(other,request,challenge);err!=Err_Key{
+		t.Fatal("response was made with a key that is not in the request")
+	}
+	forged:=append([]byte(nil),challenge[:id_len]...)
+	K,_:=other.Dec(challenge[id_len:])
+	key,_:=mac_key(K,forged)
+	forged=append(forged,pop_mac(key,forged,request)...)
+	if _,err=ca.Issue(forged);err!=Err_POP{
+		t.Fatal("certificate issued without the private key")
+	}
+	//a challenge takes one response, even a wrong one
+	response,_:=Respond(sk,request,challenge)
+	if _,err=ca.Issue(response);err!=Err_Challenge{
+		t.Fatal("challenge was answered twice")
+	}
+	//the response is bound to the request it was made for
+	challenge,_=ca.Challenge(request)
+	r2,_:=New_Request("victim",[]string{"evil.example"},sk.Public())
+	swapped,_:=Respond(sk,r2.To_Bytes(),challenge)
+	if _,err=ca.Issue(swapped);err!=Err_POP{
+		t.Fatal("response for another request was accepted")
+	}
+	challenge,_=ca.Challenge(request)
+	response,_=Respond(sk,request,challenge)
+	if _,err=ca.Issue(response);err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=ca.Issue(response);err!=Err_Challenge{
+		t.Fatal("response was replayed")
+	}
+	tampered:=append([]byte(nil),challenge...)
+	tampered[len(tampered)-1]^=1
+	bad,_:=Respond(sk,request,tampered)
+	if _,err=ca.Issue(bad);err!=Err_Challenge{
+		t.Fatal("answered challenge was reused")
+	}
+}
+
+func Test_Expiry(t *testing.T){
+	ca,_:=New_CA("test CA")
+	now:=time.Unix(1700000000,0)
+	ca.Now=func()time.Time{return now}
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	r,_:=New_Request("late",nil,sk.Public())
+	challenge,_:=ca.Challenge(r.To_Bytes())
+	response,_:=Respond(sk,r.To_Bytes(),challenge)
+	now=now.Add(ca.Challenge_TTL+time.Second)
+	if _,err:=ca.Issue(response);err!=Err_Challenge{
+		t.Fatal("expired challenge was answered")
+	}
+	ca.Challenge(r.To_Bytes())
+	if len(ca.pending)!=1{
+		t.Fatal("expired challenges were kept")
+	}
+	challenge,_=ca.Challenge(r.To_Bytes())
+	response,_=Respond(sk,r.To_Bytes(),challenge)
+	data,err:=ca.Issue(response)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,_:=Parse_Certificate(data)
+	for when,want:=range map[time.Duration]error{-time.Second:Err_Certificate,0:nil,ca.Validity:nil,ca.Validity+time.Second:Err_Certificate}{
+		if err=c.Verify(ca.Public_Key(),now.Add(when));err!=want{
+			t.Fatalf("verify at %v: %v",when,err)
+		}
+	}
+	other,_:=New_CA("test CA")
+	if c.Verify(other.Public_Key(),now)!=Err_Certificate||c.Verify(nil,now)!=Err_Certificate{
+		t.Fatal("certificate verified under another CA")
+	}
+	c.Subject="someone else"
+	if c.Verify(ca.Public_Key(),now)!=Err_Certificate{
+		t.Fatal("changed certificate verified")
+	}
+}
+
+func Test_Format(t *testing.T){
+	ca,_:=New_CA("test CA")
+	sk,_:=kyber_kem.Kyber_1024.Keygen()
+	too_many:=strings.Split(strings.Repeat("n.example,",Max_Names),",")
+	for _,names:=range [][]string{{""},{strings.Repeat("a",256)},append(too_many[:Max_Names],"last.example")}{
+		if _,err:=New_Request("x",names,sk.Public());err!=Err_Format{
+			t.Fatal("bad names were requested")
+		}
+	}
+	if _,err:=New_Request("",nil,sk.Public());err!=Err_Format{
+		t.Fatal("empty subject was requested")
+	}
+	r,_:=New_Request("x",[]string{"x.example"},sk.Public())
+	request:=r.To_Bytes()
+	for i:=0;i<len(request);i+=97{
+		if _,err:=ca.Challenge(request[:i]);err!=Err_Format{
+			t.Fatalf("request cut at %d was challenged",i)
+		}
+	}
+	if _,err:=ca.Challenge(append(request,0));err!=Err_Format{
+		t.Fatal("request with trailing data was challenged")
+	}
+	wrong:=bytes.Replace(request,[]byte("kyber_1024"),[]byte("kyber_9999"),1)
+	if _,err:=ca.Challenge(wrong);err!=Err_Format{
+		t.Fatal("request with an unknown scheme was challenged")
+	}
+	denied:=errors.New("not on the allow list")
+	ca.Approve=func(r *Request)error{
+		if !strings.HasSuffix(r.Subject,".internal"){
+			return denied
+		}
+		return nil
+	}
+	if _,err:=ca.Challenge(request);err!=denied{
+		t.Fatal("policy was not applied")
+	}
+	if _,err:=Enroll(ca,ca.Public_Key(),sk,"db.internal",nil);err!=nil{
+		t.Fatal(err)
+	}
+	if _,err:=ca.Issue(make([]byte,id_len));err!=Err_Format{
+		t.Fatal("short response was read")
+	}
+	challenge,_:=ca.Challenge((&Request{"db.internal",nil,sk.Public()}).To_Bytes())
+	if _,err:=Respond(sk,(&Request{"db.internal",nil,sk.Public()}).To_Bytes(),challenge[1:]);err!=Err_Format{
+		t.Fatal("short challenge was answered")
+	}
+}
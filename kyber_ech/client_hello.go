@@ -0,0 +1,122 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains just enough of the TLS 1.3 ClientHello to find, replace and expand extensions
+	ClientHello=legacy_version(2)||random(32)||session id<0..32>||cipher suites<2..2^16-2>||compression methods<1..2^8-1>||extensions<0..2^16-1>
+	extension=type(2)||data<0..2^16-1>
+the bytes are the ClientHello structure itself, without the 4 byte handshake header
+*/
+package kyber_ech
+
+import(
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	ext_server_name uint16=0x0000
+	ext_ech_outer_extensions uint16=0xfd00
+	Ext_ECH uint16=0xfe0d
+)
+
+var Err_Client_Hello=errors.New("kyber_ech: malformed ClientHello")
+
+type extension struct{
+	typ uint16
+	data []byte
+}
+
+type client_hello struct{
+	version_random []byte
+	session_id []byte
+	cipher_suites []byte
+	compression []byte
+	extensions []extension
+}
+
+type tls_reader struct{
+	data []byte
+	err error
+}
+
+func (r *tls_reader)fixed(n int)[]byte{
+	if r.err!=nil||len(r.data)<n{
+		r.err=Err_Client_Hello
+		return nil
+	}
+	out:=r.data[:n]
+	r.data=r.data[n:]
+	return out
+}
+
+func (r *tls_reader)u8()byte{
+	b:=r.fixed(1)
+	if b==nil{
+		return 0
+	}
+	return b[0]
+}
+
+func (r *tls_reader)u16()uint16{
+	b:=r.fixed(2)
+	if b==nil{
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *tls_reader)vec8()[]byte{
+	return r.fixed(int(r.u8()))
+}
+
+func (r *tls_reader)vec16()[]byte{
+	return r.fixed(int(r.u16()))
+}
+
+func put_vec8(out,field []byte)[]byte{
+	return append(append(out,byte(len(field))),field...)
+}
+
+func put_vec16(out,field []byte)[]byte{
+	return append(binary.BigEndian.AppendUint16(out,uint16(len(field))),field...)
+}
+
+//rest is whatever follows the ClientHello, the padding of an encoded inner hello
+func parse_client_hello(data []byte)(ch *client_hello,rest []byte,err error){
+	r:=&tls_reader{data:data}
+	ch=&client_hello{version_random:r.fixed(34),session_id:r.vec8(),cipher_suites:r.vec16(),compression:r.vec8()}
+	extensions:=&tls_reader{data:r.vec16()}
+	for r.err==nil&&len(extensions.data)>0{
+		ext:=extension{extensions.u16(),extensions.vec16()}
+		if extensions.err!=nil{
+			return nil,nil,Err_Client_Hello
+		}
+		ch.extensions=append(ch.extensions,ext)
+	}
+	if r.err!=nil||len(ch.session_id)>32{
+		return nil,nil,Err_Client_Hello
+	}
+	return ch,r.data,nil
+}
+
+func (ch *client_hello)marshal()[]byte{
+	out:=append([]byte(nil),ch.version_random...)
+	out=put_vec8(put_vec16(put_vec8(out,ch.session_id),ch.cipher_suites),ch.compression)
+	var extensions []byte
+	for _,ext:=range ch.extensions{
+		extensions=put_vec16(binary.BigEndian.AppendUint16(extensions,ext.typ),ext.data)
+	}
+	return put_vec16(out,extensions)
+}
+
+func (ch *client_hello)find(typ uint16)(int,[]byte){
+	for i,ext:=range ch.extensions{
+		if ext.typ==typ{
+			return i,ext.data
+		}
+	}
+	return -1,nil
+}
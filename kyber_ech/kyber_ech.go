@@ -0,0 +1,392 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains TLS Encrypted Client Hello (draft-ietf-tls-esni version 0xfe0d) configurations and ClientHelloInner encryption with the
+kyber HPKE KEMs, for TLS front-ends that build and read their own ClientHello messages
+
+	ECHConfig:        version(2)||length(2)||config id(1)||kem id(2)||public key<1..2^16-1>||cipher suites<4..2^16-4>||maximum name length(1)||public name<1..255>||extensions<0..2^16-1>
+	ECHConfigList:    ECHConfig<4..2^16-1>
+	outer extension:  0||kdf id(2)||aead id(2)||config id(1)||enc<0..2^16-1>||payload<1..2^16-1>
+	payload:          HPKE seal(info="tls ech"||0||ECHConfig,aad=ClientHelloOuter with the payload zeroed,EncodedClientHelloInner)
+the KEM ids are the ones of kyber_hpke, X25519Kyber768Draft00 (0x0030) is the one TLS stacks know, kyber_768 alone (0xff41) is a private id since
+round 3 kyber is not FIPS 203 ML-KEM
+*/
+package kyber_ech
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hpke"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	Version uint16=0xfe0d
+	info_label="tls ech"
+	ech_outer byte=0
+	ech_inner byte=1
+	aead_overhead=16//every kyber_hpke AEAD has a 16 byte tag
+)
+
+var(
+	Err_Config=errors.New("kyber_ech: malformed or unsupported ECH configuration")
+	Err_No_ECH=errors.New("kyber_ech: ClientHello has no encrypted_client_hello extension")
+	Err_Decrypt=errors.New("kyber_ech: ClientHelloInner could not be decrypted")
+)
+
+type Cipher_Suite struct{
+	KDF uint16
+	AEAD uint16
+}
+
+type Config struct{
+	Config_ID byte
+	KEM *kyber_hpke.KEM
+	Public_Key kyber_kem.Public_Key
+	Suites []Cipher_Suite
+	Maximum_Name_Length byte
+	Public_Name string
+	Extensions []byte//the encoded extension list, without its length
+}
+
+//the server side of a config
+type Key struct{
+	Config *Config
+	Private_Key kyber_kem.Private_Key
+}
+
+func Generate_Key(config_id byte,public_name string,kem *kyber_hpke.KEM,suites ...Cipher_Suite)(*Key,error){
+	if len(public_name)==0||len(public_name)>255{
+		return nil,Err_Config
+	}
+	if len(suites)==0{
+		suites=[]Cipher_Suite{{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_AES_128_GCM}}
+	}
+	sk,err:=kem.Scheme.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	return &Key{&Config{Config_ID:config_id,KEM:kem,Public_Key:sk.Public(),Suites:suites,Public_Name:public_name},sk},nil
+}
+
+func (c *Config)To_Bytes()[]byte{
+	contents:=binary.BigEndian.AppendUint16([]byte{c.Config_ID},c.KEM.ID)
+	contents=put_vec16(contents,c.Public_Key.To_Bytes())
+	var suites []byte
+	for _,s:=range c.Suites{
+		suites=binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(suites,s.KDF),s.AEAD)
+	}
+	contents=put_vec16(contents,suites)
+	contents=put_vec8(append(contents,c.Maximum_Name_Length),[]byte(c.Public_Name))
+	contents=put_vec16(contents,c.Extensions)
+	return put_vec16(binary.BigEndian.AppendUint16(nil,Version),contents)
+}
+
+func Marshal_Config_List(configs []*Config)[]byte{
+	var list []byte
+	for _,c:=range configs{
+		list=append(list,c.To_Bytes()...)
+	}
+	return put_vec16(nil,list)
+}
+
+//nil,nil for a well formed config of another version, KEM or with a mandatory extension this package does not know
+func parse_config(version uint16,contents []byte)(*Config,error){
+	if version!=Version{
+		return nil,nil
+	}
+	r:=&tls_reader{data:contents}
+	c:=&Config{Config_ID:r.u8()}
+	kem_id:=r.u16()
+	pk,suites:=r.vec16(),r.vec16()
+	c.Maximum_Name_Length=r.u8()
+	c.Public_Name=string(r.vec8())
+	c.Extensions=r.vec16()
+	if r.err!=nil||len(r.data)!=0||len(pk)==0||len(suites)==0||len(suites)%4!=0||len(c.Public_Name)==0{
+		return nil,Err_Config
+	}
+	for i:=0;i<len(suites);i+=4{
+		c.Suites=append(c.Suites,Cipher_Suite{binary.BigEndian.Uint16(suites[i:]),binary.BigEndian.Uint16(suites[i+2:])})
+	}
+	extensions:=&tls_reader{data:c.Extensions}
+	mandatory:=false
+	for len(extensions.data)>0{
+		typ:=extensions.u16()
+		extensions.vec16()
+		mandatory=mandatory||typ&0x8000!=0
+	}
+	if extensions.err!=nil{
+		return nil,Err_Config
+	}
+	kem,err:=kyber_hpke.KEM_by_ID(kem_id)
+	if err!=nil||mandatory{
+		return nil,nil
+	}
+	if c.Public_Key,err=kem.Scheme.Bytes_to_Pk(pk);err!=nil{
+		return nil,Err_Config
+	}
+	c.KEM=kem
+	return c,nil
+}
+
+//configs this package can not use are skipped, the list may come out empty
+func Unmarshal_Config_List(data []byte)([]*Config,error){
+	r:=&tls_reader{data:data}
+	list:=&tls_reader{data:r.vec16()}
+	if r.err!=nil||len(r.data)!=0||len(list.data)==0{
+		return nil,Err_Config
+	}
+	var configs []*Config
+	for len(list.data)>0{
+		version:=list.u16()
+		contents:=list.vec16()
+		if list.err!=nil{
+			return nil,Err_Config
+		}
+		c,err:=parse_config(version,contents)
+		if err!=nil{
+			return nil,err
+		}
+		if c!=nil{
+			configs=append(configs,c)
+		}
+	}
+	return configs,nil
+}
+
+func (c *Config)info()[]byte{
+	return append(append([]byte(info_label),0),c.To_Bytes()...)
+}
+
+func (c *Config)hpke_suite(s Cipher_Suite)*kyber_hpke.Suite{
+	return &kyber_hpke.Suite{KEM:c.KEM,KDF:s.KDF,AEAD:s.AEAD}
+}
+
+func (c *Config)offers(s Cipher_Suite)bool{
+	for _,offered:=range c.Suites{
+		if offered==s{
+			return true
+		}
+	}
+	return false
+}
+
+func marshal_outer(s Cipher_Suite,config_id byte,enc,payload []byte)[]byte{
+	out:=binary.BigEndian.AppendUint16([]byte{ech_outer},s.KDF)
+	out=append(binary.BigEndian.AppendUint16(out,s.AEAD),config_id)
+	return put_vec16(put_vec16(out,enc),payload)
+}
+
+//the inner ClientHello is padded so the server name and total length do not show through the payload length
+func encode_inner(c *Config,inner []byte)([]byte,error){
+	ch,rest,err:=parse_client_hello(inner)
+	if err!=nil||len(rest)!=0{
+		return nil,Err_Client_Hello
+	}
+	if _,ech:=ch.find(Ext_ECH);!bytes.Equal(ech,[]byte{ech_inner}){
+		return nil,Err_Client_Hello
+	}
+	ch.session_id=nil
+	encoded:=ch.marshal()
+	pad:=int(c.Maximum_Name_Length)+9
+	if i,sni:=ch.find(ext_server_name);i>=0{
+		r:=&tls_reader{data:sni}
+		r.u16()
+		r.u8()
+		pad=max(0,int(c.Maximum_Name_Length)-len(r.vec16()))
+	}
+	pad+=31-(len(encoded)+pad-1)%32
+	return append(encoded,make([]byte,pad)...),nil
+}
+
+//the client's HPKE context, kept for the second ClientHello after a HelloRetryRequest
+type Client_Context struct{
+	config *Config
+	suite Cipher_Suite
+	hpke *kyber_hpke.Context
+}
+
+/*
+inner is the ClientHelloInner, it has to carry an inner encrypted_client_hello extension (the single byte 1) and the same session id as the outer hello,
+outer builds the ClientHelloOuter around the given encrypted_client_hello extension data, it is called twice and has to build the same hello
+around a zeroed and the real payload
+*/
+func (c *Config)Seal_Client_Hello(inner []byte,outer func(ech []byte)([]byte,error))(outer_hello []byte,ctx *Client_Context,err error){
+	ctx=&Client_Context{config:c}
+	found:=false
+	for _,s:=range c.Suites{
+		if s.KDF==kyber_hpke.KDF_HKDF_SHA256&&c.hpke_suite(s).N_k()!=0{
+			ctx.suite,found=s,true
+			break
+		}
+	}
+	if !found{
+		return nil,nil,Err_Config
+	}
+	enc,hpke,err:=c.hpke_suite(ctx.suite).Setup_Sender(c.Public_Key,c.info())
+	if err!=nil{
+		return nil,nil,err
+	}
+	ctx.hpke=hpke
+	outer_hello,err=ctx.seal(enc,inner,outer)
+	if err!=nil{
+		return nil,nil,err
+	}
+	return outer_hello,ctx,nil
+}
+
+//the second ClientHello after a HelloRetryRequest, it reuses the context and sends an empty enc
+func (ctx *Client_Context)Seal_Client_Hello(inner []byte,outer func(ech []byte)([]byte,error))([]byte,error){
+	return ctx.seal(nil,inner,outer)
+}
+
+func (ctx *Client_Context)seal(enc,inner []byte,outer func(ech []byte)([]byte,error))([]byte,error){
+	encoded,err:=encode_inner(ctx.config,inner)
+	if err!=nil{
+		return nil,err
+	}
+	zeroed:=make([]byte,len(encoded)+aead_overhead)
+	aad,err:=outer(marshal_outer(ctx.suite,ctx.config.Config_ID,enc,zeroed))
+	if err!=nil{
+		return nil,err
+	}
+	payload,err:=ctx.hpke.Seal(aad,encoded)
+	if err!=nil{
+		return nil,err
+	}
+	outer_hello,err:=outer(marshal_outer(ctx.suite,ctx.config.Config_ID,enc,payload))
+	if err!=nil{
+		return nil,err
+	}
+	if len(outer_hello)!=len(aad){
+		return nil,Err_Client_Hello
+	}
+	return outer_hello,nil
+}
+
+//the server's HPKE context, kept for the second ClientHello after a HelloRetryRequest
+type Server_Context struct{
+	key *Key
+	suite Cipher_Suite
+	hpke *kyber_hpke.Context
+}
+
+type outer_ech struct{
+	suite Cipher_Suite
+	config_id byte
+	enc []byte
+	payload []byte
+}
+
+func parse_outer(outer []byte)(ch *client_hello,index int,ech *outer_ech,err error){
+	ch,rest,err:=parse_client_hello(outer)
+	if err!=nil||len(rest)!=0{
+		return nil,0,nil,Err_Client_Hello
+	}
+	index,data:=ch.find(Ext_ECH)
+	if index<0{
+		return nil,0,nil,Err_No_ECH
+	}
+	r:=&tls_reader{data:data}
+	typ:=r.u8()
+	ech=&outer_ech{suite:Cipher_Suite{r.u16(),r.u16()},config_id:r.u8(),enc:r.vec16(),payload:r.vec16()}
+	if r.err!=nil||len(r.data)!=0||typ!=ech_outer||len(ech.payload)==0{
+		return nil,0,nil,Err_Client_Hello
+	}
+	return ch,index,ech,nil
+}
+
+func open_payload(hpke *kyber_hpke.Context,ch *client_hello,index int,ech *outer_ech)([]byte,error){
+	zeroed:=*ch
+	zeroed.extensions=append([]extension(nil),ch.extensions...)
+	zeroed.extensions[index].data=marshal_outer(ech.suite,ech.config_id,ech.enc,make([]byte,len(ech.payload)))
+	encoded,err:=hpke.Open(zeroed.marshal(),ech.payload)
+	if err!=nil{
+		return nil,Err_Decrypt
+	}
+	return decode_inner(encoded,ch)
+}
+
+/*
+finds the key for the ClientHelloOuter's config id and returns the reconstructed ClientHelloInner, on Err_Decrypt the server goes on with the
+outer hello and sends its retry configs
+*/
+func Open_Client_Hello(keys []*Key,outer []byte)(inner []byte,ctx *Server_Context,err error){
+	ch,index,ech,err:=parse_outer(outer)
+	if err!=nil{
+		return nil,nil,err
+	}
+	if len(ech.enc)==0{
+		return nil,nil,Err_Client_Hello
+	}
+	for _,k:=range keys{
+		if k.Config.Config_ID!=ech.config_id||!k.Config.offers(ech.suite){
+			continue
+		}
+		hpke,err:=k.Config.hpke_suite(ech.suite).Setup_Receiver(k.Private_Key,ech.enc,k.Config.info())
+		if err!=nil{
+			continue
+		}
+		if inner,err=open_payload(hpke,ch,index,ech);err==nil{
+			return inner,&Server_Context{k,ech.suite,hpke},nil
+		}
+		if err!=Err_Decrypt{
+			return nil,nil,err
+		}
+	}
+	return nil,nil,Err_Decrypt
+}
+
+//the second ClientHello after a HelloRetryRequest, it has to use the same config and suite with an empty enc
+func (ctx *Server_Context)Open_Client_Hello(outer []byte)([]byte,error){
+	ch,index,ech,err:=parse_outer(outer)
+	if err!=nil{
+		return nil,err
+	}
+	if len(ech.enc)!=0||ech.config_id!=ctx.key.Config.Config_ID||ech.suite!=ctx.suite{
+		return nil,Err_Client_Hello
+	}
+	return open_payload(ctx.hpke,ch,index,ech)
+}
+
+//undoes the padding, restores the session id and expands ech_outer_extensions from the outer hello
+func decode_inner(encoded []byte,outer *client_hello)([]byte,error){
+	ch,rest,err:=parse_client_hello(encoded)
+	if err!=nil||len(ch.session_id)!=0||len(bytes.Trim(rest,"\x00"))!=0{
+		return nil,Err_Client_Hello
+	}
+	ch.session_id=outer.session_id
+	var extensions []extension
+	next:=0
+	for _,ext:=range ch.extensions{
+		if ext.typ!=ext_ech_outer_extensions{
+			extensions=append(extensions,ext)
+			continue
+		}
+		r:=&tls_reader{data:ext.data}
+		types:=r.vec8()
+		if r.err!=nil||len(r.data)!=0||len(types)==0||len(types)%2!=0{
+			return nil,Err_Client_Hello
+		}
+		for i:=0;i<len(types);i+=2{
+			typ:=binary.BigEndian.Uint16(types[i:])
+			for next<len(outer.extensions)&&outer.extensions[next].typ!=typ{
+				next++
+			}
+			if typ==Ext_ECH||next==len(outer.extensions){
+				return nil,Err_Client_Hello
+			}
+			extensions=append(extensions,outer.extensions[next])
+			next++
+		}
+	}
+	ch.extensions=extensions
+	if _,ech:=ch.find(Ext_ECH);!bytes.Equal(ech,[]byte{ech_inner}){
+		return nil,Err_Client_Hello
+	}
+	return ch.marshal(),nil
+}
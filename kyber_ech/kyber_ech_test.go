@@ -0,0 +1,154 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on ECH configurations and on sealing and opening synthetic ClientHello messages
+*/
+package kyber_ech
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hpke"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+const ext_key_share uint16=0x0033
+
+func server_name(host string)extension{
+	return extension{ext_server_name,put_vec16(nil,put_vec16([]byte{0},[]byte(host)))}
+}
+
+func hello(session_id []byte,extensions ...extension)[]byte{
+	ch:=&client_hello{version_random:make([]byte,34),session_id:session_id,cipher_suites:[]byte{0x13,0x01},compression:[]byte{0},extensions:extensions}
+	ch.version_random[0],ch.version_random[1],ch.version_random[2]=3,3,7
+	return ch.marshal()
+}
+
+func Test_Config_List(t *testing.T){
+	a,err:=Generate_Key(1,"public.example",kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	b,err:=Generate_Key(2,"public.example",kyber_hpke.KEM_Kyber768,Cipher_Suite{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_ChaCha20_Poly1305},Cipher_Suite{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_AES_256_GCM})
+	if err!=nil{
+		t.Fatal(err)
+	}
+	b.Config.Maximum_Name_Length=32
+	b.Config.Extensions=[]byte{0x00,0x01,0x00,0x02,'h','i'}
+	data:=Marshal_Config_List([]*Config{a.Config,b.Config})
+	skipped:=Marshal_Config_List([]*Config{a.Config})[2:]
+	binary.BigEndian.PutUint16(skipped,0xfe0c)//an older draft version
+	mandatory:=*a.Config
+	mandatory.Extensions=[]byte{0x80,0x01,0x00,0x00}
+	skipped=append(skipped,mandatory.To_Bytes()...)
+	list:=put_vec16(nil,append(data[2:],skipped...))
+	configs,err:=Unmarshal_Config_List(list)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(configs)!=2||!bytes.Equal(configs[0].To_Bytes(),a.Config.To_Bytes())||!bytes.Equal(configs[1].To_Bytes(),b.Config.To_Bytes())||len(configs[1].Suites)!=2{
+		t.Fatal("config list did not round trip")
+	}
+	for _,bad:=range [][]byte{nil,{0,0},list[:len(list)-1],append(list,0)}{
+		if _,err=Unmarshal_Config_List(bad);err==nil{
+			t.Fatal("malformed config list was read")
+		}
+	}
+	if _,err=Generate_Key(3,"",kyber_hpke.KEM_Kyber768);err!=Err_Config{
+		t.Fatal("config without a public name was generated")
+	}
+}
+
+func Test_Client_Hello(t *testing.T){
+	session_id:=bytes.Repeat([]byte{0x5a},32)
+	key_share:=extension{ext_key_share,bytes.Repeat([]byte{0x11},40)}
+	for _,kem:=range []*kyber_hpke.KEM{kyber_hpke.KEM_X25519_Kyber768_Draft00,kyber_hpke.KEM_Kyber768}{
+		k,err:=Generate_Key(7,"public.example",kem)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		k.Config.Maximum_Name_Length=32
+		//a client published list, the server keeps the private key
+		configs,_:=Unmarshal_Config_List(Marshal_Config_List([]*Config{k.Config}))
+		config:=configs[0]
+		outer:=func(ech []byte)([]byte,error){
+			return hello(session_id,server_name("public.example"),key_share,extension{Ext_ECH,ech}),nil
+		}
+		//the key share is compressed into the outer hello with ech_outer_extensions
+		compressed:=extension{ext_ech_outer_extensions,put_vec8(nil,binary.BigEndian.AppendUint16(nil,ext_key_share))}
+		inner:=hello(session_id,server_name("secret.example"),compressed,extension{Ext_ECH,[]byte{ech_inner}})
+		want:=hello(session_id,server_name("secret.example"),key_share,extension{Ext_ECH,[]byte{ech_inner}})
+		outer_hello,client,err:=config.Seal_Client_Hello(inner,outer)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if bytes.Contains(outer_hello,[]byte("secret.example")){
+			t.Fatal(kem.Scheme.Name()+" outer hello leaks the inner server name")
+		}
+		got,server,err:=Open_Client_Hello([]*Key{k},outer_hello)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got,want){
+			t.Fatal(kem.Scheme.Name()+" inner hello did not round trip")
+		}
+		//the second hello after a HelloRetryRequest
+		retry,err:=client.Seal_Client_Hello(inner,outer)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if got,err=server.Open_Client_Hello(retry);err!=nil||!bytes.Equal(got,want){
+			t.Fatal(kem.Scheme.Name()+" retried inner hello did not round trip")
+		}
+		//names up to the maximum length pad to the same payload length
+		short:=hello(session_id,server_name("a.example"),extension{Ext_ECH,[]byte{ech_inner}})
+		long:=hello(session_id,server_name("a-much-longer-name.example"),extension{Ext_ECH,[]byte{ech_inner}})
+		short_hello,_,_:=config.Seal_Client_Hello(short,outer)
+		long_hello,_,_:=config.Seal_Client_Hello(long,outer)
+		if len(short_hello)!=len(long_hello){
+			t.Fatal("inner server name length shows through the payload")
+		}
+	}
+}
+
+func Test_Rejected(t *testing.T){
+	k,_:=Generate_Key(1,"public.example",kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	other,_:=Generate_Key(2,"public.example",kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	same_id,_:=Generate_Key(1,"public.example",kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	inner:=hello(nil,server_name("secret.example"),extension{Ext_ECH,[]byte{ech_inner}})
+	outer:=func(ech []byte)([]byte,error){
+		return hello(nil,server_name("public.example"),extension{Ext_ECH,ech}),nil
+	}
+	outer_hello,_,err:=k.Config.Seal_Client_Hello(inner,outer)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,err=Open_Client_Hello([]*Key{same_id,k},outer_hello);err!=nil{
+		t.Fatal("trial decryption over keys sharing a config id failed")
+	}
+	if _,_,err=Open_Client_Hello([]*Key{other,same_id},outer_hello);err!=Err_Decrypt{
+		t.Fatal("hello for another config was opened")
+	}
+	tampered:=append([]byte(nil),outer_hello...)
+	tampered[len(tampered)-1]^=1
+	if _,_,err=Open_Client_Hello([]*Key{k},tampered);err!=Err_Decrypt{
+		t.Fatal("tampered payload was opened")
+	}
+	tampered=append([]byte(nil),outer_hello...)
+	tampered[10]^=1//the outer random is bound through the aad
+	if _,_,err=Open_Client_Hello([]*Key{k},tampered);err!=Err_Decrypt{
+		t.Fatal("tampered outer hello was opened")
+	}
+	if _,_,err=Open_Client_Hello([]*Key{k},hello(nil,server_name("public.example")));err!=Err_No_ECH{
+		t.Fatal("hello without ECH was not reported")
+	}
+	if _,_,err=Open_Client_Hello([]*Key{k},outer_hello[:len(outer_hello)-1]);err!=Err_Client_Hello{
+		t.Fatal("truncated hello was read")
+	}
+	if _,_,err=k.Config.Seal_Client_Hello(hello(nil,server_name("secret.example")),outer);err!=Err_Client_Hello{
+		t.Fatal("inner hello without the inner extension was sealed")
+	}
+}
@@ -0,0 +1,253 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains HPKE (RFC 9180) in base mode with kyber KEMs, HKDF-SHA256 and AES-GCM or ChaCha20-Poly1305
+the KEM is used as a black box, so any kyber_kem.Scheme works once it has a KEM id:
+
+	X25519Kyber768Draft00 (0x0030) the registered hybrid of DHKEM(X25519,HKDF-SHA256) and round 3 kyber_768 from draft-westerbaan-cfrg-hpke-xyber768d00
+	kyber_512, kyber_768, kyber_1024 (0xff40-0xff42) are not registered, the ML-KEM ids 0x0040-0x0042 belong to FIPS 203 which round 3 kyber is not
+*/
+package kyber_hpke
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const(
+	KDF_HKDF_SHA256 uint16=0x0001
+	AEAD_AES_128_GCM uint16=0x0001
+	AEAD_AES_256_GCM uint16=0x0002
+	AEAD_ChaCha20_Poly1305 uint16=0x0003
+	mode_base byte=0
+	N_h=32
+	N_n=12
+)
+
+var(
+	Err_Suite=errors.New("kyber_hpke: unsupported cipher suite")
+	Err_Open=errors.New("kyber_hpke: message authentication failed")
+	Err_Key=errors.New("kyber_hpke: key does not belong to the suite's KEM")
+	Err_Sequence=errors.New("kyber_hpke: message limit reached")
+)
+
+type KEM struct{
+	ID uint16
+	Scheme kyber_kem.Scheme
+}
+
+var(
+	KEM_X25519_Kyber768_Draft00=&KEM{0x0030,X25519_Kyber768_Draft00}
+	KEM_Kyber512=&KEM{0xff40,kyber_kem.Kyber_512}
+	KEM_Kyber768=&KEM{0xff41,kyber_kem.Kyber_768}
+	KEM_Kyber1024=&KEM{0xff42,kyber_kem.Kyber_1024}
+)
+
+var KEMs=[]*KEM{KEM_X25519_Kyber768_Draft00,KEM_Kyber512,KEM_Kyber768,KEM_Kyber1024}
+
+func KEM_by_ID(id uint16)(*KEM,error){
+	for _,k:=range KEMs{
+		if k.ID==id{
+			return k,nil
+		}
+	}
+	return nil,Err_Suite
+}
+
+type Suite struct{
+	KEM *KEM
+	KDF uint16
+	AEAD uint16
+}
+
+//key length of the AEAD, 0 for an unknown one
+func (s *Suite)N_k()int{
+	switch s.AEAD{
+	case AEAD_AES_128_GCM:
+		return 16
+	case AEAD_AES_256_GCM,AEAD_ChaCha20_Poly1305:
+		return 32
+	}
+	return 0
+}
+
+func (s *Suite)check()error{
+	if s.KEM==nil||s.KDF!=KDF_HKDF_SHA256||s.N_k()==0{
+		return Err_Suite
+	}
+	return nil
+}
+
+func (s *Suite)id()[]byte{
+	out:=[]byte("HPKE")
+	for _,x:=range []uint16{s.KEM.ID,s.KDF,s.AEAD}{
+		out=binary.BigEndian.AppendUint16(out,x)
+	}
+	return out
+}
+
+func (s *Suite)New_AEAD(key []byte)(cipher.AEAD,error){
+	if s.AEAD==AEAD_ChaCha20_Poly1305{
+		return chacha20poly1305.New(key)
+	}
+	block,err:=aes.NewCipher(key)
+	if err!=nil{
+		return nil,err
+	}
+	return cipher.NewGCM(block)
+}
+
+func labeled_extract(suite_id,salt []byte,label string,ikm []byte)[]byte{
+	labeled:=append(append(append([]byte("HPKE-v1"),suite_id...),label...),ikm...)
+	return hkdf.Extract(sha256.New,labeled,salt)
+}
+
+func labeled_expand(suite_id,prk []byte,label string,info []byte,length int)([]byte,error){
+	labeled:=binary.BigEndian.AppendUint16(nil,uint16(length))
+	labeled=append(append(append(append(labeled,"HPKE-v1"...),suite_id...),label...),info...)
+	out:=make([]byte,length)
+	if _,err:=io.ReadFull(hkdf.Expand(sha256.New,prk,labeled),out);err!=nil{
+		return nil,err
+	}
+	return out,nil
+}
+
+type Context struct{
+	suite *Suite
+	aead cipher.AEAD
+	base_nonce []byte
+	exporter_secret []byte
+	seq uint64
+}
+
+func (s *Suite)key_schedule(shared_secret,info []byte)(*Context,error){
+	suite_id:=s.id()
+	context:=[]byte{mode_base}
+	context=append(context,labeled_extract(suite_id,nil,"psk_id_hash",nil)...)
+	context=append(context,labeled_extract(suite_id,nil,"info_hash",info)...)
+	secret:=labeled_extract(suite_id,shared_secret,"secret",nil)
+	key,err:=labeled_expand(suite_id,secret,"key",context,s.N_k())
+	if err!=nil{
+		return nil,err
+	}
+	c:=&Context{suite:s}
+	if c.base_nonce,err=labeled_expand(suite_id,secret,"base_nonce",context,N_n);err!=nil{
+		return nil,err
+	}
+	if c.exporter_secret,err=labeled_expand(suite_id,secret,"exp",context,N_h);err!=nil{
+		return nil,err
+	}
+	if c.aead,err=s.New_AEAD(key);err!=nil{
+		return nil,err
+	}
+	return c,nil
+}
+
+func (s *Suite)Setup_Sender(pk kyber_kem.Public_Key,info []byte)(enc []byte,c *Context,err error){
+	if err=s.check();err!=nil{
+		return
+	}
+	if pk.Scheme()!=s.KEM.Scheme{
+		return nil,nil,Err_Key
+	}
+	enc,shared_secret,err:=pk.Enc()
+	if err!=nil{
+		return
+	}
+	c,err=s.key_schedule(shared_secret,info)
+	return
+}
+
+func (s *Suite)Setup_Receiver(sk kyber_kem.Private_Key,enc,info []byte)(*Context,error){
+	if err:=s.check();err!=nil{
+		return nil,err
+	}
+	if sk.Scheme()!=s.KEM.Scheme{
+		return nil,Err_Key
+	}
+	if len(enc)!=s.KEM.Scheme.Ct_Len(){
+		return nil,Err_Open
+	}
+	shared_secret,err:=sk.Dec(enc)
+	if err!=nil{
+		return nil,err
+	}
+	return s.key_schedule(shared_secret,info)
+}
+
+func (c *Context)nonce()([]byte,error){
+	if c.seq==^uint64(0){
+		return nil,Err_Sequence
+	}
+	nonce:=append([]byte(nil),c.base_nonce...)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:],c.seq)
+	for i:=range seq{
+		nonce[N_n-8+i]^=seq[i]
+	}
+	return nonce,nil
+}
+
+func (c *Context)Seal(aad,plaintext []byte)([]byte,error){
+	nonce,err:=c.nonce()
+	if err!=nil{
+		return nil,err
+	}
+	c.seq++
+	return c.aead.Seal(nil,nonce,plaintext,aad),nil
+}
+
+func (c *Context)Open(aad,ciphertext []byte)([]byte,error){
+	nonce,err:=c.nonce()
+	if err!=nil{
+		return nil,err
+	}
+	plaintext,err:=c.aead.Open(nil,nonce,ciphertext,aad)
+	if err!=nil{
+		return nil,Err_Open
+	}
+	c.seq++
+	return plaintext,nil
+}
+
+func (c *Context)Export(exporter_context []byte,length int)([]byte,error){
+	return labeled_expand(c.suite.id(),c.exporter_secret,"sec",exporter_context,length)
+}
+
+//single shot encryption, enc||ciphertext
+func (s *Suite)Seal(pk kyber_kem.Public_Key,info,aad,plaintext []byte)([]byte,error){
+	enc,c,err:=s.Setup_Sender(pk,info)
+	if err!=nil{
+		return nil,err
+	}
+	ct,err:=c.Seal(aad,plaintext)
+	if err!=nil{
+		return nil,err
+	}
+	return append(enc,ct...),nil
+}
+
+func (s *Suite)Open(sk kyber_kem.Private_Key,info,aad,sealed []byte)([]byte,error){
+	if err:=s.check();err!=nil{
+		return nil,err
+	}
+	n:=s.KEM.Scheme.Ct_Len()
+	if len(sealed)<n{
+		return nil,Err_Open
+	}
+	c,err:=s.Setup_Receiver(sk,sealed[:n],info)
+	if err!=nil{
+		return nil,err
+	}
+	return c.Open(aad,sealed[n:])
+}
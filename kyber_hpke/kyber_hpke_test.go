@@ -0,0 +1,122 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on HPKE with the kyber KEMs
+*/
+package kyber_hpke
+
+import(
+	"crypto/ecdh"
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string)[]byte{
+	out,err:=hex.DecodeString(s)
+	if err!=nil{
+		panic(err)
+	}
+	return out
+}
+
+//RFC 9180 appendix A.1.1, DHKEM(X25519,HKDF-SHA256) with HKDF-SHA256 and AES-128-GCM in base mode
+func Test_RFC_9180_Vector(t *testing.T){
+	skE,_:=ecdh.X25519().NewPrivateKey(unhex("52c4a758a802cd8b936eceea314432798d5baf2d7e9235dc084ab1b9cfa2f736"))
+	skR,_:=ecdh.X25519().NewPrivateKey(unhex("4612c550263fc8ad58375df3f557aac531d26850903e55a9f23f21d8534e8ac8"))
+	enc,shared_secret,err:=dhkem_encap(skE,skR.PublicKey())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc,unhex("37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431")){
+		t.Fatal("enc does not match the test vector")
+	}
+	if !bytes.Equal(shared_secret,unhex("fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc")){
+		t.Fatal("DHKEM shared secret does not match the test vector")
+	}
+	decapped,err:=dhkem_decap(skR,enc)
+	if err!=nil||!bytes.Equal(decapped,shared_secret){
+		t.Fatal("DHKEM decapsulation does not match")
+	}
+	suite:=&Suite{&KEM{dhkem_x25519_id,nil},KDF_HKDF_SHA256,AEAD_AES_128_GCM}
+	c,err:=suite.key_schedule(shared_secret,unhex("4f6465206f6e2061204772656369616e2055726e"))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c.base_nonce,unhex("56d890e5accaaf011cff4b7d"))||!bytes.Equal(c.exporter_secret,unhex("45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8")){
+		t.Fatal("key schedule does not match the test vector")
+	}
+	ct,_:=c.Seal(unhex("436f756e742d30"),unhex("4265617574792069732074727574682c20747275746820626561757479"))
+	if !bytes.Equal(ct,unhex("f938558b5d72f1a23810b4be2ab4f84331acc02fc97babc53a52ae8218a355a96d8770ac83d07bea87e13c512a")){
+		t.Fatal("sequence 0 ciphertext does not match the test vector")
+	}
+}
+
+func Test_Suites(t *testing.T){
+	for _,kem:=range KEMs{
+		for _,aead:=range []uint16{AEAD_AES_128_GCM,AEAD_AES_256_GCM,AEAD_ChaCha20_Poly1305}{
+			suite:=&Suite{kem,KDF_HKDF_SHA256,aead}
+			sk,err:=kem.Scheme.Keygen()
+			if err!=nil{
+				t.Fatal(err)
+			}
+			pk,err:=kem.Scheme.Bytes_to_Pk(sk.Public().To_Bytes())
+			if err!=nil{
+				t.Fatal(err)
+			}
+			enc,sender,err:=suite.Setup_Sender(pk,[]byte("info"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			receiver,err:=suite.Setup_Receiver(sk,enc,[]byte("info"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			for i:=0;i<3;i++{
+				ct,_:=sender.Seal([]byte("aad"),[]byte("message"))
+				pt,err:=receiver.Open([]byte("aad"),ct)
+				if err!=nil||string(pt)!="message"{
+					t.Fatal(kem.Scheme.Name()+" message did not open")
+				}
+			}
+			a,_:=sender.Export([]byte("exporter"),32)
+			b,_:=receiver.Export([]byte("exporter"),32)
+			if !bytes.Equal(a,b){
+				t.Fatal("exported secrets do not match")
+			}
+			sealed,_:=suite.Seal(pk,[]byte("info"),nil,[]byte("single shot"))
+			if pt,err:=suite.Open(sk,[]byte("other info"),nil,sealed);err==nil||pt!=nil{
+				t.Fatal("message opened under another info")
+			}
+			if pt,err:=suite.Open(sk,[]byte("info"),nil,sealed);err!=nil||string(pt)!="single shot"{
+				t.Fatal("single shot message did not open")
+			}
+		}
+	}
+	other,_:=KEM_Kyber512.Scheme.Keygen()
+	if _,_,err:=(&Suite{KEM_Kyber768,KDF_HKDF_SHA256,AEAD_AES_128_GCM}).Setup_Sender(other.Public(),nil);err!=Err_Key{
+		t.Fatal("key for another KEM was accepted")
+	}
+	if _,_,err:=(&Suite{KEM_Kyber512,KDF_HKDF_SHA256,9}).Setup_Sender(other.Public(),nil);err!=Err_Suite{
+		t.Fatal("unknown AEAD was accepted")
+	}
+}
+
+func Test_Xyber_Seed(t *testing.T){
+	a,_:=X25519_Kyber768_Draft00.Seed_to_Keys([32]byte{1})
+	b,_:=X25519_Kyber768_Draft00.Seed_to_Keys([32]byte{1})
+	if !bytes.Equal(a.Public().To_Bytes(),b.Public().To_Bytes())||len(a.To_Bytes())!=X25519_Kyber768_Draft00.Sk_Len(){
+		t.Fatal("seeded keys are not deterministic")
+	}
+	restored,err:=X25519_Kyber768_Draft00.Bytes_to_Sk(a.To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,K,_:=a.Public().Enc()
+	if K2,err:=restored.Dec(c);err!=nil||!bytes.Equal(K,K2)||len(K)!=64{
+		t.Fatal("restored key does not decapsulate")
+	}
+}
@@ -0,0 +1,201 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the X25519Kyber768Draft00 KEM, DHKEM(X25519,HKDF-SHA256) from RFC 9180 run next to round 3 kyber_768
+	pk=X25519 pk(32)||kyber pk(1184)  sk=X25519 sk(32)||kyber sk(2400)  ct=X25519 enc(32)||kyber ct(1088)  K=DHKEM secret(32)||kyber secret(32)
+Seed_to_Keys expands the seed with SHAKE256 and does not follow the DeriveKeyPair of the draft
+*/
+package kyber_hpke
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/sha3"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+)
+
+const(
+	dhkem_x25519_id=0x0020
+	x25519_len=32
+)
+
+type xyber_scheme struct{}
+
+type xyber_public_key struct{
+	dh *ecdh.PublicKey
+	pq kyber_kem.Public_Key
+}
+
+type xyber_private_key struct{
+	dh *ecdh.PrivateKey
+	pq kyber_kem.Private_Key
+}
+
+var X25519_Kyber768_Draft00 kyber_kem.Scheme=&xyber_scheme{}
+
+var pq=kyber_kem.Kyber_768
+
+func (*xyber_scheme)Name()string{
+	return "X25519Kyber768Draft00"
+}
+
+func (*xyber_scheme)Pk_Len()int{
+	return x25519_len+pq.Pk_Len()
+}
+
+func (*xyber_scheme)Sk_Len()int{
+	return x25519_len+pq.Sk_Len()
+}
+
+func (*xyber_scheme)Ct_Len()int{
+	return x25519_len+pq.Ct_Len()
+}
+
+func (*xyber_scheme)Ss_Len()int{
+	return 2*kyber_kem.Shared_key_len
+}
+
+func (*xyber_scheme)Keygen()(kyber_kem.Private_Key,error){
+	dh,err:=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	sk,err:=pq.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	return &xyber_private_key{dh,sk},nil
+}
+
+func (*xyber_scheme)Seed_to_Keys(seed [32]byte)(kyber_kem.Private_Key,error){
+	expanded:=make([]byte,x25519_len+32)
+	h:=sha3.NewShake256()
+	h.Write([]byte("X25519Kyber768Draft00 seed"))
+	h.Write(seed[:])
+	h.Read(expanded)
+	dh,err:=ecdh.X25519().NewPrivateKey(expanded[:x25519_len])
+	if err!=nil{
+		return nil,err
+	}
+	sk,err:=pq.Seed_to_Keys([32]byte(expanded[x25519_len:]))
+	if err!=nil{
+		return nil,err
+	}
+	return &xyber_private_key{dh,sk},nil
+}
+
+func (s *xyber_scheme)Bytes_to_Pk(data []byte)(kyber_kem.Public_Key,error){
+	if len(data)!=s.Pk_Len(){
+		return nil,errors.New("kyber_hpke: X25519Kyber768Draft00 public key has the wrong length")
+	}
+	dh,err:=ecdh.X25519().NewPublicKey(data[:x25519_len])
+	if err!=nil{
+		return nil,err
+	}
+	pk,err:=pq.Bytes_to_Pk(data[x25519_len:])
+	if err!=nil{
+		return nil,err
+	}
+	return &xyber_public_key{dh,pk},nil
+}
+
+func (s *xyber_scheme)Bytes_to_Sk(data []byte)(kyber_kem.Private_Key,error){
+	if len(data)!=s.Sk_Len(){
+		return nil,errors.New("kyber_hpke: X25519Kyber768Draft00 private key has the wrong length")
+	}
+	dh,err:=ecdh.X25519().NewPrivateKey(data[:x25519_len])
+	if err!=nil{
+		return nil,err
+	}
+	sk,err:=pq.Bytes_to_Sk(data[x25519_len:])
+	if err!=nil{
+		return nil,err
+	}
+	return &xyber_private_key{dh,sk},nil
+}
+
+func (*xyber_public_key)Scheme()kyber_kem.Scheme{
+	return X25519_Kyber768_Draft00
+}
+
+func (pk *xyber_public_key)To_Bytes()[]byte{
+	return append(pk.dh.Bytes(),pk.pq.To_Bytes()...)
+}
+
+func (pk *xyber_public_key)Enc()(c,K []byte,err error){
+	skE,err:=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return
+	}
+	enc,K_dh,err:=dhkem_encap(skE,pk.dh)
+	if err!=nil{
+		return
+	}
+	c_pq,K_pq,err:=pk.pq.Enc()
+	if err!=nil{
+		return
+	}
+	return append(enc,c_pq...),append(K_dh,K_pq...),nil
+}
+
+func (*xyber_private_key)Scheme()kyber_kem.Scheme{
+	return X25519_Kyber768_Draft00
+}
+
+func (sk *xyber_private_key)To_Bytes()[]byte{
+	return append(sk.dh.Bytes(),sk.pq.To_Bytes()...)
+}
+
+func (sk *xyber_private_key)Public()kyber_kem.Public_Key{
+	return &xyber_public_key{sk.dh.PublicKey(),sk.pq.Public()}
+}
+
+func (sk *xyber_private_key)Dec(c []byte)(K []byte,err error){
+	if len(c)!=X25519_Kyber768_Draft00.Ct_Len(){
+		return nil,errors.New("kyber_hpke: X25519Kyber768Draft00 ciphertext has the wrong length")
+	}
+	K_dh,err:=dhkem_decap(sk.dh,c[:x25519_len])
+	if err!=nil{
+		return
+	}
+	K_pq,err:=sk.pq.Dec(c[x25519_len:])
+	if err!=nil{
+		return
+	}
+	return append(K_dh,K_pq...),nil
+}
+
+func dhkem_suite_id()[]byte{
+	return []byte{'K','E','M',dhkem_x25519_id>>8,dhkem_x25519_id&0xff}
+}
+
+func extract_and_expand(dh,kem_context []byte)([]byte,error){
+	prk:=labeled_extract(dhkem_suite_id(),nil,"eae_prk",dh)
+	return labeled_expand(dhkem_suite_id(),prk,"shared_secret",kem_context,N_h)
+}
+
+func dhkem_encap(skE *ecdh.PrivateKey,pkR *ecdh.PublicKey)(enc,K []byte,err error){
+	dh,err:=skE.ECDH(pkR)
+	if err!=nil{
+		return
+	}
+	enc=skE.PublicKey().Bytes()
+	K,err=extract_and_expand(dh,append(append([]byte(nil),enc...),pkR.Bytes()...))
+	return
+}
+
+func dhkem_decap(skR *ecdh.PrivateKey,enc []byte)([]byte,error){
+	pkE,err:=ecdh.X25519().NewPublicKey(enc)
+	if err!=nil{
+		return nil,err
+	}
+	dh,err:=skR.ECDH(pkE)
+	if err!=nil{
+		return nil,err
+	}
+	return extract_and_expand(dh,append(append([]byte(nil),enc...),skR.PublicKey().Bytes()...))
+}
@@ -0,0 +1,228 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a hybrid KEM that pairs any crypto/ecdh curve with any kyber parameter set
+The shared keys are combined following NIST SP 800-227: K=KDF(K_kyber||K_ecdh,c_kyber||c_ecdh||pk_kyber||pk_ecdh,name)
+*/
+package kyber_hybrid
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/sha3"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+)
+
+type scheme struct{
+	name string
+	curve ecdh.Curve
+	pq kyber_kem.Scheme
+	kdf kyber_kdf.KDF
+	ec_pk_len,ec_sk_len int
+}
+
+type public_key struct{
+	scheme *scheme
+	pq kyber_kem.Public_Key
+	ec *ecdh.PublicKey
+}
+
+type private_key struct{
+	scheme *scheme
+	pq kyber_kem.Private_Key
+	ec *ecdh.PrivateKey
+}
+
+func New(curve ecdh.Curve,pq kyber_kem.Scheme,kdf kyber_kdf.KDF)(kyber_kem.Scheme,error){
+	if curve==nil||pq==nil||kdf==nil{
+		return nil,errors.New("hybrid kem needs a curve, a kyber scheme and a kdf")
+	}
+	ec,err:=curve.GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	s:=&scheme{curve:curve,pq:pq,kdf:kdf}
+	s.ec_pk_len=len(ec.PublicKey().Bytes())
+	s.ec_sk_len=len(ec.Bytes())
+	s.name=curve_name(curve)+"_"+pq.Name()+"_"+kdf.Name()
+	return s,nil
+}
+
+func curve_name(curve ecdh.Curve)string{
+	switch curve{
+	case ecdh.X25519():
+		return "X25519"
+	case ecdh.P256():
+		return "P256"
+	case ecdh.P384():
+		return "P384"
+	case ecdh.P521():
+		return "P521"
+	}
+	return "ECDH"
+}
+
+func (s *scheme)Name()string{
+	return s.name
+}
+
+func (s *scheme)Pk_Len()int{
+	return s.pq.Pk_Len()+s.ec_pk_len
+}
+
+func (s *scheme)Sk_Len()int{
+	return s.pq.Sk_Len()+s.ec_sk_len
+}
+
+func (s *scheme)Ct_Len()int{
+	return s.pq.Ct_Len()+s.ec_pk_len
+}
+
+func (s *scheme)Ss_Len()int{
+	return kyber_kem.Shared_key_len
+}
+
+func (s *scheme)Keygen()(kyber_kem.Private_Key,error){
+	pq,err:=s.pq.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	ec,err:=s.curve.GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	return &private_key{s,pq,ec},nil
+}
+
+//the kyber seed and the ecdh scalar are both expanded from the seed with SHAKE256
+func (s *scheme)Seed_to_Keys(seed [32]byte)(kyber_kem.Private_Key,error){
+	var pq_seed [32]byte
+	if seed==[32]byte{}{
+		return nil,errors.New("keys can not be recovered, nil seed")
+	}
+	shake:=sha3.NewShake256()
+	shake.Write([]byte(s.name))
+	shake.Write(seed[:])
+	shake.Read(pq_seed[:])
+	pq,err:=s.pq.Seed_to_Keys(pq_seed)
+	if err!=nil{
+		return nil,err
+	}
+	ec_bytes:=make([]byte,s.ec_sk_len)
+	for i:=0;i<256;i++{//rejection sample a scalar for the nist curves
+		shake.Read(ec_bytes)
+		if s.curve==ecdh.P521(){//the order has 521 bits, so only the low bit of the first of 66 bytes can be set
+			ec_bytes[0]&=1
+		}
+		ec,err:=s.curve.NewPrivateKey(ec_bytes)
+		if err==nil{
+			return &private_key{s,pq,ec},nil
+		}
+	}
+	return nil,errors.New("could not derive an ecdh key from the seed")
+}
+
+func (s *scheme)Bytes_to_Pk(data []byte)(kyber_kem.Public_Key,error){
+	if len(data)!=s.Pk_Len(){
+		return nil,errors.New("public key has the wrong length for "+s.name)
+	}
+	pq,err:=s.pq.Bytes_to_Pk(data[:s.pq.Pk_Len()])
+	if err!=nil{
+		return nil,err
+	}
+	ec,err:=s.curve.NewPublicKey(data[s.pq.Pk_Len():])
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pq,ec},nil
+}
+
+func (s *scheme)Bytes_to_Sk(data []byte)(kyber_kem.Private_Key,error){
+	if len(data)!=s.Sk_Len(){
+		return nil,errors.New("private key has the wrong length for "+s.name)
+	}
+	pq,err:=s.pq.Bytes_to_Sk(data[:s.pq.Sk_Len()])
+	if err!=nil{
+		return nil,err
+	}
+	ec,err:=s.curve.NewPrivateKey(data[s.pq.Sk_Len():])
+	if err!=nil{
+		return nil,err
+	}
+	return &private_key{s,pq,ec},nil
+}
+
+func (pk *public_key)Scheme()kyber_kem.Scheme{
+	return pk.scheme
+}
+
+func (pk *public_key)To_Bytes()[]byte{
+	return append(pk.pq.To_Bytes(),pk.ec.Bytes()...)
+}
+
+func (pk *public_key)Enc()(c,K []byte,err error){
+	c_pq,K_pq,err:=pk.pq.Enc()
+	if err!=nil{
+		return
+	}
+	e,err:=pk.scheme.curve.GenerateKey(rand.Reader)
+	if err!=nil{
+		return
+	}
+	K_ec,err:=e.ECDH(pk.ec)
+	if err!=nil{
+		return
+	}
+	c=append(c_pq,e.PublicKey().Bytes()...)
+	K,err=pk.scheme.combine(K_pq,K_ec,c,pk.To_Bytes())
+	return
+}
+
+func (sk *private_key)Scheme()kyber_kem.Scheme{
+	return sk.scheme
+}
+
+func (sk *private_key)To_Bytes()[]byte{
+	return append(sk.pq.To_Bytes(),sk.ec.Bytes()...)
+}
+
+func (sk *private_key)Public()kyber_kem.Public_Key{
+	return &public_key{sk.scheme,sk.pq.Public(),sk.ec.PublicKey()}
+}
+
+func (sk *private_key)Dec(c []byte)(K []byte,err error){
+	s:=sk.scheme
+	if len(c)!=s.Ct_Len(){
+		err=errors.New("ciphertext has the wrong length for "+s.name)
+		return
+	}
+	K_pq,err:=sk.pq.Dec(c[:s.pq.Ct_Len()])
+	if err!=nil{
+		return
+	}
+	e,err:=s.curve.NewPublicKey(c[s.pq.Ct_Len():])
+	if err!=nil{
+		return
+	}
+	K_ec,err:=sk.ec.ECDH(e)
+	if err!=nil{
+		return
+	}
+	return s.combine(K_pq,K_ec,c,sk.Public().To_Bytes())
+}
+
+//both ciphertexts and both public keys are bound into the kdf context
+func (s *scheme)combine(K_pq,K_ec,c,pk []byte)([]byte,error){
+	secret:=make([]byte,0,len(K_pq)+len(K_ec))
+	secret=append(secret,K_pq...)
+	secret=append(secret,K_ec...)
+	context:=make([]byte,0,len(c)+len(pk))
+	context=append(context,c...)
+	context=append(context,pk...)
+	return s.kdf.Derive(secret,context,s.name,kyber_kem.Shared_key_len)
+}
@@ -0,0 +1,124 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the hybrid KEM combiner
+*/
+package kyber_hybrid
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/ecdh"
+	"bytes"
+	"testing"
+)
+
+func Test_Hybrid(t *testing.T){
+	for _,curve:=range []ecdh.Curve{ecdh.X25519(),ecdh.P256(),ecdh.P384()}{
+		for _,pq:=range []kyber_kem.Scheme{kyber_kem.Kyber_512,kyber_kem.Kyber_768,kyber_kem.Kyber_1024}{
+			for _,kdf:=range []kyber_kdf.KDF{kyber_kdf.HKDF_SHA256,kyber_kdf.SHA3_256,kyber_kdf.KMAC256}{
+				s,err:=New(curve,pq,kdf)
+				if err!=nil{
+					t.Fatal(err)
+				}
+				test_scheme(t,s)
+			}
+		}
+	}
+}
+
+func test_scheme(t *testing.T,s kyber_kem.Scheme){
+	sk,err:=s.Keygen()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(sk.To_Bytes())!=s.Sk_Len()||len(sk.Public().To_Bytes())!=s.Pk_Len(){
+		t.Fatal(s.Name()+" key lengths do not match the scheme")
+	}
+	pk,err:=s.Bytes_to_Pk(sk.Public().To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,K,err:=pk.Enc()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(c)!=s.Ct_Len(){
+		t.Fatal(s.Name()+" ciphertext length does not match the scheme")
+	}
+	loaded,err:=s.Bytes_to_Sk(sk.To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	K_dec,err:=loaded.Dec(c)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(K,K_dec){
+		t.Fatal(s.Name()+" shared keys do not match")
+	}
+	c[len(c)-s.Ct_Len()/2]^=1//flip a bit in the kyber ciphertext
+	K_dec,err=sk.Dec(c)
+	if err==nil&&bytes.Equal(K,K_dec){
+		t.Fatal(s.Name()+" tampered ciphertext decapsulated to the same key")
+	}
+}
+
+func Test_Seed_to_Keys(t *testing.T){
+	s,err:=New(ecdh.P256(),kyber_kem.Kyber_768,kyber_kdf.KMAC256)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	a,err:=s.Seed_to_Keys([32]byte{7})
+	if err!=nil{
+		t.Fatal(err)
+	}
+	b,err:=s.Seed_to_Keys([32]byte{7})
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Public().To_Bytes(),b.Public().To_Bytes()){
+		t.Fatal("seed did not reproduce the hybrid public key")
+	}
+	//a raw 66 byte string is a P-521 scalar only 1 time in 128, every seed has to give a key
+	for _,curve:=range []ecdh.Curve{ecdh.P384(),ecdh.P521()}{
+		s,_=New(curve,kyber_kem.Kyber_512,kyber_kdf.KMAC256)
+		for i:=1;i<=200;i++{
+			a,err=s.Seed_to_Keys([32]byte{byte(i),byte(i>>8),1})
+			if err!=nil{
+				t.Fatalf("%s seed %d: %v",s.Name(),i,err)
+			}
+			b,_=s.Seed_to_Keys([32]byte{byte(i),byte(i>>8),1})
+			if !bytes.Equal(a.Public().To_Bytes(),b.Public().To_Bytes()){
+				t.Fatal(s.Name()+" seed did not reproduce the key")
+			}
+		}
+	}
+}
+
+func Test_Binding(t *testing.T){//the same kyber and ecdh keys under different kdfs must not agree
+	a,_:=New(ecdh.X25519(),kyber_kem.Kyber_768,kyber_kdf.HKDF_SHA256)
+	b,_:=New(ecdh.X25519(),kyber_kem.Kyber_768,kyber_kdf.KMAC256)
+	sk,err:=a.Keygen()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,K,err:=sk.Public().Enc()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	other,err:=b.Bytes_to_Sk(sk.To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	K_dec,err:=other.Dec(c)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if bytes.Equal(K,K_dec){
+		t.Fatal("shared key is not bound to the combiner")
+	}
+}
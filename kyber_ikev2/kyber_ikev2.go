@@ -0,0 +1,250 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the IKEv2 (RFC 7296) messages and payloads that the additional key exchanges of RFC 9370 need
+	header:     SPIi(8)||SPIr(8)||next payload(1)||version(1)||exchange type(1)||flags(1)||message id(4)||length(4)
+	payload:    next payload(1)||critical(1)||length(2)||body
+	KE:         key exchange method(2)||reserved(2)||key exchange data
+	transform:  last(1)||reserved(1)||length(2)||type(1)||reserved(1)||id(2)||[0x800e||key length(2)]
+the kyber transform ids are in the private use range, ML-KEM-512/768/1024 (35-37) belong to FIPS 203 which round 3 kyber is not
+*/
+package kyber_ikev2
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	Exchange_IKE_SA_INIT byte=34
+	Exchange_IKE_INTERMEDIATE byte=43
+	Payload_None byte=0
+	Payload_KE byte=34
+	Payload_Nonce byte=40
+	Payload_Notify byte=41
+	Payload_SK byte=46
+	Flag_Initiator byte=0x08
+	Flag_Response byte=0x20
+	version byte=0x20
+	header_len=28
+	payload_header_len=4
+)
+
+//transform types, ADDKE1 to ADDKE7 are 6 to 12
+const(
+	Transform_Type_ENCR byte=1
+	Transform_Type_PRF byte=2
+	Transform_Type_KE byte=4
+	Transform_Type_ADDKE1 byte=6
+	Transform_Type_ADDKE7 byte=12
+)
+
+const(
+	ENCR_AES_GCM_16 uint16=20
+	PRF_HMAC_SHA2_256 uint16=5
+	KE_None uint16=0
+	KE_Curve25519 uint16=31
+	KE_Kyber512 uint16=1035
+	KE_Kyber768 uint16=1036
+	KE_Kyber1024 uint16=1037
+	attribute_key_length uint16=0x800e
+)
+
+var(
+	Err_Format=errors.New("kyber_ikev2: malformed message")
+	Err_Integrity=errors.New("kyber_ikev2: encrypted payload failed authentication")
+	Err_KE=errors.New("kyber_ikev2: unexpected key exchange method")
+	Err_State=errors.New("kyber_ikev2: message out of order")
+)
+
+var ke_schemes=map[uint16]kyber_kem.Scheme{
+	KE_Kyber512:kyber_kem.Kyber_512,
+	KE_Kyber768:kyber_kem.Kyber_768,
+	KE_Kyber1024:kyber_kem.Kyber_1024,
+}
+
+func Scheme_by_KE(id uint16)(kyber_kem.Scheme,error){
+	s,ok:=ke_schemes[id]
+	if !ok{
+		return nil,Err_KE
+	}
+	return s,nil
+}
+
+type Header struct{
+	SPI_i uint64
+	SPI_r uint64
+	Next_Payload byte
+	Exchange byte
+	Flags byte
+	Message_ID uint32
+	Length uint32
+}
+
+func (h *Header)To_Bytes()[]byte{
+	out:=binary.BigEndian.AppendUint64(nil,h.SPI_i)
+	out=binary.BigEndian.AppendUint64(out,h.SPI_r)
+	out=append(out,h.Next_Payload,version,h.Exchange,h.Flags)
+	out=binary.BigEndian.AppendUint32(out,h.Message_ID)
+	return binary.BigEndian.AppendUint32(out,h.Length)
+}
+
+//the length has to match the message, the version has to be 2.0
+func Parse_Header(msg []byte)(*Header,error){
+	if len(msg)<header_len||msg[17]!=version{
+		return nil,Err_Format
+	}
+	h:=&Header{
+		SPI_i:binary.BigEndian.Uint64(msg),
+		SPI_r:binary.BigEndian.Uint64(msg[8:]),
+		Next_Payload:msg[16],
+		Exchange:msg[18],
+		Flags:msg[19],
+		Message_ID:binary.BigEndian.Uint32(msg[20:]),
+		Length:binary.BigEndian.Uint32(msg[24:]),
+	}
+	if h.Length!=uint32(len(msg)){
+		return nil,Err_Format
+	}
+	return h,nil
+}
+
+type Payload struct{
+	Type byte
+	Critical bool
+	Body []byte
+}
+
+func (p *Payload)header(next byte)[]byte{
+	out:=[]byte{next,0}
+	if p.Critical{
+		out[1]=0x80
+	}
+	return binary.BigEndian.AppendUint16(out,uint16(payload_header_len+len(p.Body)))
+}
+
+//the type of the first payload goes in whatever comes before the chain
+func Marshal_Payloads(payloads []Payload)(first byte,data []byte){
+	first=Payload_None
+	for i:=range payloads{
+		next:=Payload_None
+		if i+1<len(payloads){
+			next=payloads[i+1].Type
+		}
+		data=append(append(data,payloads[i].header(next)...),payloads[i].Body...)
+	}
+	if len(payloads)>0{
+		first=payloads[0].Type
+	}
+	return first,data
+}
+
+func Parse_Payloads(first byte,data []byte)([]Payload,error){
+	var payloads []Payload
+	next:=first
+	for next!=Payload_None{
+		if len(data)<payload_header_len{
+			return nil,Err_Format
+		}
+		n:=int(binary.BigEndian.Uint16(data[2:]))
+		if n<payload_header_len||n>len(data){
+			return nil,Err_Format
+		}
+		payloads=append(payloads,Payload{next,data[1]&0x80!=0,data[payload_header_len:n]})
+		next=data[0]
+		data=data[n:]
+	}
+	if len(data)!=0{
+		return nil,Err_Format
+	}
+	return payloads,nil
+}
+
+type KE_Payload struct{
+	Method uint16
+	Data []byte
+}
+
+func (ke *KE_Payload)Payload()Payload{
+	body:=binary.BigEndian.AppendUint16(nil,ke.Method)
+	return Payload{Type:Payload_KE,Body:append(append(body,0,0),ke.Data...)}
+}
+
+func Parse_KE(p Payload)(*KE_Payload,error){
+	if p.Type!=Payload_KE||len(p.Body)<4{
+		return nil,Err_Format
+	}
+	return &KE_Payload{binary.BigEndian.Uint16(p.Body),p.Body[4:]},nil
+}
+
+//a transform substructure, key length 0 leaves the attribute out
+type Transform struct{
+	Type byte
+	ID uint16
+	Key_Length uint16
+}
+
+func Marshal_Transforms(transforms []Transform)[]byte{
+	var out []byte
+	for i,t:=range transforms{
+		last:=byte(3)
+		if i==len(transforms)-1{
+			last=0
+		}
+		n:=8
+		if t.Key_Length!=0{
+			n+=4
+		}
+		out=binary.BigEndian.AppendUint16(append(out,last,0),uint16(n))
+		out=binary.BigEndian.AppendUint16(append(out,t.Type,0),t.ID)
+		if t.Key_Length!=0{
+			out=binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(out,attribute_key_length),t.Key_Length)
+		}
+	}
+	return out
+}
+
+func Parse_Transforms(data []byte)([]Transform,error){
+	var transforms []Transform
+	for len(data)>0{
+		if len(data)<8{
+			return nil,Err_Format
+		}
+		n:=int(binary.BigEndian.Uint16(data[2:]))
+		if n<8||n>len(data)||(data[0]==0)!=(n==len(data))||(data[0]!=0&&data[0]!=3){
+			return nil,Err_Format
+		}
+		t:=Transform{Type:data[4],ID:binary.BigEndian.Uint16(data[6:])}
+		switch n{
+		case 8:
+		case 12:
+			if binary.BigEndian.Uint16(data[8:])!=attribute_key_length{
+				return nil,Err_Format
+			}
+			t.Key_Length=binary.BigEndian.Uint16(data[10:])
+		default:
+			return nil,Err_Format
+		}
+		transforms=append(transforms,t)
+		data=data[n:]
+	}
+	return transforms,nil
+}
+
+//the additional key exchange methods of a chosen proposal in the order they run, types chosen as NONE are skipped
+func Additional_KE(transforms []Transform)[]uint16{
+	var methods []uint16
+	for ty:=Transform_Type_ADDKE1;ty<=Transform_Type_ADDKE7;ty++{
+		for _,t:=range transforms{
+			if t.Type==ty&&t.ID!=KE_None{
+				methods=append(methods,t.ID)
+				break
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,178 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the IKEv2 payloads, the key updates and initiator and responder running additional key exchanges
+*/
+package kyber_ikev2
+
+import(
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func Test_Payloads(t *testing.T){
+	h:=&Header{SPI_i:1,SPI_r:2,Next_Payload:Payload_KE,Exchange:Exchange_IKE_SA_INIT,Flags:Flag_Initiator,Message_ID:0}
+	ke:=&KE_Payload{KE_Kyber768,[]byte("public key")}
+	first,data:=Marshal_Payloads([]Payload{ke.Payload(),{Type:Payload_Nonce,Body:make([]byte,32)},{Type:Payload_Notify,Critical:true}})
+	h.Length=uint32(header_len+len(data))
+	msg:=append(h.To_Bytes(),data...)
+	got,err:=Parse_Header(msg)
+	if err!=nil||*got!=*h||first!=Payload_KE{
+		t.Fatal("header did not round trip")
+	}
+	payloads,err:=Parse_Payloads(got.Next_Payload,msg[header_len:])
+	if err!=nil||len(payloads)!=3||payloads[1].Type!=Payload_Nonce||len(payloads[1].Body)!=32||!payloads[2].Critical{
+		t.Fatal("payloads did not round trip")
+	}
+	if got,err:=Parse_KE(payloads[0]);err!=nil||got.Method!=KE_Kyber768||!bytes.Equal(got.Data,ke.Data){
+		t.Fatal("KE payload did not round trip")
+	}
+	for _,bad:=range [][]byte{msg[:header_len-1],msg[:len(msg)-1],append(msg,0)}{
+		if _,err=Parse_Header(bad);err==nil{
+			t.Fatal("malformed header was read")
+		}
+	}
+	if _,err=Parse_Payloads(Payload_KE,data[:len(data)-1]);err==nil{
+		t.Fatal("truncated payloads were read")
+	}
+	if _,err=Parse_KE(payloads[1]);err==nil{
+		t.Fatal("nonce was read as a KE payload")
+	}
+	transforms:=[]Transform{
+		{Transform_Type_ENCR,ENCR_AES_GCM_16,256},
+		{Transform_Type_PRF,PRF_HMAC_SHA2_256,0},
+		{Transform_Type_KE,KE_Curve25519,0},
+		{Transform_Type_ADDKE1+1,KE_Kyber1024,0},
+		{Transform_Type_ADDKE1,KE_Kyber768,0},
+		{Transform_Type_ADDKE1+2,KE_None,0},
+	}
+	data=Marshal_Transforms(transforms)
+	parsed,err:=Parse_Transforms(data)
+	if err!=nil||len(parsed)!=len(transforms){
+		t.Fatal("transforms did not round trip")
+	}
+	for i:=range parsed{
+		if parsed[i]!=transforms[i]{
+			t.Fatal("transforms did not round trip")
+		}
+	}
+	if m:=Additional_KE(parsed);len(m)!=2||m[0]!=KE_Kyber768||m[1]!=KE_Kyber1024{
+		t.Fatal("additional key exchanges are out of order")
+	}
+	if _,err=Parse_Transforms(data[:len(data)-4]);err==nil{
+		t.Fatal("transforms without a last one were read")
+	}
+}
+
+//IKE_SA_INIT with X25519 on both sides, leaving the SA keys to the additional key exchanges
+func sa_init(t *testing.T)(initiator,responder *SA){
+	a,_:=ecdh.X25519().GenerateKey(rand.Reader)
+	b,_:=ecdh.X25519().GenerateKey(rand.Reader)
+	ss_a,_:=a.ECDH(b.PublicKey())
+	ss_b,_:=b.ECDH(a.PublicKey())
+	n_i,n_r:=make([]byte,32),make([]byte,32)
+	rand.Read(n_i)
+	rand.Read(n_r)
+	return New_SA(0x1111,0x2222,n_i,n_r,ss_a),New_SA(0x1111,0x2222,n_i,n_r,ss_b)
+}
+
+func Test_Key_Update(t *testing.T){
+	sa,_:=sa_init(t)
+	old_d:=sa.Keys.SK_d
+	ss:=bytes.Repeat([]byte{7},32)
+	sa.Update(ss)
+	m:=hmac.New(sha256.New,old_d)
+	m.Write(append(append(append([]byte(nil),ss...),sa.N_i...),sa.N_r...))
+	skeyseed:=m.Sum(nil)
+	seed:=append(append([]byte(nil),sa.N_i...),sa.N_r...)
+	seed=binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(seed,sa.SPI_i),sa.SPI_r)
+	m=hmac.New(sha256.New,skeyseed)
+	m.Write(append(seed,1))
+	if !bytes.Equal(sa.Keys.SK_d,m.Sum(nil)){
+		t.Fatal("SK_d is not prf+(SKEYSEED(1),Ni||Nr||SPIi||SPIr)")
+	}
+	if len(sa.Keys.SK_ei)!=encr_key_len||len(sa.Keys.SK_pr)!=prf_len||bytes.Equal(sa.Keys.SK_ei,sa.Keys.SK_er){
+		t.Fatal("keys have the wrong shape")
+	}
+}
+
+func Test_Intermediate(t *testing.T){
+	sa_i,sa_r:=sa_init(t)
+	methods:=[]uint16{KE_Kyber768,KE_Kyber1024,KE_Kyber512}
+	initial:=sa_i.Keys.SK_d
+	initiator,err:=New_Initiator(sa_i,methods)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	responder,err:=New_Responder(sa_r,methods)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for !initiator.Done(){
+		req,err:=initiator.Request()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if _,err=initiator.Request();err!=Err_State{
+			t.Fatal("second request was sent before the response")
+		}
+		resp,err:=responder.Handle(req)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if err=initiator.Response(resp);err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sa_i.Keys.SK_d,sa_r.Keys.SK_d)||!bytes.Equal(sa_i.Keys.SK_ei,sa_r.Keys.SK_ei)||!bytes.Equal(sa_i.Keys.SK_pr,sa_r.Keys.SK_pr){
+			t.Fatal("keys differ after an additional key exchange")
+		}
+	}
+	if !responder.Done()||bytes.Equal(sa_i.Keys.SK_d,initial)||initiator.message_id!=4{
+		t.Fatal("additional key exchanges did not all run")
+	}
+}
+
+func Test_Intermediate_Errors(t *testing.T){
+	sa_i,sa_r:=sa_init(t)
+	if _,err:=New_Initiator(sa_i,[]uint16{KE_Curve25519});err!=Err_KE{
+		t.Fatal("non kyber additional key exchange was accepted")
+	}
+	initiator,_:=New_Initiator(sa_i,[]uint16{KE_Kyber768})
+	responder,_:=New_Responder(sa_r,[]uint16{KE_Kyber1024})
+	req,_:=initiator.Request()
+	tampered:=append([]byte(nil),req...)
+	tampered[len(tampered)-1]^=1
+	if _,err:=responder.Handle(tampered);err!=Err_Integrity{
+		t.Fatal("tampered request was opened")
+	}
+	tampered=append([]byte(nil),req...)
+	tampered[23]^=1//the message id is bound through the aad
+	if _,err:=responder.Handle(tampered);err!=Err_Integrity{
+		t.Fatal("request with a changed header was opened")
+	}
+	if _,err:=responder.Handle(req);err!=Err_KE{
+		t.Fatal("request with another method was answered")
+	}
+	responder,_=New_Responder(sa_r,[]uint16{KE_Kyber768})
+	if err:=initiator.Response(req);err!=Err_State{
+		t.Fatal("own request was taken as the response")
+	}
+	resp,err:=responder.Handle(req)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=responder.Handle(req);err!=Err_State{
+		t.Fatal("request was answered after the last exchange")
+	}
+	if err=initiator.Response(resp);err!=nil||!bytes.Equal(sa_i.Keys.SK_d,sa_r.Keys.SK_d){
+		t.Fatal("exchange did not finish after the rejected messages")
+	}
+}
@@ -0,0 +1,302 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the IKE SA keys, their update after every additional key exchange and the IKE_INTERMEDIATE exchanges that carry kyber
+	SKEYSEED=prf(Ni||Nr,g^ir)                         after IKE_SA_INIT
+	SKEYSEED(n)=prf(SK_d(n-1),SK(n)||Ni||Nr)          after the n-th IKE_INTERMEDIATE, SK(n) being the kyber shared secret (RFC 9370)
+	SK_d||SK_ei||SK_er||SK_pi||SK_pr=prf+(SKEYSEED,Ni||Nr||SPIi||SPIr)
+	SK payload:  IV(8)||AES-GCM(SK_e,salt||IV,aad=header||SK payload header,payloads||pad length(0))
+the prf is HMAC-SHA256 and the encryption AES-GCM-16 with 256 bit keys, so there are no SK_a keys, the IntAuth values of RFC 9242 for
+IKE_AUTH are left to the caller
+*/
+package kyber_ikev2
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+const(
+	prf_len=32
+	encr_key_len=32+4//the key and the salt
+	iv_len=8
+)
+
+type Keys struct{
+	SK_d []byte
+	SK_ei []byte
+	SK_er []byte
+	SK_pi []byte
+	SK_pr []byte
+}
+
+type SA struct{
+	SPI_i uint64
+	SPI_r uint64
+	N_i []byte
+	N_r []byte
+	Keys *Keys
+}
+
+func prf(key,data []byte)[]byte{
+	m:=hmac.New(sha256.New,key)
+	m.Write(data)
+	return m.Sum(nil)
+}
+
+func prf_plus(key,seed []byte,length int)[]byte{
+	var out,t []byte
+	for i:=byte(1);len(out)<length;i++{
+		t=prf(key,append(append(t,seed...),i))
+		out=append(out,t...)
+	}
+	return out[:length]
+}
+
+func (sa *SA)derive(skeyseed []byte){
+	seed:=append(append([]byte(nil),sa.N_i...),sa.N_r...)
+	seed=binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(seed,sa.SPI_i),sa.SPI_r)
+	keys:=prf_plus(skeyseed,seed,3*prf_len+2*encr_key_len)
+	sa.Keys=&Keys{
+		SK_d:keys[:prf_len],
+		SK_ei:keys[prf_len:prf_len+encr_key_len],
+		SK_er:keys[prf_len+encr_key_len:prf_len+2*encr_key_len],
+		SK_pi:keys[prf_len+2*encr_key_len:2*prf_len+2*encr_key_len],
+		SK_pr:keys[2*prf_len+2*encr_key_len:],
+	}
+}
+
+//the SA after IKE_SA_INIT, shared_secret is the result of the key exchange in the KE transform
+func New_SA(spi_i,spi_r uint64,n_i,n_r,shared_secret []byte)*SA{
+	sa:=&SA{SPI_i:spi_i,SPI_r:spi_r,N_i:n_i,N_r:n_r}
+	sa.derive(prf(append(append([]byte(nil),n_i...),n_r...),shared_secret))
+	return sa
+}
+
+//mixes the shared secret of an additional key exchange into every key
+func (sa *SA)Update(shared_secret []byte){
+	data:=append(append(append([]byte(nil),shared_secret...),sa.N_i...),sa.N_r...)
+	sa.derive(prf(sa.Keys.SK_d,data))
+}
+
+func gcm(key []byte)(cipher.AEAD,error){
+	block,err:=aes.NewCipher(key[:32])
+	if err!=nil{
+		return nil,err
+	}
+	return cipher.NewGCM(block)
+}
+
+//the SK_e key of whoever sent the message
+func (sa *SA)sender_key(flags byte)[]byte{
+	if flags&Flag_Initiator!=0{
+		return sa.Keys.SK_ei
+	}
+	return sa.Keys.SK_er
+}
+
+//the payloads go inside an SK payload, the header's next payload and length are filled in
+func (sa *SA)Seal(h Header,payloads []Payload)([]byte,error){
+	key:=sa.sender_key(h.Flags)
+	aead,err:=gcm(key)
+	if err!=nil{
+		return nil,err
+	}
+	first,inner:=Marshal_Payloads(payloads)
+	inner=append(inner,0)//no padding
+	iv:=make([]byte,iv_len)
+	if _,err=rand.Read(iv);err!=nil{
+		return nil,err
+	}
+	sk:=&Payload{Type:Payload_SK,Body:make([]byte,iv_len+len(inner)+aead.Overhead())}
+	h.Next_Payload=Payload_SK
+	h.Length=uint32(header_len+payload_header_len+len(sk.Body))
+	aad:=append(h.To_Bytes(),sk.header(first)...)
+	nonce:=append(append([]byte(nil),key[32:]...),iv...)
+	return append(append(aad,iv...),aead.Seal(nil,nonce,inner,aad)...),nil
+}
+
+//the SK payload has to be the only payload
+func (sa *SA)Open(msg []byte)(*Header,[]Payload,error){
+	h,err:=Parse_Header(msg)
+	if err!=nil{
+		return nil,nil,err
+	}
+	if h.Next_Payload!=Payload_SK||h.SPI_i!=sa.SPI_i||h.SPI_r!=sa.SPI_r{
+		return nil,nil,Err_Format
+	}
+	//the SK payload's next payload is the first payload inside it
+	if len(msg)<header_len+payload_header_len||int(binary.BigEndian.Uint16(msg[header_len+2:]))!=len(msg)-header_len{
+		return nil,nil,Err_Format
+	}
+	key:=sa.sender_key(h.Flags)
+	aead,err:=gcm(key)
+	if err!=nil{
+		return nil,nil,err
+	}
+	body:=msg[header_len+payload_header_len:]
+	if len(body)<iv_len+aead.Overhead()+1{
+		return nil,nil,Err_Format
+	}
+	nonce:=append(append([]byte(nil),key[32:]...),body[:iv_len]...)
+	inner,err:=aead.Open(nil,nonce,body[iv_len:],msg[:header_len+payload_header_len])
+	if err!=nil{
+		return nil,nil,Err_Integrity
+	}
+	pad:=int(inner[len(inner)-1])
+	if pad+1>len(inner){
+		return nil,nil,Err_Format
+	}
+	payloads,err:=Parse_Payloads(msg[header_len],inner[:len(inner)-1-pad])
+	if err!=nil{
+		return nil,nil,err
+	}
+	return h,payloads,nil
+}
+
+func single_ke(payloads []Payload,method uint16)(*KE_Payload,error){
+	if len(payloads)!=1{
+		return nil,Err_Format
+	}
+	ke,err:=Parse_KE(payloads[0])
+	if err!=nil{
+		return nil,err
+	}
+	if ke.Method!=method{
+		return nil,Err_KE
+	}
+	return ke,nil
+}
+
+/*
+runs the IKE_INTERMEDIATE exchanges of the additional key exchanges on an SA fresh from IKE_SA_INIT, message ids go on from 1,
+every exchange is protected with the keys from before it and the SA is updated once it completes
+*/
+type Initiator struct{
+	SA *SA
+	methods []uint16
+	message_id uint32
+	sk kyber_kem.Private_Key
+}
+
+func New_Initiator(sa *SA,methods []uint16)(*Initiator,error){
+	for _,m:=range methods{
+		if _,err:=Scheme_by_KE(m);err!=nil{
+			return nil,err
+		}
+	}
+	return &Initiator{SA:sa,methods:methods,message_id:1},nil
+}
+
+func (i *Initiator)Done()bool{
+	return len(i.methods)==0
+}
+
+func (i *Initiator)Request()([]byte,error){
+	if i.Done()||i.sk!=nil{
+		return nil,Err_State
+	}
+	scheme,_:=Scheme_by_KE(i.methods[0])
+	sk,err:=scheme.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	ke:=&KE_Payload{i.methods[0],sk.Public().To_Bytes()}
+	h:=Header{SPI_i:i.SA.SPI_i,SPI_r:i.SA.SPI_r,Exchange:Exchange_IKE_INTERMEDIATE,Flags:Flag_Initiator,Message_ID:i.message_id}
+	msg,err:=i.SA.Seal(h,[]Payload{ke.Payload()})
+	if err!=nil{
+		return nil,err
+	}
+	i.sk=sk
+	return msg,nil
+}
+
+//a failed response leaves the initiator waiting for the real one
+func (i *Initiator)Response(msg []byte)error{
+	if i.sk==nil{
+		return Err_State
+	}
+	h,payloads,err:=i.SA.Open(msg)
+	if err!=nil{
+		return err
+	}
+	if h.Exchange!=Exchange_IKE_INTERMEDIATE||h.Flags&(Flag_Initiator|Flag_Response)!=Flag_Response||h.Message_ID!=i.message_id{
+		return Err_State
+	}
+	ke,err:=single_ke(payloads,i.methods[0])
+	if err!=nil{
+		return err
+	}
+	if len(ke.Data)!=i.sk.Scheme().Ct_Len(){
+		return Err_Format
+	}
+	shared_secret,err:=i.sk.Dec(ke.Data)
+	if err!=nil{
+		return err
+	}
+	i.SA.Update(shared_secret)
+	i.methods,i.message_id,i.sk=i.methods[1:],i.message_id+1,nil
+	return nil
+}
+
+type Responder struct{
+	SA *SA
+	methods []uint16
+	message_id uint32
+}
+
+func New_Responder(sa *SA,methods []uint16)(*Responder,error){
+	for _,m:=range methods{
+		if _,err:=Scheme_by_KE(m);err!=nil{
+			return nil,err
+		}
+	}
+	return &Responder{SA:sa,methods:methods,message_id:1},nil
+}
+
+func (r *Responder)Done()bool{
+	return len(r.methods)==0
+}
+
+//answers the request with a kyber ciphertext to the initiator's public key, then updates the SA
+func (r *Responder)Handle(msg []byte)([]byte,error){
+	if r.Done(){
+		return nil,Err_State
+	}
+	h,payloads,err:=r.SA.Open(msg)
+	if err!=nil{
+		return nil,err
+	}
+	if h.Exchange!=Exchange_IKE_INTERMEDIATE||h.Flags&(Flag_Initiator|Flag_Response)!=Flag_Initiator||h.Message_ID!=r.message_id{
+		return nil,Err_State
+	}
+	ke,err:=single_ke(payloads,r.methods[0])
+	if err!=nil{
+		return nil,err
+	}
+	scheme,_:=Scheme_by_KE(r.methods[0])
+	pk,err:=scheme.Bytes_to_Pk(ke.Data)
+	if err!=nil{
+		return nil,Err_Format
+	}
+	c,shared_secret,err:=pk.Enc()
+	if err!=nil{
+		return nil,err
+	}
+	h.Flags=Flag_Response
+	resp,err:=r.SA.Seal(*h,[]Payload{(&KE_Payload{r.methods[0],c}).Payload()})
+	if err!=nil{
+		return nil,err
+	}
+	r.SA.Update(shared_secret)
+	r.methods,r.message_id=r.methods[1:],r.message_id+1
+	return resp,nil
+}
@@ -0,0 +1,127 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the key derivation functions used to combine and expand shared secrets: HKDF-SHA256, SHA3-256 and KMAC256 (NIST SP 800-185)
+*/
+package kyber_kdf
+
+import(
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+type KDF interface{
+	Name()string
+	Derive(secret,context []byte,label string,length int)([]byte,error)
+}
+
+type hkdf_sha256 struct{}
+type sha3_256 struct{}
+type kmac_256 struct{}
+
+var(
+	HKDF_SHA256 KDF=hkdf_sha256{}
+	SHA3_256 KDF=sha3_256{}
+	KMAC256 KDF=kmac_256{}
+)
+
+func By_Name(name string)(KDF,error){
+	for _,kdf:=range []KDF{HKDF_SHA256,SHA3_256,KMAC256}{
+		if kdf.Name()==name{
+			return kdf,nil
+		}
+	}
+	return nil,errors.New("unknown kdf: "+name)
+}
+
+func (hkdf_sha256)Name()string{
+	return "HKDF-SHA256"
+}
+
+//the label and context are used as the HKDF info with an empty salt
+func (hkdf_sha256)Derive(secret,context []byte,label string,length int)(out []byte,err error){
+	info:=make([]byte,0,len(label)+len(context))
+	info=append(info,label...)
+	info=append(info,context...)
+	out=make([]byte,length)
+	_,err=io.ReadFull(hkdf.New(sha256.New,secret,nil,info),out)
+	if err!=nil{
+		return nil,err
+	}
+	return
+}
+
+func (sha3_256)Name()string{
+	return "SHA3-256"
+}
+
+func (sha3_256)Derive(secret,context []byte,label string,length int)(out []byte,err error){
+	if length>32||length<1{
+		err=errors.New("SHA3-256 can only derive between 1 and 32 bytes")
+		return
+	}
+	H:=sha3.New256()
+	H.Write(secret)
+	H.Write(context)
+	H.Write([]byte(label))
+	out=H.Sum(nil)[:length]
+	return
+}
+
+func (kmac_256)Name()string{
+	return "KMAC256"
+}
+
+//the secret is the KMAC key and the label is the customization string
+func (kmac_256)Derive(secret,context []byte,label string,length int)([]byte,error){
+	if length<1{
+		return nil,errors.New("KMAC256 output length must be positive")
+	}
+	return KMAC_256(secret,context,length,[]byte(label)),nil
+}
+
+func KMAC_256(key,data []byte,length int,custom []byte)(out []byte){
+	const rate=136
+	c:=sha3.NewCShake256([]byte("KMAC"),custom)
+	encoded_key:=append(left_encode(uint64(len(key))*8),key...)
+	pad:=left_encode(rate)
+	pad=append(pad,encoded_key...)
+	if rem:=len(pad)%rate;rem!=0{
+		pad=append(pad,make([]byte,rate-rem)...)
+	}
+	c.Write(pad)
+	c.Write(data)
+	c.Write(right_encode(uint64(length)*8))
+	out=make([]byte,length)
+	c.Read(out)
+	return
+}
+
+func left_encode(x uint64)[]byte{
+	var bytes9 [9]byte
+	binary.BigEndian.PutUint64(bytes9[1:],x)
+	i:=1
+	for i<8&&bytes9[i]==0{
+		i++
+	}
+	bytes9[i-1]=byte(9-i)
+	return bytes9[i-1:]
+}
+
+func right_encode(x uint64)[]byte{
+	var bytes9 [9]byte
+	binary.BigEndian.PutUint64(bytes9[:8],x)
+	i:=0
+	for i<7&&bytes9[i]==0{
+		i++
+	}
+	bytes9[8]=byte(8-i)
+	return bytes9[i:]
+}
@@ -0,0 +1,59 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the key derivation functions
+*/
+package kyber_kdf
+
+import(
+	"encoding/hex"
+	"bytes"
+	"testing"
+)
+
+func Test_KMAC_256(t *testing.T){//NIST SP 800-185 KMAC sample #4
+	key,_:=hex.DecodeString("404142434445464748494A4B4C4D4E4F505152535455565758595A5B5C5D5E5F")
+	want,_:=hex.DecodeString("20C570C31346F703C9AC36C61C03CB64C3970D0CFC787E9B79599D273A68D2F7F69D4CC3DE9D104A351689F27CF6F5951F0103F33F4F24871024D9C27773A8DD")
+	out:=KMAC_256(key,[]byte{0,1,2,3},64,[]byte("My Tagged Application"))
+	if !bytes.Equal(out,want){
+		t.Fatal("KMAC256 does not match test vector")
+	}
+}
+
+func Test_HKDF_SHA256(t *testing.T){//RFC 5869 test case 3
+	ikm:=bytes.Repeat([]byte{0x0b},22)
+	want,_:=hex.DecodeString("8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8")
+	out,err:=HKDF_SHA256.Derive(ikm,nil,"",42)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out,want){
+		t.Fatal("HKDF-SHA256 does not match test vector")
+	}
+}
+
+func Test_Derive(t *testing.T){
+	for _,kdf:=range []KDF{HKDF_SHA256,SHA3_256,KMAC256}{
+		named,err:=By_Name(kdf.Name())
+		if err!=nil||named!=kdf{
+			t.Fatal("kdf lookup failed for "+kdf.Name())
+		}
+		a,err:=kdf.Derive([]byte("secret"),[]byte("context"),"label a",32)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		b,err:=kdf.Derive([]byte("secret"),[]byte("context"),"label b",32)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if bytes.Equal(a,b){
+			t.Fatal(kdf.Name()+" output does not depend on the label")
+		}
+	}
+	if _,err:=SHA3_256.Derive(nil,nil,"",33);err==nil{
+		t.Fatal("SHA3-256 derived more than 32 bytes")
+	}
+}
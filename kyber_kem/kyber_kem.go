@@ -0,0 +1,386 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a common KEM interface that wraps kyber_512,kyber_768,kyber_1024 and their 90s variants so protocols can be written once for every parameter set
+*/
+package kyber_kem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_512"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_768"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_1024"
+	"errors"
+)
+
+const Shared_key_len=32
+
+type Scheme interface{
+	Name()string
+	Keygen()(Private_Key,error)
+	Seed_to_Keys(seed [32]byte)(Private_Key,error)
+	Bytes_to_Pk(data []byte)(Public_Key,error)
+	Bytes_to_Sk(data []byte)(Private_Key,error)
+	Pk_Len()int
+	Sk_Len()int
+	Ct_Len()int
+	Ss_Len()int
+}
+
+type Public_Key interface{
+	Scheme()Scheme
+	To_Bytes()[]byte
+	Enc()(c,K []byte,err error)
+}
+
+type Private_Key interface{
+	Scheme()Scheme
+	To_Bytes()[]byte
+	Public()Public_Key
+	Dec(c []byte)(K []byte,err error)
+}
+
+type scheme struct{
+	name string
+	pk_len,sk_len,ct_len int
+	keygen func(s *scheme)*private_key
+	seed_keygen func(s *scheme,seed [32]byte)(*private_key,error)
+	load_pk func(s *scheme,data []byte)(*public_key,error)
+	load_sk func(s *scheme,data []byte)(*private_key,error)
+}
+
+type public_key struct{
+	scheme *scheme
+	bytes []byte
+	enc func()(c,K []byte)
+}
+
+type private_key struct{
+	scheme *scheme
+	bytes,pk_bytes []byte
+	dec func(c []byte)(K []byte,err error)
+}
+
+var(
+	Kyber_512 Scheme=&scheme{"kyber_512",800,1632,768,keygen_512,seed_keygen_512,load_pk_512,load_sk_512}
+	Kyber_768 Scheme=&scheme{"kyber_768",1184,2400,1088,keygen_768,seed_keygen_768,load_pk_768,load_sk_768}
+	Kyber_1024 Scheme=&scheme{"kyber_1024",1568,3168,1568,keygen_1024,seed_keygen_1024,load_pk_1024,load_sk_1024}
+	Kyber_512_90s Scheme=&scheme{"kyber_512_90s",800,1632,768,keygen_512_90s,seed_keygen_512_90s,load_pk_512_90s,load_sk_512_90s}
+	Kyber_768_90s Scheme=&scheme{"kyber_768_90s",1184,2400,1088,keygen_768_90s,seed_keygen_768_90s,load_pk_768_90s,load_sk_768_90s}
+	Kyber_1024_90s Scheme=&scheme{"kyber_1024_90s",1568,3168,1568,keygen_1024_90s,seed_keygen_1024_90s,load_pk_1024_90s,load_sk_1024_90s}
+)
+
+var All=[]Scheme{Kyber_512,Kyber_768,Kyber_1024,Kyber_512_90s,Kyber_768_90s,Kyber_1024_90s}
+
+func Scheme_by_Name(name string)(Scheme,error){
+	for _,s:=range All{
+		if s.Name()==name{
+			return s,nil
+		}
+	}
+	return nil,errors.New("unknown kyber scheme: "+name)
+}
+
+func (s *scheme)Name()string{
+	return s.name
+}
+
+func (s *scheme)Pk_Len()int{
+	return s.pk_len
+}
+
+func (s *scheme)Sk_Len()int{
+	return s.sk_len
+}
+
+func (s *scheme)Ct_Len()int{
+	return s.ct_len
+}
+
+func (s *scheme)Ss_Len()int{
+	return Shared_key_len
+}
+
+func (s *scheme)Keygen()(Private_Key,error){
+	return s.keygen(s),nil
+}
+
+func (s *scheme)Seed_to_Keys(seed [32]byte)(Private_Key,error){
+	sk,err:=s.seed_keygen(s,seed)
+	if err!=nil{
+		return nil,err
+	}
+	return sk,nil
+}
+
+func (s *scheme)Bytes_to_Pk(data []byte)(Public_Key,error){
+	pk,err:=s.load_pk(s,data)
+	if err!=nil{
+		return nil,err
+	}
+	return pk,nil
+}
+
+func (s *scheme)Bytes_to_Sk(data []byte)(Private_Key,error){
+	sk,err:=s.load_sk(s,data)
+	if err!=nil{
+		return nil,err
+	}
+	return sk,nil
+}
+
+func (pk *public_key)Scheme()Scheme{
+	return pk.scheme
+}
+
+func (pk *public_key)To_Bytes()[]byte{
+	return append([]byte(nil),pk.bytes...)
+}
+
+func (pk *public_key)Enc()(c,K []byte,err error){
+	c,K=pk.enc()
+	return
+}
+
+func (sk *private_key)Scheme()Scheme{
+	return sk.scheme
+}
+
+func (sk *private_key)To_Bytes()[]byte{
+	return append([]byte(nil),sk.bytes...)
+}
+
+func (sk *private_key)Public()Public_Key{
+	pk,_:=sk.scheme.load_pk(sk.scheme,sk.pk_bytes)//pk_bytes always has the right length
+	return pk
+}
+
+func (sk *private_key)Dec(c []byte)(K []byte,err error){
+	return sk.dec(c)
+}
+
+func new_sk(s *scheme,data,pk_bytes []byte,dec func(c []byte)([]byte,error))*private_key{
+	return &private_key{scheme:s,bytes:data,pk_bytes:pk_bytes,dec:dec}
+}
+
+func shake_dec(dec func(c []byte,Shared_key_length int)([]byte,error))func(c []byte)([]byte,error){
+	return func(c []byte)([]byte,error){
+		return dec(c,Shared_key_len)
+	}
+}
+
+func dec_90s(dec func(c []byte)([32]byte,error))func(c []byte)([]byte,error){
+	return func(c []byte)([]byte,error){
+		K,err:=dec(c)
+		if err!=nil{
+			return nil,err
+		}
+		return K[:],nil
+	}
+}
+
+func keygen_512(s *scheme)*private_key{
+	sk:=kyber_512.Keygen()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec))
+}
+
+func seed_keygen_512(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_512.Seed_to_Keys(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_sk_512(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_512.Bytes_to_Sk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_pk_512(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_512.Bytes_to_Pk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc(Shared_key_len)
+		return c[:],K
+	}},nil
+}
+
+func keygen_768(s *scheme)*private_key{
+	sk:=kyber_768.Keygen()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec))
+}
+
+func seed_keygen_768(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_768.Seed_to_Keys(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_sk_768(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_768.Bytes_to_Sk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_pk_768(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_768.Bytes_to_Pk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc(Shared_key_len)
+		return c[:],K
+	}},nil
+}
+
+func keygen_1024(s *scheme)*private_key{
+	sk:=kyber_1024.Keygen()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec))
+}
+
+func seed_keygen_1024(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_1024.Seed_to_Keys(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_sk_1024(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_1024.Bytes_to_Sk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],shake_dec(sk.Dec)),nil
+}
+
+func load_pk_1024(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_1024.Bytes_to_Pk(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc(Shared_key_len)
+		return c[:],K
+	}},nil
+}
+
+func keygen_512_90s(s *scheme)*private_key{
+	sk:=kyber_512.Keygen_90s()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec))
+}
+
+func seed_keygen_512_90s(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_512.Seed_to_Keys_90s(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_sk_512_90s(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_512.Bytes_to_Sk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_pk_512_90s(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_512.Bytes_to_Pk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc()
+		return c[:],K[:]
+	}},nil
+}
+
+func keygen_768_90s(s *scheme)*private_key{
+	sk:=kyber_768.Keygen_90s()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec))
+}
+
+func seed_keygen_768_90s(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_768.Seed_to_Keys_90s(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_sk_768_90s(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_768.Bytes_to_Sk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_pk_768_90s(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_768.Bytes_to_Pk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc()
+		return c[:],K[:]
+	}},nil
+}
+
+func keygen_1024_90s(s *scheme)*private_key{
+	sk:=kyber_1024.Keygen_90s()
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec))
+}
+
+func seed_keygen_1024_90s(s *scheme,seed [32]byte)(*private_key,error){
+	sk,err:=kyber_1024.Seed_to_Keys_90s(seed)
+	if err!=nil{
+		return nil,err
+	}
+	data:=sk.To_Bytes()
+	return new_sk(s,data[:],sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_sk_1024_90s(s *scheme,data []byte)(*private_key,error){
+	sk,err:=kyber_1024.Bytes_to_Sk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return new_sk(s,append([]byte(nil),data...),sk.Pk_Bytes[:],dec_90s(sk.Dec)),nil
+}
+
+func load_pk_1024_90s(s *scheme,data []byte)(*public_key,error){
+	pk,err:=kyber_1024.Bytes_to_Pk_90s(data)
+	if err!=nil{
+		return nil,err
+	}
+	return &public_key{s,pk.Bytes[:],func()([]byte,[]byte){
+		c,K:=pk.Enc()
+		return c[:],K[:]
+	}},nil
+}
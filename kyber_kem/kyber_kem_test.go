@@ -0,0 +1,90 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the common KEM interface
+*/
+package kyber_kem
+
+import(
+	"bytes"
+	"testing"
+)
+
+func Test_Schemes(t *testing.T){
+	for _,s:=range All{
+		named,err:=Scheme_by_Name(s.Name())
+		if err!=nil||named!=s{
+			t.Fatal("scheme lookup failed for "+s.Name())
+		}
+		sk,err:=s.Keygen()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if len(sk.To_Bytes())!=s.Sk_Len()||len(sk.Public().To_Bytes())!=s.Pk_Len(){
+			t.Fatal(s.Name()+" key lengths do not match the scheme")
+		}
+		pk,err:=s.Bytes_to_Pk(sk.Public().To_Bytes())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		c,K,err:=pk.Enc()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if len(c)!=s.Ct_Len()||len(K)!=s.Ss_Len(){
+			t.Fatal(s.Name()+" ciphertext or shared key length does not match the scheme")
+		}
+		K_dec,err:=sk.Dec(c)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(K,K_dec){
+			t.Fatal(s.Name()+" shared keys do not match")
+		}
+		loaded,err:=s.Bytes_to_Sk(sk.To_Bytes())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		K_dec,err=loaded.Dec(c)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(K,K_dec){
+			t.Fatal(s.Name()+" shared keys do not match after loading the private key")
+		}
+		c[0]^=1
+		K_dec,err=sk.Dec(c)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if bytes.Equal(K,K_dec){
+			t.Fatal(s.Name()+" tampered ciphertext decapsulated to the same key")
+		}
+		if _,err=sk.Dec(c[1:]);err==nil{
+			t.Fatal(s.Name()+" accepted a short ciphertext")
+		}
+	}
+}
+
+func Test_Seed_to_Keys(t *testing.T){
+	seed:=[32]byte{1,2,3}
+	for _,s:=range All{
+		a,err:=s.Seed_to_Keys(seed)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		b,err:=s.Seed_to_Keys(seed)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(a.Public().To_Bytes(),b.Public().To_Bytes()){
+			t.Fatal(s.Name()+" seed did not reproduce the public key")
+		}
+		if _,err=s.Seed_to_Keys([32]byte{});err==nil{
+			t.Fatal(s.Name()+" accepted a nil seed")
+		}
+	}
+}
@@ -0,0 +1,331 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains Kemeleon style encodings that make kyber public keys and ciphertexts look like uniformly random bytes
+	public key:  t read as the base q integer r=sum t_i*q^i, rejected unless r<2^b where b=bitlen(q^(256k))-1, sent as r in b bits||rho
+	ciphertext:  every compressed coefficient of u and v is replaced by a uniform x mod q that compresses back to it, then read as a base q
+	             integer and rejected the same way
+the ciphertext grows since its coefficients are sent mod q instead of compressed,
+the unused top bits of the leading byte are filled with random bits, rejection happens with probability below 1/2 and keygen and encaps just run again,
+a rejected ciphertext can not be encoded again with new preimages since that would skew the accepted ones, the whole encapsulation has to be redone
+*/
+package kyber_kemeleon
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+const(
+	q=3329
+	n=256
+	rho_len=32
+	max_tries=128//the chance of this many rejections in a row is below 2^-128
+)
+
+var(
+	Err_Scheme=errors.New("kyber_kemeleon: scheme is not a kyber parameter set")
+	Err_Reject=errors.New("kyber_kemeleon: value has no uniform encoding, generate a new one")
+	Err_Format=errors.New("kyber_kemeleon: encoded value has the wrong length")
+)
+
+type Scheme struct{
+	KEM kyber_kem.Scheme
+	k int
+	d_u int
+	d_v int
+	pk_bits int
+	ct_bits int
+}
+
+//the parameters are read off the public key length, so the 90s variants work too
+func For(s kyber_kem.Scheme)(*Scheme,error){
+	for _,known:=range kyber_kem.All{
+		if s!=known{
+			continue
+		}
+		k:=(s.Pk_Len()-rho_len)/384
+		out:=&Scheme{KEM:s,k:k,d_u:10,d_v:4}
+		if k==4{
+			out.d_u,out.d_v=11,5
+		}
+		out.pk_bits=power(k*n).BitLen()-1
+		out.ct_bits=power((k+1)*n).BitLen()-1
+		return out,nil
+	}
+	return nil,Err_Scheme
+}
+
+func must(s kyber_kem.Scheme)*Scheme{
+	out,err:=For(s)
+	if err!=nil{
+		panic(err)
+	}
+	return out
+}
+
+var(
+	Kyber_512=must(kyber_kem.Kyber_512)
+	Kyber_768=must(kyber_kem.Kyber_768)
+	Kyber_1024=must(kyber_kem.Kyber_1024)
+)
+
+var All=[]*Scheme{Kyber_512,Kyber_768,Kyber_1024}
+
+func power(e int)*big.Int{
+	return new(big.Int).Exp(big.NewInt(q),big.NewInt(int64(e)),nil)
+}
+
+func (s *Scheme)Pk_Len()int{
+	return (s.pk_bits+7)/8+rho_len
+}
+
+func (s *Scheme)Ct_Len()int{
+	return (s.ct_bits+7)/8
+}
+
+//little endian bit packing of d bit values, the way kyber packs its coefficients
+func unpack(data []byte,d,count int)[]uint16{
+	out:=make([]uint16,count)
+	for i:=range out{
+		for j:=0;j<d;j++{
+			bit:=i*d+j
+			out[i]|=uint16(data[bit/8]>>(bit%8)&1)<<j
+		}
+	}
+	return out
+}
+
+func pack(values []uint16,d int)[]byte{
+	out:=make([]byte,(len(values)*d+7)/8)
+	for i,x:=range values{
+		for j:=0;j<d;j++{
+			bit:=i*d+j
+			out[bit/8]|=byte(x>>j&1)<<(bit%8)
+		}
+	}
+	return out
+}
+
+func compress(x uint16,d int)uint16{
+	return uint16(((uint32(x)<<d)+q/2)/q)&(1<<d-1)
+}
+
+//preimages[d][c] lists every x mod q with compress(x,d)==c
+var preimages=map[int][][]uint16{}
+
+func init(){
+	for _,d:=range []int{4,5,10,11}{
+		table:=make([][]uint16,1<<d)
+		for x:=uint16(0);x<q;x++{
+			c:=compress(x,d)
+			table[c]=append(table[c],x)
+		}
+		preimages[d]=table
+	}
+}
+
+//r=sum digits_i*q^i, nil if r needs all of the top bit
+func to_integer(digits []uint16,bits int)*big.Int{
+	r:=new(big.Int)
+	Q:=big.NewInt(q)
+	for i:=len(digits)-1;i>=0;i--{
+		r.Mul(r,Q)
+		r.Add(r,big.NewInt(int64(digits[i])))
+	}
+	if r.BitLen()>bits{
+		return nil
+	}
+	return r
+}
+
+func from_integer(r *big.Int,count int)[]uint16{
+	Q:=big.NewInt(q)
+	digit:=new(big.Int)
+	out:=make([]uint16,count)
+	for i:=range out{
+		r.DivMod(r,Q,digit)
+		out[i]=uint16(digit.Int64())
+	}
+	return out
+}
+
+//big endian in ceil(bits/8) bytes with the unused top bits random
+func integer_bytes(r *big.Int,bits int)([]byte,error){
+	out:=make([]byte,(bits+7)/8)
+	r.FillBytes(out)
+	if extra:=8*len(out)-bits;extra>0{
+		var fill [1]byte
+		if _,err:=rand.Read(fill[:]);err!=nil{
+			return nil,err
+		}
+		out[0]|=fill[0]&^(0xff>>extra)
+	}
+	return out,nil
+}
+
+func bytes_integer(data []byte,bits int)*big.Int{
+	top:=append([]byte(nil),data...)
+	top[0]&=0xff>>(8*len(top)-bits)
+	return new(big.Int).SetBytes(top)
+}
+
+func (s *Scheme)check(scheme kyber_kem.Scheme)error{
+	if scheme!=s.KEM{//the 90s variants have the same lengths but are different schemes
+		return Err_Scheme
+	}
+	return nil
+}
+
+//Err_Reject for up to half of all public keys, Keygen only hands out ones that encode
+func (s *Scheme)Encode_Pk(pk kyber_kem.Public_Key)([]byte,error){
+	if err:=s.check(pk.Scheme());err!=nil{
+		return nil,err
+	}
+	pk_bytes:=pk.To_Bytes()
+	t:=unpack(pk_bytes,12,s.k*n)
+	for _,x:=range t{
+		if x>=q{
+			return nil,Err_Reject
+		}
+	}
+	r:=to_integer(t,s.pk_bits)
+	if r==nil{
+		return nil,Err_Reject
+	}
+	out,err:=integer_bytes(r,s.pk_bits)
+	if err!=nil{
+		return nil,err
+	}
+	return append(out,pk_bytes[384*s.k:]...),nil
+}
+
+//every string of Pk_Len bytes decodes to a public key
+func (s *Scheme)Decode_Pk(data []byte)(kyber_kem.Public_Key,error){
+	if len(data)!=s.Pk_Len(){
+		return nil,Err_Format
+	}
+	split:=len(data)-rho_len
+	t:=from_integer(bytes_integer(data[:split],s.pk_bits),s.k*n)
+	return s.KEM.Bytes_to_Pk(append(pack(t,12),data[split:]...))
+}
+
+//a key pair whose public key encodes, with the encoding
+func (s *Scheme)Keygen()(kyber_kem.Private_Key,[]byte,error){
+	for i:=0;i<max_tries;i++{
+		sk,err:=s.KEM.Keygen()
+		if err!=nil{
+			return nil,nil,err
+		}
+		encoded,err:=s.Encode_Pk(sk.Public())
+		if err==nil{
+			return sk,encoded,nil
+		}
+		if err!=Err_Reject{
+			return nil,nil,err
+		}
+	}
+	return nil,nil,Err_Reject
+}
+
+//uniform index below size from rejection sampling two random bytes at a time
+func uniform_index(size int,random []byte)(int,[]byte,error){
+	limit:=65536-65536%size
+	for{
+		if len(random)<2{
+			random=make([]byte,512)
+			if _,err:=rand.Read(random);err!=nil{
+				return 0,nil,err
+			}
+		}
+		x:=int(random[0])|int(random[1])<<8
+		random=random[2:]
+		if x<limit{
+			return x%size,random,nil
+		}
+	}
+}
+
+/*
+encodes a standard ciphertext with fresh random preimages, Err_Reject means the encapsulation has to be redone,
+Encaps does that and is what senders should use
+*/
+func (s *Scheme)Encode_Ct(c []byte)([]byte,error){
+	if len(c)!=s.KEM.Ct_Len(){
+		return nil,Err_Format
+	}
+	u_len:=s.k*n*s.d_u/8
+	compressed:=append(unpack(c[:u_len],s.d_u,s.k*n),unpack(c[u_len:],s.d_v,n)...)
+	digits:=make([]uint16,len(compressed))
+	var random []byte
+	var err error
+	for i,x:=range compressed{
+		d:=s.d_u
+		if i>=s.k*n{
+			d=s.d_v
+		}
+		options:=preimages[d][x]
+		var j int
+		if j,random,err=uniform_index(len(options),random);err!=nil{
+			return nil,err
+		}
+		digits[i]=options[j]
+	}
+	r:=to_integer(digits,s.ct_bits)
+	if r==nil{
+		return nil,Err_Reject
+	}
+	return integer_bytes(r,s.ct_bits)
+}
+
+//every string of Ct_Len bytes decodes to a ciphertext
+func (s *Scheme)Decode_Ct(data []byte)([]byte,error){
+	if len(data)!=s.Ct_Len(){
+		return nil,Err_Format
+	}
+	digits:=from_integer(bytes_integer(data,s.ct_bits),(s.k+1)*n)
+	u,v:=digits[:s.k*n],digits[s.k*n:]
+	for i:=range u{
+		u[i]=compress(u[i],s.d_u)
+	}
+	for i:=range v{
+		v[i]=compress(v[i],s.d_v)
+	}
+	return append(pack(u,s.d_u),pack(v,s.d_v)...),nil
+}
+
+func (s *Scheme)Encaps(pk kyber_kem.Public_Key)(encoded,K []byte,err error){
+	if err=s.check(pk.Scheme());err!=nil{
+		return nil,nil,err
+	}
+	for i:=0;i<max_tries;i++{
+		c,K,err:=pk.Enc()
+		if err!=nil{
+			return nil,nil,err
+		}
+		encoded,err=s.Encode_Ct(c)
+		if err==nil{
+			return encoded,K,nil
+		}
+		if err!=Err_Reject{
+			return nil,nil,err
+		}
+	}
+	return nil,nil,Err_Reject
+}
+
+func (s *Scheme)Decaps(sk kyber_kem.Private_Key,encoded []byte)([]byte,error){
+	if err:=s.check(sk.Scheme());err!=nil{
+		return nil,err
+	}
+	c,err:=s.Decode_Ct(encoded)
+	if err!=nil{
+		return nil,err
+	}
+	return sk.Dec(c)
+}
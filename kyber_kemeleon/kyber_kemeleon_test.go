@@ -0,0 +1,181 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the Kemeleon encodings and statistical tests that their bytes and bits are uniform
+*/
+package kyber_kemeleon
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/rand"
+	"bytes"
+	"testing"
+)
+
+const samples=256
+
+func Test_Round_Trip(t *testing.T){
+	for _,kem:=range kyber_kem.All{
+		s,err:=For(kem)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		sk,encoded_pk,err:=s.Keygen()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		pk,err:=s.Decode_Pk(encoded_pk)
+		if err!=nil||len(encoded_pk)!=s.Pk_Len()||!bytes.Equal(pk.To_Bytes(),sk.Public().To_Bytes()){
+			t.Fatal(kem.Name()+" public key did not round trip")
+		}
+		encoded_ct,K,err:=s.Encaps(pk)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		got,err:=s.Decaps(sk,encoded_ct)
+		if err!=nil||len(encoded_ct)!=s.Ct_Len()||!bytes.Equal(got,K){
+			t.Fatal(kem.Name()+" shared secret did not round trip")
+		}
+		//every string decodes, so a random one can not be told apart by failing
+		random:=make([]byte,s.Pk_Len())
+		rand.Read(random)
+		if _,err=s.Decode_Pk(random);err!=nil{
+			t.Fatal(kem.Name()+" random string did not decode to a public key")
+		}
+		random=make([]byte,s.Ct_Len())
+		rand.Read(random)
+		if c,err:=s.Decode_Ct(random);err!=nil||len(c)!=kem.Ct_Len(){
+			t.Fatal(kem.Name()+" random string did not decode to a ciphertext")
+		}
+	}
+	if _,err:=For(nil);err!=Err_Scheme{
+		t.Fatal("unknown scheme was accepted")
+	}
+	sk,_:=kyber_kem.Kyber_768.Keygen()
+	if _,err:=Kyber_512.Encode_Pk(sk.Public());err!=Err_Scheme{
+		t.Fatal("public key of another scheme was encoded")
+	}
+	sk_90s,_:=kyber_kem.Kyber_768_90s.Keygen()
+	if _,err:=Kyber_768.Encode_Pk(sk_90s.Public());err!=Err_Scheme{
+		t.Fatal("kyber_768_90s public key was encoded as kyber_768")
+	}
+	if _,_,err:=Kyber_768.Encaps(sk_90s.Public());err!=Err_Scheme{
+		t.Fatal("kyber_768 encapsulated to a kyber_768_90s public key")
+	}
+	if _,err:=Kyber_768.Decaps(sk_90s,make([]byte,Kyber_768.Ct_Len()));err!=Err_Scheme{
+		t.Fatal("kyber_768 decapsulated with a kyber_768_90s private key")
+	}
+	if _,err:=Kyber_768.Decode_Ct(make([]byte,Kyber_768.Ct_Len()-1));err!=Err_Format{
+		t.Fatal("short ciphertext was decoded")
+	}
+}
+
+//a standard ciphertext re-encodes to a different string that decodes back to it
+func Test_Ciphertext_Preimages(t *testing.T){
+	sk,_:=kyber_kem.Kyber_1024.Keygen()
+	first,_,err:=Kyber_1024.Encaps(sk.Public())//some ciphertexts never encode, this one is known to
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,_:=Kyber_1024.Decode_Ct(first)
+	var second []byte
+	for i:=0;i<max_tries&&second==nil;i++{
+		second,_=Kyber_1024.Encode_Ct(c)
+	}
+	if second==nil{
+		t.Fatal("ciphertext with an encoding did not encode again")
+	}
+	b,_:=Kyber_1024.Decode_Ct(second)
+	if bytes.Equal(first,second)||!bytes.Equal(b,c){
+		t.Fatal("ciphertext preimages are not random")
+	}
+}
+
+//sum over the byte values of (observed-expected)^2/expected, about 255 for uniform bytes with a standard deviation of about 23
+func chi_square(data [][]byte)float64{
+	var counts [256]float64
+	total:=0.0
+	for _,d:=range data{
+		for _,b:=range d{
+			counts[b]++
+			total++
+		}
+	}
+	expected:=total/256
+	chi:=0.0
+	for _,c:=range counts{
+		chi+=(c-expected)*(c-expected)/expected
+	}
+	return chi
+}
+
+//chi square over the compressed u coefficients, about 2^d-1 for uniform ones
+func coefficient_chi_square(cts [][]byte,s *Scheme)float64{
+	counts:=make([]float64,1<<s.d_u)
+	total:=0.0
+	for _,c:=range cts{
+		for _,x:=range unpack(c,s.d_u,s.k*n){
+			counts[x]++
+			total++
+		}
+	}
+	expected:=total/float64(len(counts))
+	chi:=0.0
+	for _,c:=range counts{
+		chi+=(c-expected)*(c-expected)/expected
+	}
+	return chi
+}
+
+//the worst count of ones at any bit position, binomial with a standard deviation of 8 for 256 samples, 6 deviations is the bound
+func worst_bit(data [][]byte)float64{
+	worst:=0.0
+	for i:=0;i<8*len(data[0]);i++{
+		ones:=0.0
+		for _,d:=range data{
+			ones+=float64(d[i/8]>>(i%8)&1)
+		}
+		worst=max(worst,ones-samples/2,samples/2-ones)
+	}
+	return worst
+}
+
+func Test_Uniform(t *testing.T){
+	for _,s:=range All{
+		var pks,cts,plain_pks,plain_cts [][]byte
+		rejected:=0
+		for len(pks)<samples{
+			sk,_:=s.KEM.Keygen()
+			encoded,err:=s.Encode_Pk(sk.Public())
+			if err==Err_Reject{
+				rejected++
+				continue
+			}
+			pks=append(pks,encoded)
+			plain_pks=append(plain_pks,sk.Public().To_Bytes())
+			c,_,_:=sk.Public().Enc()
+			plain_cts=append(plain_cts,c)
+			encoded,_,_=s.Encaps(sk.Public())
+			cts=append(cts,encoded)
+		}
+		//rejection takes 19% (kyber_768) to 45% (kyber_512) of keys
+		if rejected>=2*samples{
+			t.Fatal(s.KEM.Name()+" rejected far more public keys than it should")
+		}
+		for _,set:=range [][][]byte{pks,cts}{
+			if chi:=chi_square(set);chi>400{
+				t.Fatalf("%s encoded bytes are not uniform, chi square %.0f",s.KEM.Name(),chi)
+			}
+			if worst:=worst_bit(set);worst>48{
+				t.Fatalf("%s encoded bits are not uniform, a bit is off by %.0f",s.KEM.Name(),worst)
+			}
+		}
+		//the plain encodings are told apart, coefficients below q and compression that maps 3 or 4 values (1024 bins) or 1 or 2 (2048 bins) to each bin
+		if chi_square(plain_pks)<1000||coefficient_chi_square(plain_cts,s)<2*float64(int(1)<<s.d_u){
+			t.Fatal(s.KEM.Name()+" statistical tests can not tell the plain encodings apart")
+		}
+	}
+}
@@ -0,0 +1,508 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a KEMTLS style handshake where the server authenticates by decapsulating to its long-term kyber_768 key instead of signing
+
+	ClientHello           C->S  random||pk_e
+	ServerHello           S->C  random||c_e||auth flag                HS=Extract(dES,K_e)
+	{Certificate}         S->C  server certificate                    encrypted under the handshake traffic keys
+	{ClientKemCiphertext} C->S  c_s to the server's static key        AHS=Extract(dHS,K_s)
+	{Certificate}         C->S  client certificate when requested     encrypted under the authenticated handshake keys
+	{ServerKemCiphertext} S->C  c_c to the client's static key        MS=Extract(dAHS,K_c or 0)
+	{Finished}            C->S  HMAC over the transcript
+	{Finished}            S->C  HMAC over the transcript
+*/
+package kyber_kemtls
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const(
+	record_handshake byte=22
+	record_application byte=23
+	record_alert byte=21
+	msg_client_hello byte=1
+	msg_server_hello byte=2
+	msg_certificate byte=11
+	msg_client_kem_ciphertext byte=12
+	msg_server_kem_ciphertext byte=13
+	msg_finished byte=20
+	Max_record_len=16384
+	header_len=3
+)
+
+var kem=kyber_kem.Kyber_768
+
+var(
+	Err_Certificate=errors.New("kyber_kemtls: peer certificate is not trusted")
+	Err_Finished=errors.New("kyber_kemtls: finished MAC is invalid")
+	Err_Unexpected=errors.New("kyber_kemtls: unexpected handshake message")
+	Err_Record=errors.New("kyber_kemtls: bad record")
+)
+
+type Certificate struct{
+	Name string
+	Public_Key kyber_kem.Public_Key
+	Issuer_Sig []byte//ed25519 signature of the CA over the name and key, empty for pinned keys
+}
+
+type Config struct{
+	Certificate *Certificate
+	Private_Key kyber_kem.Private_Key
+	CA ed25519.PublicKey//peer certificates signed by this key are trusted
+	Pinned_Key []byte//a peer certificate carrying exactly this public key is trusted
+	Server_Name string//checked against the server certificate name when set
+	Require_Client_Auth bool
+}
+
+func Issue_Certificate(ca ed25519.PrivateKey,name string,pk kyber_kem.Public_Key)*Certificate{
+	c:=&Certificate{Name:name,Public_Key:pk}
+	c.Issuer_Sig=ed25519.Sign(ca,c.signed_bytes())
+	return c
+}
+
+func (c *Certificate)signed_bytes()[]byte{
+	data:=[]byte("kyber_kemtls certificate")
+	data=binary.BigEndian.AppendUint16(data,uint16(len(c.Name)))
+	data=append(data,c.Name...)
+	return append(data,c.Public_Key.To_Bytes()...)
+}
+
+func (c *Certificate)To_Bytes()[]byte{
+	data:=binary.BigEndian.AppendUint16(nil,uint16(len(c.Name)))
+	data=append(data,c.Name...)
+	data=append(data,c.Public_Key.To_Bytes()...)
+	return append(data,c.Issuer_Sig...)
+}
+
+func Bytes_to_Certificate(data []byte)(c *Certificate,err error){
+	if len(data)<2{
+		return nil,Err_Record
+	}
+	n:=int(binary.BigEndian.Uint16(data))
+	data=data[2:]
+	if len(data)<n+kem.Pk_Len(){
+		return nil,Err_Record
+	}
+	c=&Certificate{Name:string(data[:n])}
+	if c.Public_Key,err=kem.Bytes_to_Pk(data[n:n+kem.Pk_Len()]);err!=nil{
+		return nil,err
+	}
+	c.Issuer_Sig=append([]byte(nil),data[n+kem.Pk_Len():]...)
+	return
+}
+
+func (config *Config)verify(c *Certificate,name string)error{
+	if name!=""&&c.Name!=name{
+		return Err_Certificate
+	}
+	if config.Pinned_Key!=nil{
+		if subtle.ConstantTimeCompare(config.Pinned_Key,c.Public_Key.To_Bytes())!=1{
+			return Err_Certificate
+		}
+		return nil
+	}
+	if config.CA!=nil&&ed25519.Verify(config.CA,c.signed_bytes(),c.Issuer_Sig){
+		return nil
+	}
+	return Err_Certificate
+}
+
+type half_conn struct{
+	sync.Mutex
+	aead cipher.AEAD
+	iv []byte
+	seq uint64
+}
+
+func (hc *half_conn)set_key(secret []byte){
+	key:=expand_label(secret,"key",nil,chacha20poly1305.KeySize)
+	hc.aead,_=chacha20poly1305.New(key)
+	hc.iv=expand_label(secret,"iv",nil,chacha20poly1305.NonceSize)
+	hc.seq=0
+}
+
+func (hc *half_conn)nonce()[]byte{
+	nonce:=append([]byte(nil),hc.iv...)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:],hc.seq)
+	for i:=range seq{
+		nonce[len(nonce)-8+i]^=seq[i]
+	}
+	hc.seq++
+	return nonce
+}
+
+type Conn struct{
+	conn net.Conn
+	config *Config
+	in,out half_conn
+	transcript hash.Hash
+	peer *Certificate
+	buf []byte
+	closed bool
+}
+
+func Client(conn net.Conn,config *Config)(*Conn,error){
+	c:=&Conn{conn:conn,config:config,transcript:sha256.New()}
+	if err:=c.client_handshake();err!=nil{
+		conn.Close()
+		return nil,err
+	}
+	return c,nil
+}
+
+func Server(conn net.Conn,config *Config)(*Conn,error){
+	if config.Certificate==nil||config.Private_Key==nil{
+		return nil,errors.New("kyber_kemtls: server needs a certificate and private key")
+	}
+	c:=&Conn{conn:conn,config:config,transcript:sha256.New()}
+	if err:=c.server_handshake();err!=nil{
+		conn.Close()
+		return nil,err
+	}
+	return c,nil
+}
+
+func (c *Conn)Peer_Certificate()*Certificate{
+	return c.peer
+}
+
+func (c *Conn)write_record(typ byte,data []byte)error{
+	c.out.Lock()
+	defer c.out.Unlock()
+	header:=[]byte{typ,0,0}
+	if c.out.aead!=nil{
+		binary.BigEndian.PutUint16(header[1:],uint16(len(data)+c.out.aead.Overhead()))
+		data=c.out.aead.Seal(nil,c.out.nonce(),data,header)
+	}else{
+		binary.BigEndian.PutUint16(header[1:],uint16(len(data)))
+	}
+	_,err:=c.conn.Write(append(header,data...))
+	return err
+}
+
+func (c *Conn)read_record()(typ byte,data []byte,err error){
+	var header [header_len]byte
+	if _,err=io.ReadFull(c.conn,header[:]);err!=nil{
+		return
+	}
+	data=make([]byte,binary.BigEndian.Uint16(header[1:]))
+	if _,err=io.ReadFull(c.conn,data);err!=nil{
+		return
+	}
+	if c.in.aead!=nil{
+		if data,err=c.in.aead.Open(nil,c.in.nonce(),data,header[:]);err!=nil{
+			return 0,nil,Err_Record
+		}
+	}
+	return header[0],data,nil
+}
+
+func (c *Conn)write_handshake(typ byte,body []byte)error{
+	msg:=append([]byte{typ},body...)
+	c.transcript.Write(msg)
+	return c.write_record(record_handshake,msg)
+}
+
+func (c *Conn)read_handshake(typ byte)([]byte,error){
+	c.in.Lock()
+	rec,msg,err:=c.read_record()
+	c.in.Unlock()
+	if err!=nil{
+		return nil,err
+	}
+	if rec!=record_handshake||len(msg)<1||msg[0]!=typ{
+		return nil,Err_Unexpected
+	}
+	c.transcript.Write(msg)
+	return msg[1:],nil
+}
+
+func (c *Conn)transcript_hash()[]byte{
+	return c.transcript.Sum(nil)
+}
+
+func extract(salt,ikm []byte)[]byte{
+	if salt==nil{
+		salt=make([]byte,sha256.Size)
+	}
+	if ikm==nil{
+		ikm=make([]byte,sha256.Size)
+	}
+	return hkdf.Extract(sha256.New,ikm,salt)
+}
+
+func expand_label(secret []byte,label string,context []byte,length int)[]byte{
+	info:=binary.BigEndian.AppendUint16(nil,uint16(length))
+	info=append(info,byte(len("kemtls ")+len(label)))
+	info=append(info,"kemtls "+label...)
+	info=append(info,byte(len(context)))
+	info=append(info,context...)
+	out:=make([]byte,length)
+	io.ReadFull(hkdf.Expand(sha256.New,secret,info),out)
+	return out
+}
+
+func derive_secret(secret []byte,label string,transcript []byte)[]byte{
+	return expand_label(secret,label,transcript,sha256.Size)
+}
+
+func derived(secret []byte)[]byte{
+	empty:=sha256.Sum256(nil)
+	return derive_secret(secret,"derived",empty[:])
+}
+
+func finished_mac(secret []byte,label string,transcript []byte)[]byte{
+	mac:=hmac.New(sha256.New,expand_label(secret,label,nil,sha256.Size))
+	mac.Write(transcript)
+	return mac.Sum(nil)
+}
+
+func (c *Conn)client_handshake()error{
+	var random [32]byte
+	rand.Read(random[:])
+	e,err:=kem.Keygen()
+	if err!=nil{
+		return err
+	}
+	if err=c.write_handshake(msg_client_hello,append(random[:],e.Public().To_Bytes()...));err!=nil{
+		return err
+	}
+	sh,err:=c.read_handshake(msg_server_hello)
+	if err!=nil{
+		return err
+	}
+	if len(sh)!=32+kem.Ct_Len()+1{
+		return Err_Record
+	}
+	K_e,err:=e.Dec(sh[32:32+kem.Ct_Len()])
+	if err!=nil{
+		return err
+	}
+	client_auth:=sh[len(sh)-1]==1
+	HS:=extract(derived(extract(nil,nil)),K_e)
+	th:=c.transcript_hash()
+	c.out.set_key(derive_secret(HS,"c hs traffic",th))
+	c.in.set_key(derive_secret(HS,"s hs traffic",th))
+	body,err:=c.read_handshake(msg_certificate)
+	if err!=nil{
+		return err
+	}
+	if c.peer,err=Bytes_to_Certificate(body);err!=nil{
+		return err
+	}
+	if err=c.config.verify(c.peer,c.config.Server_Name);err!=nil{
+		return err
+	}
+	ct,K_s,err:=c.peer.Public_Key.Enc()
+	if err!=nil{
+		return err
+	}
+	if err=c.write_handshake(msg_client_kem_ciphertext,ct);err!=nil{
+		return err
+	}
+	AHS:=extract(derived(HS),K_s)
+	th=c.transcript_hash()
+	c.out.set_key(derive_secret(AHS,"c ahs traffic",th))
+	c.in.set_key(derive_secret(AHS,"s ahs traffic",th))
+	var K_c []byte
+	if client_auth{
+		if c.config.Certificate==nil||c.config.Private_Key==nil{
+			return errors.New("kyber_kemtls: server requires a client certificate")
+		}
+		if err=c.write_handshake(msg_certificate,c.config.Certificate.To_Bytes());err!=nil{
+			return err
+		}
+		body,err=c.read_handshake(msg_server_kem_ciphertext)
+		if err!=nil{
+			return err
+		}
+		if K_c,err=c.config.Private_Key.Dec(body);err!=nil{
+			return err
+		}
+	}
+	MS:=extract(derived(AHS),K_c)
+	if err=c.write_handshake(msg_finished,finished_mac(MS,"c finished",c.transcript_hash()));err!=nil{
+		return err
+	}
+	th=c.transcript_hash()
+	c.out.set_key(derive_secret(MS,"c ap traffic",th))
+	expected:=finished_mac(MS,"s finished",th)
+	body,err=c.read_handshake(msg_finished)
+	if err!=nil{
+		return err
+	}
+	if !hmac.Equal(body,expected){
+		return Err_Finished
+	}
+	c.in.set_key(derive_secret(MS,"s ap traffic",th))
+	return nil
+}
+
+func (c *Conn)server_handshake()error{
+	var random [32]byte
+	rand.Read(random[:])
+	ch,err:=c.read_handshake(msg_client_hello)
+	if err!=nil{
+		return err
+	}
+	if len(ch)!=32+kem.Pk_Len(){
+		return Err_Record
+	}
+	pk_e,err:=kem.Bytes_to_Pk(ch[32:])
+	if err!=nil{
+		return err
+	}
+	ct,K_e,err:=pk_e.Enc()
+	if err!=nil{
+		return err
+	}
+	sh:=append(random[:],ct...)
+	if c.config.Require_Client_Auth{
+		sh=append(sh,1)
+	}else{
+		sh=append(sh,0)
+	}
+	if err=c.write_handshake(msg_server_hello,sh);err!=nil{
+		return err
+	}
+	HS:=extract(derived(extract(nil,nil)),K_e)
+	th:=c.transcript_hash()
+	c.in.set_key(derive_secret(HS,"c hs traffic",th))
+	c.out.set_key(derive_secret(HS,"s hs traffic",th))
+	if err=c.write_handshake(msg_certificate,c.config.Certificate.To_Bytes());err!=nil{
+		return err
+	}
+	body,err:=c.read_handshake(msg_client_kem_ciphertext)
+	if err!=nil{
+		return err
+	}
+	K_s,err:=c.config.Private_Key.Dec(body)
+	if err!=nil{
+		return err
+	}
+	AHS:=extract(derived(HS),K_s)
+	th=c.transcript_hash()
+	c.in.set_key(derive_secret(AHS,"c ahs traffic",th))
+	c.out.set_key(derive_secret(AHS,"s ahs traffic",th))
+	var K_c []byte
+	if c.config.Require_Client_Auth{
+		body,err=c.read_handshake(msg_certificate)
+		if err!=nil{
+			return err
+		}
+		if c.peer,err=Bytes_to_Certificate(body);err!=nil{
+			return err
+		}
+		if err=c.config.verify(c.peer,"");err!=nil{
+			return err
+		}
+		var ct []byte
+		if ct,K_c,err=c.peer.Public_Key.Enc();err!=nil{
+			return err
+		}
+		if err=c.write_handshake(msg_server_kem_ciphertext,ct);err!=nil{
+			return err
+		}
+	}
+	MS:=extract(derived(AHS),K_c)
+	expected:=finished_mac(MS,"c finished",c.transcript_hash())
+	body,err=c.read_handshake(msg_finished)
+	if err!=nil{
+		return err
+	}
+	if !hmac.Equal(body,expected){
+		return Err_Finished
+	}
+	th=c.transcript_hash()
+	c.in.set_key(derive_secret(MS,"c ap traffic",th))
+	if err=c.write_handshake(msg_finished,finished_mac(MS,"s finished",th));err!=nil{
+		return err
+	}
+	c.out.set_key(derive_secret(MS,"s ap traffic",th))
+	return nil
+}
+
+func (c *Conn)Read(b []byte)(int,error){
+	c.in.Lock()
+	defer c.in.Unlock()
+	for len(c.buf)==0{
+		if c.closed{
+			return 0,io.EOF
+		}
+		typ,data,err:=c.read_record()
+		if err!=nil{
+			return 0,err
+		}
+		switch typ{
+		case record_application:
+			c.buf=data
+		case record_alert:
+			c.closed=true
+		default:
+			return 0,Err_Unexpected
+		}
+	}
+	n:=copy(b,c.buf)
+	c.buf=c.buf[n:]
+	return n,nil
+}
+
+func (c *Conn)Write(b []byte)(n int,err error){
+	for len(b)>0{
+		chunk:=min(len(b),Max_record_len)
+		if err=c.write_record(record_application,b[:chunk]);err!=nil{
+			return
+		}
+		n+=chunk
+		b=b[chunk:]
+	}
+	return
+}
+
+//sends a close_notify alert before closing the underlying connection, the peer gets a second to read it
+func (c *Conn)Close()error{
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	c.write_record(record_alert,[]byte{0})
+	return c.conn.Close()
+}
+
+func (c *Conn)LocalAddr()net.Addr{
+	return c.conn.LocalAddr()
+}
+
+func (c *Conn)RemoteAddr()net.Addr{
+	return c.conn.RemoteAddr()
+}
+
+func (c *Conn)SetDeadline(t time.Time)error{
+	return c.conn.SetDeadline(t)
+}
+
+func (c *Conn)SetReadDeadline(t time.Time)error{
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn)SetWriteDeadline(t time.Time)error{
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,132 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run the KEMTLS handshake between a client and server over net.Pipe
+*/
+package kyber_kemtls
+
+import(
+	"crypto/ed25519"
+	"crypto/rand"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+type result struct{
+	conn *Conn
+	err error
+}
+
+func handshake(client_config,server_config *Config)(client,server *Conn,client_err,server_err error){
+	c,s:=net.Pipe()
+	done:=make(chan result)
+	go func(){
+		conn,err:=Server(s,server_config)
+		done<-result{conn,err}
+	}()
+	client,client_err=Client(c,client_config)
+	r:=<-done
+	return client,r.conn,client_err,r.err
+}
+
+func new_server(name string,ca ed25519.PrivateKey)*Config{
+	sk,_:=kem.Keygen()
+	cert:=&Certificate{Name:name,Public_Key:sk.Public()}
+	if ca!=nil{
+		cert=Issue_Certificate(ca,name,sk.Public())
+	}
+	return &Config{Certificate:cert,Private_Key:sk}
+}
+
+func Test_Pinned(t *testing.T){
+	server_config:=new_server("server",nil)
+	client,server,err,server_err:=handshake(&Config{Pinned_Key:server_config.Certificate.Public_Key.To_Bytes()},server_config)
+	if err!=nil||server_err!=nil{
+		t.Fatal(err,server_err)
+	}
+	data:=make([]byte,3*Max_record_len+5)
+	rand.Read(data)
+	go func(){
+		client.Write(data)
+		client.Close()
+	}()
+	got,err:=io.ReadAll(server)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got,data){
+		t.Fatal("application data does not match")
+	}
+	if client.Peer_Certificate().Name!="server"||server.Peer_Certificate()!=nil{
+		t.Fatal("peer certificates are wrong")
+	}
+}
+
+func Test_Client_Auth(t *testing.T){
+	ca_pub,ca,_:=ed25519.GenerateKey(rand.Reader)
+	server_config:=new_server("server.internal",ca)
+	server_config.CA=ca_pub
+	server_config.Require_Client_Auth=true
+	client_config:=new_server("client.internal",ca)
+	client_config.CA=ca_pub
+	client_config.Server_Name="server.internal"
+	client,server,err,server_err:=handshake(client_config,server_config)
+	if err!=nil||server_err!=nil{
+		t.Fatal(err,server_err)
+	}
+	if server.Peer_Certificate().Name!="client.internal"{
+		t.Fatal("server did not learn the client certificate")
+	}
+	go server.Write([]byte("pong"))
+	buf:=make([]byte,4)
+	if _,err=io.ReadFull(client,buf);err!=nil||string(buf)!="pong"{
+		t.Fatal("server to client data failed")
+	}
+	go client.Close()
+	if _,err=server.Read(buf);err!=io.EOF{
+		t.Fatal("close notify was not delivered")
+	}
+}
+
+func Test_Untrusted(t *testing.T){
+	ca_pub,ca,_:=ed25519.GenerateKey(rand.Reader)
+	other_pub,_,_:=ed25519.GenerateKey(rand.Reader)
+	server_config:=new_server("server",ca)
+	if _,_,err,_:=handshake(&Config{CA:other_pub},server_config);err!=Err_Certificate{
+		t.Fatal("certificate from an unknown CA was accepted")
+	}
+	if _,_,err,_:=handshake(&Config{CA:ca_pub,Server_Name:"other"},server_config);err!=Err_Certificate{
+		t.Fatal("certificate for another name was accepted")
+	}
+	wrong,_:=kem.Keygen()
+	if _,_,err,_:=handshake(&Config{Pinned_Key:wrong.Public().To_Bytes()},server_config);err!=Err_Certificate{
+		t.Fatal("certificate that does not match the pinned key was accepted")
+	}
+	if _,_,err,_:=handshake(&Config{},server_config);err!=Err_Certificate{
+		t.Fatal("certificate was accepted without a trust anchor")
+	}
+}
+
+func Test_Impostor(t *testing.T){//a server that presents a certificate without the matching private key can not finish
+	server_config:=new_server("server",nil)
+	pinned:=server_config.Certificate.Public_Key.To_Bytes()
+	server_config.Private_Key,_=kem.Keygen()
+	_,_,err,server_err:=handshake(&Config{Pinned_Key:pinned},server_config)
+	if server_err==nil||err==nil{
+		t.Fatal("impostor server completed the handshake")
+	}
+}
+
+func Test_Missing_Client_Cert(t *testing.T){
+	server_config:=new_server("server",nil)
+	server_config.Require_Client_Auth=true
+	_,_,err,server_err:=handshake(&Config{Pinned_Key:server_config.Certificate.Public_Key.To_Bytes()},server_config)
+	if err==nil||server_err==nil{
+		t.Fatal("handshake completed without a required client certificate")
+	}
+}
@@ -0,0 +1,168 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the JSON over HTTP API for the keyring, byte fields are base64 as encoding/json writes them
+
+	POST /keys                         {"key_id","scheme"}            -> key info
+	GET  /keys/{id}                                                   -> key info
+	POST /keys/{id}/rotate                                            -> key info
+	POST /keys/{id}/versions/{n}/destroy
+	POST /generate-data-key            {"key_id","context"}           -> {"key_id","key_version","plaintext","ciphertext_blob"}
+	POST /decrypt                      {"ciphertext_blob","context"}  -> {"key_id","key_version","plaintext"}
+	POST /rewrap                       {"ciphertext_blob","context"}  -> {"key_id","key_version","ciphertext_blob"}
+errors are {"error"} with 400, 404 or 409, the handler does no authentication and must sit behind something that does
+*/
+package kyber_kms
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const max_request_len=1<<20
+
+type Create_Request struct{
+	Key_ID string `json:"key_id"`
+	Scheme string `json:"scheme"`//defaults to kyber_768
+}
+
+type Data_Key_Request struct{
+	Key_ID string `json:"key_id,omitempty"`
+	Context []byte `json:"context,omitempty"`
+	Ciphertext_Blob []byte `json:"ciphertext_blob,omitempty"`
+}
+
+type Data_Key_Response struct{
+	Key_ID string `json:"key_id"`
+	Key_Version uint32 `json:"key_version"`
+	Plaintext []byte `json:"plaintext,omitempty"`
+	Ciphertext_Blob []byte `json:"ciphertext_blob,omitempty"`
+}
+
+type api struct{
+	keyring *Keyring
+}
+
+func Handler(k *Keyring)http.Handler{
+	a:=&api{k}
+	mux:=http.NewServeMux()
+	mux.HandleFunc("POST /keys",a.create)
+	mux.HandleFunc("GET /keys/{id}",a.describe)
+	mux.HandleFunc("POST /keys/{id}/rotate",a.rotate)
+	mux.HandleFunc("POST /keys/{id}/versions/{n}/destroy",a.destroy)
+	mux.HandleFunc("POST /generate-data-key",a.generate)
+	mux.HandleFunc("POST /decrypt",a.decrypt)
+	mux.HandleFunc("POST /rewrap",a.rewrap)
+	return mux
+}
+
+func reply(w http.ResponseWriter,v any,err error){
+	w.Header().Set("Content-Type","application/json")
+	if err!=nil{
+		status:=http.StatusBadRequest
+		switch{
+		case errors.Is(err,Err_Not_Found):
+			status=http.StatusNotFound
+		case errors.Is(err,Err_Exists):
+			status=http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error":err.Error()})
+		return
+	}
+	if v==nil{
+		v=struct{}{}
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+func read_request(w http.ResponseWriter,r *http.Request,v any)bool{
+	if err:=json.NewDecoder(http.MaxBytesReader(w,r.Body,max_request_len)).Decode(v);err!=nil{
+		reply(w,nil,errors.New("kyber_kms: bad request: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+func (a *api)create(w http.ResponseWriter,r *http.Request){
+	var req Create_Request
+	if !read_request(w,r,&req){
+		return
+	}
+	s:=kyber_kem.Kyber_768
+	if req.Scheme!=""{
+		var err error
+		if s,err=kyber_kem.Scheme_by_Name(req.Scheme);err!=nil{
+			reply(w,nil,err)
+			return
+		}
+	}
+	info,err:=a.keyring.Create_Key(req.Key_ID,s)
+	reply(w,info,err)
+}
+
+func (a *api)describe(w http.ResponseWriter,r *http.Request){
+	info,err:=a.keyring.Describe_Key(r.PathValue("id"))
+	reply(w,info,err)
+}
+
+func (a *api)rotate(w http.ResponseWriter,r *http.Request){
+	info,err:=a.keyring.Rotate_Key(r.PathValue("id"))
+	reply(w,info,err)
+}
+
+func (a *api)destroy(w http.ResponseWriter,r *http.Request){
+	n,err:=strconv.ParseUint(r.PathValue("n"),10,32)
+	if err!=nil{
+		reply(w,nil,Err_Not_Found)
+		return
+	}
+	reply(w,nil,a.keyring.Destroy_Version(r.PathValue("id"),uint32(n)))
+}
+
+func (a *api)generate(w http.ResponseWriter,r *http.Request){
+	var req Data_Key_Request
+	if !read_request(w,r,&req){
+		return
+	}
+	data_key,wrapped,err:=a.keyring.Generate_Data_Key(req.Key_ID,req.Context)
+	if err!=nil{
+		reply(w,nil,err)
+		return
+	}
+	_,n,_,_:=parse(wrapped)
+	reply(w,&Data_Key_Response{req.Key_ID,n,data_key,wrapped},nil)
+}
+
+func (a *api)decrypt(w http.ResponseWriter,r *http.Request){
+	var req Data_Key_Request
+	if !read_request(w,r,&req){
+		return
+	}
+	data_key,info,n,err:=a.keyring.Decrypt(req.Ciphertext_Blob,req.Context)
+	if err!=nil{
+		reply(w,nil,err)
+		return
+	}
+	reply(w,&Data_Key_Response{Key_ID:info.Key_ID,Key_Version:n,Plaintext:data_key},nil)
+}
+
+func (a *api)rewrap(w http.ResponseWriter,r *http.Request){
+	var req Data_Key_Request
+	if !read_request(w,r,&req){
+		return
+	}
+	wrapped,err:=a.keyring.Rewrap(req.Ciphertext_Blob,req.Context)
+	if err!=nil{
+		reply(w,nil,err)
+		return
+	}
+	id,n,_,_:=parse(wrapped)
+	reply(w,&Data_Key_Response{Key_ID:id,Key_Version:n,Ciphertext_Blob:wrapped},nil)
+}
@@ -0,0 +1,371 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains KMS style envelope encryption with a file backed keyring of versioned kyber key encryption keys
+a data key is sealed to the current version of a named key, rotation adds a version and Rewrap moves old data keys onto it without touching the payloads
+
+	wrapped data key=format(1)||len||key id||key version(4)||kyber_seal(public key,data key,aad)
+	aad="kyber_kms"||len||key id||key version(4)||len||encryption context
+	keyring file="kyber-keyring-v1\n"||count(4)||keys, key=len||id||current version(4)||count(4)||versions, version=version(4)||len||scheme name||len||private key
+lengths are 4 byte big endian, a destroyed version keeps its number with an empty private key so wrapped keys for it fail cleanly
+the keyring file holds every private key in the clear and is only written with mode 0600, it should sit on an encrypted disk or behind a KMS of its own
+*/
+package kyber_kms
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_seal"
+	"crypto/rand"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+const(
+	Format byte=1
+	Data_key_len=32
+	keyring_magic="kyber-keyring-v1\n"
+	max_id_len=256
+)
+
+var(
+	Err_Not_Found=errors.New("kyber_kms: key or key version not found")
+	Err_Exists=errors.New("kyber_kms: key already exists")
+	Err_Key_ID=errors.New("kyber_kms: key id must be 1 to 256 characters without a slash")
+	Err_Decrypt=errors.New("kyber_kms: data key could not be unwrapped, the context or the blob is wrong")
+	Err_Format=errors.New("kyber_kms: malformed wrapped data key or keyring")
+	Err_Current=errors.New("kyber_kms: the current version of a key can not be destroyed")
+)
+
+type version struct{
+	number uint32
+	scheme kyber_kem.Scheme
+	sk kyber_kem.Private_Key//nil once destroyed
+}
+
+type key struct{
+	id string
+	current uint32
+	versions []*version
+}
+
+type Keyring struct{
+	mu sync.RWMutex
+	path string
+	keys map[string]*key
+}
+
+type Key_Info struct{
+	Key_ID string `json:"key_id"`
+	Scheme string `json:"scheme"`
+	Current uint32 `json:"current_version"`
+	Versions []uint32 `json:"versions"`//versions that can still unwrap
+}
+
+func put_bytes(data,field []byte)[]byte{
+	data=binary.BigEndian.AppendUint32(data,uint32(len(field)))
+	return append(data,field...)
+}
+
+type reader struct{
+	data []byte
+	err error
+}
+
+func (r *reader)uint32()(x uint32){
+	if r.err!=nil||len(r.data)<4{
+		r.err=Err_Format
+		return
+	}
+	x=binary.BigEndian.Uint32(r.data)
+	r.data=r.data[4:]
+	return
+}
+
+func (r *reader)bytes()(field []byte){
+	n:=r.uint32()
+	if r.err!=nil||uint64(len(r.data))<uint64(n){
+		r.err=Err_Format
+		return
+	}
+	field=r.data[:n]
+	r.data=r.data[n:]
+	return
+}
+
+//opens the keyring at path, a missing file is an empty keyring that is created on the first change
+func Open_Keyring(path string)(*Keyring,error){
+	k:=&Keyring{path:path,keys:map[string]*key{}}
+	data,err:=os.ReadFile(path)
+	if errors.Is(err,os.ErrNotExist){
+		return k,nil
+	}
+	if err!=nil{
+		return nil,err
+	}
+	if !bytes.HasPrefix(data,[]byte(keyring_magic)){
+		return nil,Err_Format
+	}
+	r:=&reader{data:data[len(keyring_magic):]}
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		kk:=&key{id:string(r.bytes()),current:r.uint32()}
+		for m:=r.uint32();m>0&&r.err==nil;m--{
+			v:=&version{number:r.uint32()}
+			name,sk:=r.bytes(),r.bytes()
+			if r.err!=nil{
+				break
+			}
+			if v.scheme,err=kyber_kem.Scheme_by_Name(string(name));err!=nil{
+				return nil,err
+			}
+			if len(sk)>0{
+				if v.sk,err=v.scheme.Bytes_to_Sk(sk);err!=nil{
+					return nil,err
+				}
+			}
+			if kk.version(v.number)!=nil{
+				return nil,Err_Format
+			}
+			kk.versions=append(kk.versions,v)
+		}
+		if r.err!=nil{
+			break
+		}
+		//every key needs the version it wraps new data keys under
+		if kk.version(kk.current)==nil||k.keys[kk.id]!=nil{
+			return nil,Err_Format
+		}
+		k.keys[kk.id]=kk
+	}
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	return k,nil
+}
+
+//written next to the keyring and renamed over it so a crash never leaves half a keyring
+func (k *Keyring)save()error{
+	data:=binary.BigEndian.AppendUint32([]byte(keyring_magic),uint32(len(k.keys)))
+	for _,kk:=range k.keys{
+		data=binary.BigEndian.AppendUint32(put_bytes(data,[]byte(kk.id)),kk.current)
+		data=binary.BigEndian.AppendUint32(data,uint32(len(kk.versions)))
+		for _,v:=range kk.versions{
+			var sk []byte
+			if v.sk!=nil{
+				sk=v.sk.To_Bytes()
+			}
+			data=put_bytes(put_bytes(binary.BigEndian.AppendUint32(data,v.number),[]byte(v.scheme.Name())),sk)
+		}
+	}
+	tmp:=k.path+".tmp"
+	if err:=os.WriteFile(tmp,data,0600);err!=nil{
+		return err
+	}
+	return os.Rename(tmp,k.path)
+}
+
+func check_id(id string)error{
+	if len(id)==0||len(id)>max_id_len||strings.Contains(id,"/"){
+		return Err_Key_ID
+	}
+	return nil
+}
+
+func (kk *key)version(n uint32)*version{
+	for _,v:=range kk.versions{
+		if v.number==n{
+			return v
+		}
+	}
+	return nil
+}
+
+func (kk *key)info()*Key_Info{
+	info:=&Key_Info{Key_ID:kk.id,Current:kk.current,Versions:[]uint32{}}
+	for _,v:=range kk.versions{
+		if v.sk!=nil{
+			info.Versions=append(info.Versions,v.number)
+		}
+	}
+	info.Scheme=kk.version(kk.current).scheme.Name()
+	return info
+}
+
+//every change is written out before it is visible, a failed write leaves the keyring as it was
+func (k *Keyring)update(id string,next *key)error{
+	old,had:=k.keys[id]
+	k.keys[id]=next
+	if err:=k.save();err!=nil{
+		if had{
+			k.keys[id]=old
+		}else{
+			delete(k.keys,id)
+		}
+		return err
+	}
+	return nil
+}
+
+func (kk *key)clone()*key{
+	out:=*kk
+	out.versions=append([]*version(nil),kk.versions...)
+	return &out
+}
+
+func (k *Keyring)Create_Key(id string,s kyber_kem.Scheme)(*Key_Info,error){
+	if err:=check_id(id);err!=nil{
+		return nil,err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _,ok:=k.keys[id];ok{
+		return nil,Err_Exists
+	}
+	sk,err:=s.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	kk:=&key{id:id,current:1,versions:[]*version{{1,s,sk}}}
+	if err=k.update(id,kk);err!=nil{
+		return nil,err
+	}
+	return kk.info(),nil
+}
+
+//adds a new version with the scheme of the current one and makes it current, older versions still unwrap
+func (k *Keyring)Rotate_Key(id string)(*Key_Info,error){
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kk,ok:=k.keys[id]
+	if !ok{
+		return nil,Err_Not_Found
+	}
+	s:=kk.version(kk.current).scheme
+	sk,err:=s.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	next:=kk.clone()
+	next.current=kk.versions[len(kk.versions)-1].number+1
+	next.versions=append(next.versions,&version{next.current,s,sk})
+	if err=k.update(id,next);err!=nil{
+		return nil,err
+	}
+	return next.info(),nil
+}
+
+//drops the private key of an old version once every data key wrapped to it has been rewrapped
+func (k *Keyring)Destroy_Version(id string,n uint32)error{
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kk,ok:=k.keys[id]
+	if !ok||kk.version(n)==nil{
+		return Err_Not_Found
+	}
+	if n==kk.current{
+		return Err_Current
+	}
+	next:=kk.clone()
+	for i,v:=range next.versions{
+		if v.number==n{
+			next.versions[i]=&version{v.number,v.scheme,nil}
+		}
+	}
+	return k.update(id,next)
+}
+
+func (k *Keyring)Describe_Key(id string)(*Key_Info,error){
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kk,ok:=k.keys[id]
+	if !ok{
+		return nil,Err_Not_Found
+	}
+	return kk.info(),nil
+}
+
+func aad(id string,n uint32,context []byte)[]byte{
+	return put_bytes(binary.BigEndian.AppendUint32(put_bytes([]byte("kyber_kms"),[]byte(id)),n),context)
+}
+
+func wrap(id string,v *version,data_key,context []byte)([]byte,error){
+	sealed,err:=kyber_seal.Seal(v.sk.Public(),data_key,aad(id,v.number,context))
+	if err!=nil{
+		return nil,err
+	}
+	out:=binary.BigEndian.AppendUint32(put_bytes([]byte{Format},[]byte(id)),v.number)
+	return append(out,sealed...),nil
+}
+
+//returns a fresh data key and the same key wrapped to the current version, the context must be given again to unwrap it
+func (k *Keyring)Generate_Data_Key(id string,context []byte)(data_key,wrapped []byte,err error){
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kk,ok:=k.keys[id]
+	if !ok{
+		return nil,nil,Err_Not_Found
+	}
+	data_key=make([]byte,Data_key_len)
+	if _,err=rand.Read(data_key);err!=nil{
+		return nil,nil,err
+	}
+	if wrapped,err=wrap(id,kk.version(kk.current),data_key,context);err!=nil{
+		return nil,nil,err
+	}
+	return
+}
+
+func parse(wrapped []byte)(id string,n uint32,sealed []byte,err error){
+	if len(wrapped)<1||wrapped[0]!=Format{
+		return "",0,nil,Err_Format
+	}
+	r:=&reader{data:wrapped[1:]}
+	id,n=string(r.bytes()),r.uint32()
+	if r.err!=nil{
+		return "",0,nil,Err_Format
+	}
+	return id,n,r.data,nil
+}
+
+//the key id and version are read from the blob, as with a KMS Decrypt call
+func (k *Keyring)Decrypt(wrapped,context []byte)(data_key []byte,info *Key_Info,n uint32,err error){
+	id,n,sealed,err:=parse(wrapped)
+	if err!=nil{
+		return
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kk,ok:=k.keys[id]
+	if !ok{
+		return nil,nil,0,Err_Not_Found
+	}
+	v:=kk.version(n)
+	if v==nil||v.sk==nil{
+		return nil,nil,0,Err_Not_Found
+	}
+	if data_key,err=kyber_seal.Open(v.sk,sealed,aad(id,n,context));err!=nil{
+		return nil,nil,0,Err_Decrypt
+	}
+	return data_key,kk.info(),n,nil
+}
+
+//unwraps and wraps again to the current version of the same key, the data key and so the payload stay the same
+func (k *Keyring)Rewrap(wrapped,context []byte)([]byte,error){
+	data_key,info,_,err:=k.Decrypt(wrapped,context)
+	if err!=nil{
+		return nil,err
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kk,ok:=k.keys[info.Key_ID]
+	if !ok{
+		return nil,Err_Not_Found
+	}
+	return wrap(kk.id,kk.version(kk.current),data_key,context)
+}
@@ -0,0 +1,196 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the keyring, envelope encryption and the HTTP API over httptest
+*/
+package kyber_kms
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func open(t *testing.T,path string)*Keyring{
+	k,err:=Open_Keyring(path)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return k
+}
+
+func Test_Envelope(t *testing.T){
+	path:=filepath.Join(t.TempDir(),"keyring")
+	k:=open(t,path)
+	for _,s:=range kyber_kem.All{
+		if _,err:=k.Create_Key("app "+s.Name(),s);err!=nil{
+			t.Fatal(err)
+		}
+	}
+	if _,err:=k.Create_Key("app kyber_768",kyber_kem.Kyber_768);err!=Err_Exists{
+		t.Fatal("key was created twice")
+	}
+	context:=[]byte("table=users")
+	data_key,wrapped,err:=k.Generate_Data_Key("app kyber_1024_90s",context)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	//the payload is encrypted once under the data key and never again
+	aead,_:=chacha20poly1305.New(data_key)
+	payload:=aead.Seal(nil,make([]byte,aead.NonceSize()),[]byte("row data"),nil)
+	info,err:=k.Rotate_Key("app kyber_1024_90s")
+	if err!=nil||info.Current!=2||info.Scheme!="kyber_1024_90s"{
+		t.Fatal("rotation did not add a version")
+	}
+	k=open(t,path)//everything survives a reload
+	rewrapped,err:=k.Rewrap(wrapped,context)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,n,_,_:=parse(rewrapped);n!=2{
+		t.Fatal("data key was not rewrapped to the current version")
+	}
+	if err=k.Destroy_Version("app kyber_1024_90s",2);err!=Err_Current{
+		t.Fatal("current version was destroyed")
+	}
+	if err=k.Destroy_Version("app kyber_1024_90s",1);err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,_,err=k.Decrypt(wrapped,context);err!=Err_Not_Found{
+		t.Fatal("data key unwrapped under a destroyed version")
+	}
+	k=open(t,path)
+	unwrapped,info,n,err:=k.Decrypt(rewrapped,context)
+	if err!=nil||n!=2||info.Key_ID!="app kyber_1024_90s"{
+		t.Fatal("rewrapped data key did not unwrap")
+	}
+	aead,_=chacha20poly1305.New(unwrapped)
+	if pt,err:=aead.Open(nil,make([]byte,aead.NonceSize()),payload,nil);err!=nil||string(pt)!="row data"{
+		t.Fatal("payload did not decrypt after rotation")
+	}
+	if _,_,_,err=k.Decrypt(rewrapped,[]byte("table=orders"));err!=Err_Decrypt{
+		t.Fatal("data key unwrapped under another context")
+	}
+	//a blob relabeled to another key does not unwrap
+	k.Create_Key("other",kyber_kem.Kyber_768)
+	_,other,_:=k.Generate_Data_Key("app kyber_768",nil)
+	_,_,sealed,_:=parse(other)
+	relabeled:=append(binary.BigEndian.AppendUint32(put_bytes([]byte{Format},[]byte("other")),1),sealed...)
+	if _,_,_,err=k.Decrypt(relabeled,nil);err!=Err_Decrypt{
+		t.Fatal("relabeled blob was accepted")
+	}
+	if _,_,_,err=k.Decrypt(other[:len(other)-1],nil);err!=Err_Decrypt{
+		t.Fatal("truncated blob was accepted")
+	}
+	if _,_,_,err=k.Decrypt([]byte{9},nil);err!=Err_Format{
+		t.Fatal("blob with an unknown format was accepted")
+	}
+	for _,bad:=range []string{"","a/b"}{
+		if _,err=k.Create_Key(bad,kyber_kem.Kyber_512);err!=Err_Key_ID{
+			t.Fatal("bad key id was accepted")
+		}
+	}
+}
+
+func Test_Keyring_Format(t *testing.T){
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	//the same key written keys times
+	keyring:=func(keys int,current uint32,versions ...uint32)[]byte{
+		data:=binary.BigEndian.AppendUint32([]byte(keyring_magic),uint32(keys))
+		for i:=0;i<keys;i++{
+			data=binary.BigEndian.AppendUint32(put_bytes(data,[]byte("app")),current)
+			data=binary.BigEndian.AppendUint32(data,uint32(len(versions)))
+			for _,n:=range versions{
+				data=put_bytes(put_bytes(binary.BigEndian.AppendUint32(data,n),[]byte("kyber_512")),sk.To_Bytes())
+			}
+		}
+		return data
+	}
+	path:=filepath.Join(t.TempDir(),"keyring")
+	os.WriteFile(path,keyring(1,2,1,2),0600)
+	if k:=open(t,path);len(k.keys["app"].versions)!=2{
+		t.Fatal("keyring did not load")
+	}
+	for name,data:=range map[string][]byte{
+		"no versions":keyring(1,1),
+		"missing current":keyring(1,3,1,2),
+		"duplicate versions":keyring(1,1,1,1),
+		"duplicate keys":keyring(2,1,1),
+	}{
+		os.WriteFile(path,data,0600)
+		if _,err:=Open_Keyring(path);err!=Err_Format{
+			t.Fatal("keyring with "+name+" was opened")
+		}
+	}
+}
+
+func post(t *testing.T,url string,req,resp any)int{
+	body,_:=json.Marshal(req)
+	r,err:=http.Post(url,"application/json",bytes.NewReader(body))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if resp!=nil&&r.StatusCode==http.StatusOK{
+		if err=json.NewDecoder(r.Body).Decode(resp);err!=nil{
+			t.Fatal(err)
+		}
+	}
+	return r.StatusCode
+}
+
+func Test_HTTP(t *testing.T){
+	server:=httptest.NewServer(Handler(open(t,filepath.Join(t.TempDir(),"keyring"))))
+	defer server.Close()
+	var info Key_Info
+	if post(t,server.URL+"/keys",&Create_Request{Key_ID:"payments",Scheme:"kyber_1024"},&info)!=http.StatusOK||info.Current!=1{
+		t.Fatal("key was not created")
+	}
+	if post(t,server.URL+"/keys",&Create_Request{Key_ID:"payments"},nil)!=http.StatusConflict{
+		t.Fatal("duplicate key was not a conflict")
+	}
+	var generated,decrypted,rewrapped Data_Key_Response
+	if post(t,server.URL+"/generate-data-key",&Data_Key_Request{Key_ID:"payments",Context:[]byte("ctx")},&generated)!=http.StatusOK{
+		t.Fatal("data key was not generated")
+	}
+	if post(t,server.URL+"/keys/payments/rotate",nil,&info)!=http.StatusOK||info.Current!=2{
+		t.Fatal("key was not rotated")
+	}
+	if post(t,server.URL+"/rewrap",&Data_Key_Request{Ciphertext_Blob:generated.Ciphertext_Blob,Context:[]byte("ctx")},&rewrapped)!=http.StatusOK||rewrapped.Key_Version!=2{
+		t.Fatal("data key was not rewrapped")
+	}
+	if post(t,server.URL+"/keys/payments/versions/1/destroy",nil,nil)!=http.StatusOK{
+		t.Fatal("old version was not destroyed")
+	}
+	if post(t,server.URL+"/decrypt",&Data_Key_Request{Ciphertext_Blob:generated.Ciphertext_Blob,Context:[]byte("ctx")},nil)!=http.StatusNotFound{
+		t.Fatal("destroyed version still unwraps")
+	}
+	if post(t,server.URL+"/decrypt",&Data_Key_Request{Ciphertext_Blob:rewrapped.Ciphertext_Blob,Context:[]byte("ctx")},&decrypted)!=http.StatusOK{
+		t.Fatal("rewrapped data key did not decrypt")
+	}
+	if !bytes.Equal(decrypted.Plaintext,generated.Plaintext)||decrypted.Key_ID!="payments"||decrypted.Key_Version!=2{
+		t.Fatal("decrypted data key does not match")
+	}
+	if post(t,server.URL+"/decrypt",&Data_Key_Request{Ciphertext_Blob:rewrapped.Ciphertext_Blob},nil)!=http.StatusBadRequest{
+		t.Fatal("data key decrypted without its context")
+	}
+	r,err:=http.Get(server.URL+"/keys/payments")
+	if err!=nil{
+		t.Fatal(err)
+	}
+	json.NewDecoder(r.Body).Decode(&info)
+	r.Body.Close()
+	if info.Scheme!="kyber_1024"||len(info.Versions)!=1||info.Versions[0]!=2{
+		t.Fatal("key info is wrong")
+	}
+}
@@ -0,0 +1,448 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the Noise protocol framework state machines with the post quantum handshake patterns from PQNoise
+KEM tokens replace the DH tokens: "ekem" encapsulates to the remote ephemeral key and "skem" to the remote static key
+*/
+package kyber_noise
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/chacha20poly1305"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"strings"
+)
+
+const(
+	Max_message_len=65535
+	tag_len=16
+	max_nonce=^uint64(0)
+)
+
+var(
+	Err_Nonce=errors.New("kyber_noise: nonce exhausted")
+	Err_State=errors.New("kyber_noise: handshake message handled out of turn")
+	Err_Short=errors.New("kyber_noise: handshake message is too short")
+	Err_Size=errors.New("kyber_noise: message exceeds 65535 bytes")
+)
+
+type Cipher struct{
+	Name string
+	new_aead func(key []byte)(cipher.AEAD,error)
+	big_endian bool
+}
+
+type Hash struct{
+	Name string
+	new func()hash.Hash
+	Len int
+}
+
+var(
+	Cipher_ChaChaPoly=Cipher{"ChaChaPoly",chacha20poly1305.New,false}
+	Cipher_AESGCM=Cipher{"AESGCM",new_aes_gcm,true}
+	Hash_SHA256=Hash{"SHA256",sha256.New,32}
+	Hash_SHA512=Hash{"SHA512",sha512.New,64}
+	Hash_BLAKE2s=Hash{"BLAKE2s",func()hash.Hash{h,_:=blake2s.New256(nil);return h},32}
+	Hash_BLAKE2b=Hash{"BLAKE2b",func()hash.Hash{h,_:=blake2b.New512(nil);return h},64}
+)
+
+func new_aes_gcm(key []byte)(cipher.AEAD,error){
+	block,err:=aes.NewCipher(key)
+	if err!=nil{
+		return nil,err
+	}
+	return cipher.NewGCM(block)
+}
+
+type Suite struct{
+	KEM kyber_kem.Scheme
+	Cipher Cipher
+	Hash Hash
+}
+
+type Pattern struct{
+	Name string
+	Pre_Responder_Static bool//the initiator knows the responder's static key before the handshake ("<- s ...")
+	Messages [][]string
+}
+
+var(
+	PQ_NN=Pattern{"pqNN",false,[][]string{{"e"},{"ekem"}}}
+	PQ_NK=Pattern{"pqNK",true,[][]string{{"skem","e"},{"ekem"}}}
+	PQ_XX=Pattern{"pqXX",false,[][]string{{"e"},{"ekem","s"},{"skem","s"},{"skem"}}}
+	PQ_IK=Pattern{"pqIK",true,[][]string{{"skem","e","s"},{"ekem","skem"}}}
+	Patterns=[]Pattern{PQ_NN,PQ_NK,PQ_XX,PQ_IK}
+)
+
+type Cipher_State struct{
+	cipher Cipher
+	aead cipher.AEAD
+	k []byte
+	n uint64
+}
+
+func (cs *Cipher_State)Initialize_Key(k []byte){
+	cs.k=k
+	cs.n=0
+	cs.aead=nil
+	if k!=nil{
+		cs.aead,_=cs.cipher.new_aead(k)//keys are always 32 bytes
+	}
+}
+
+func (cs *Cipher_State)Has_Key()bool{
+	return cs.k!=nil
+}
+
+func (cs *Cipher_State)Set_Nonce(n uint64){
+	cs.n=n
+}
+
+func (cs *Cipher_State)nonce(n uint64)[]byte{
+	var nonce [12]byte
+	if cs.cipher.big_endian{
+		binary.BigEndian.PutUint64(nonce[4:],n)
+	}else{
+		binary.LittleEndian.PutUint64(nonce[4:],n)
+	}
+	return nonce[:]
+}
+
+func (cs *Cipher_State)Encrypt_With_Ad(ad,plaintext []byte)([]byte,error){
+	if !cs.Has_Key(){
+		return append([]byte(nil),plaintext...),nil
+	}
+	if cs.n==max_nonce{
+		return nil,Err_Nonce
+	}
+	c:=cs.aead.Seal(nil,cs.nonce(cs.n),plaintext,ad)
+	cs.n++
+	return c,nil
+}
+
+func (cs *Cipher_State)Decrypt_With_Ad(ad,ciphertext []byte)([]byte,error){
+	if !cs.Has_Key(){
+		return append([]byte(nil),ciphertext...),nil
+	}
+	if cs.n==max_nonce{
+		return nil,Err_Nonce
+	}
+	p,err:=cs.aead.Open(nil,cs.nonce(cs.n),ciphertext,ad)
+	if err!=nil{
+		return nil,err
+	}
+	cs.n++
+	return p,nil
+}
+
+func (cs *Cipher_State)Rekey(){
+	if !cs.Has_Key(){
+		return
+	}
+	zeros:=make([]byte,32)
+	k:=cs.aead.Seal(nil,cs.nonce(max_nonce),zeros,nil)[:32]
+	n:=cs.n
+	cs.Initialize_Key(k)
+	cs.n=n
+}
+
+type Symmetric_State struct{
+	cs Cipher_State
+	hash Hash
+	ck,h []byte
+}
+
+func (ss *Symmetric_State)initialize(name string,suite Suite){
+	ss.hash=suite.Hash
+	ss.cs.cipher=suite.Cipher
+	if len(name)<=ss.hash.Len{
+		ss.h=make([]byte,ss.hash.Len)
+		copy(ss.h,name)
+	}else{
+		H:=ss.hash.new()
+		H.Write([]byte(name))
+		ss.h=H.Sum(nil)
+	}
+	ss.ck=append([]byte(nil),ss.h...)
+	ss.cs.Initialize_Key(nil)
+}
+
+func (ss *Symmetric_State)hmac(key []byte,data ...[]byte)[]byte{
+	mac:=hmac.New(ss.hash.new,key)
+	for _,d:=range data{
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func (ss *Symmetric_State)hkdf(ck,ikm []byte)(out1,out2 []byte){
+	temp:=ss.hmac(ck,ikm)
+	out1=ss.hmac(temp,[]byte{1})
+	out2=ss.hmac(temp,out1,[]byte{2})
+	return
+}
+
+func (ss *Symmetric_State)Mix_Key(ikm []byte){
+	var k []byte
+	ss.ck,k=ss.hkdf(ss.ck,ikm)
+	ss.cs.Initialize_Key(k[:32])
+}
+
+func (ss *Symmetric_State)Mix_Hash(data []byte){
+	H:=ss.hash.new()
+	H.Write(ss.h)
+	H.Write(data)
+	ss.h=H.Sum(nil)
+}
+
+func (ss *Symmetric_State)Encrypt_And_Hash(plaintext []byte)([]byte,error){
+	c,err:=ss.cs.Encrypt_With_Ad(ss.h,plaintext)
+	if err!=nil{
+		return nil,err
+	}
+	ss.Mix_Hash(c)
+	return c,nil
+}
+
+func (ss *Symmetric_State)Decrypt_And_Hash(ciphertext []byte)([]byte,error){
+	p,err:=ss.cs.Decrypt_With_Ad(ss.h,ciphertext)
+	if err!=nil{
+		return nil,err
+	}
+	ss.Mix_Hash(ciphertext)
+	return p,nil
+}
+
+func (ss *Symmetric_State)Split()(c1,c2 *Cipher_State){
+	k1,k2:=ss.hkdf(ss.ck,nil)
+	c1=&Cipher_State{cipher:ss.cs.cipher}
+	c2=&Cipher_State{cipher:ss.cs.cipher}
+	c1.Initialize_Key(k1[:32])
+	c2.Initialize_Key(k2[:32])
+	return
+}
+
+type Config struct{
+	Suite Suite
+	Pattern Pattern
+	Initiator bool
+	Prologue []byte
+	Static kyber_kem.Private_Key
+	Remote_Static kyber_kem.Public_Key
+}
+
+type Handshake_State struct{
+	ss Symmetric_State
+	suite Suite
+	pattern Pattern
+	initiator bool
+	s,e kyber_kem.Private_Key
+	rs,re kyber_kem.Public_Key
+	turn int
+}
+
+func Protocol_Name(pattern Pattern,suite Suite)string{
+	return "Noise_"+pattern.Name+"_"+kem_name(suite.KEM)+"_"+suite.Cipher.Name+"_"+suite.Hash.Name
+}
+
+//kyber_768 becomes Kyber768 and kyber_768_90s becomes Kyber768-90s since "_" separates the fields of a noise protocol name
+func kem_name(s kyber_kem.Scheme)string{
+	parts:=strings.Split(s.Name(),"_")
+	if len(parts)<2||parts[0]!="kyber"{
+		return strings.ReplaceAll(s.Name(),"_","-")
+	}
+	return "Kyber"+strings.Join(parts[1:],"-")
+}
+
+func New_Handshake(c Config)(*Handshake_State,error){
+	if c.Suite.KEM==nil||c.Suite.Hash.new==nil||c.Suite.Cipher.new_aead==nil{
+		return nil,errors.New("kyber_noise: incomplete cipher suite")
+	}
+	hs:=&Handshake_State{suite:c.Suite,pattern:c.Pattern,initiator:c.Initiator,s:c.Static,rs:c.Remote_Static}
+	needs_s:=false
+	for i,msg:=range c.Pattern.Messages{
+		for _,token:=range msg{
+			if token=="s"&&(i%2==0)==c.Initiator{
+				needs_s=true
+			}
+		}
+	}
+	if c.Pattern.Pre_Responder_Static{
+		if c.Initiator&&c.Remote_Static==nil{
+			return nil,errors.New("kyber_noise: "+c.Pattern.Name+" needs the responder's static key")
+		}
+		if !c.Initiator{
+			needs_s=true
+		}
+	}
+	if needs_s&&c.Static==nil{
+		return nil,errors.New("kyber_noise: "+c.Pattern.Name+" needs a static key")
+	}
+	hs.ss.initialize(Protocol_Name(c.Pattern,c.Suite),c.Suite)
+	hs.ss.Mix_Hash(c.Prologue)
+	if c.Pattern.Pre_Responder_Static{
+		if c.Initiator{
+			hs.ss.Mix_Hash(c.Remote_Static.To_Bytes())
+		}else{
+			hs.ss.Mix_Hash(c.Static.Public().To_Bytes())
+		}
+	}
+	return hs,nil
+}
+
+func (hs *Handshake_State)my_turn()bool{
+	return hs.turn<len(hs.pattern.Messages)&&(hs.turn%2==0)==hs.initiator
+}
+
+func (hs *Handshake_State)Complete()bool{
+	return hs.turn>=len(hs.pattern.Messages)
+}
+
+//the handshake hash can be used for channel binding once the handshake is complete
+func (hs *Handshake_State)Handshake_Hash()[]byte{
+	return append([]byte(nil),hs.ss.h...)
+}
+
+func (hs *Handshake_State)Peer_Static()kyber_kem.Public_Key{
+	return hs.rs
+}
+
+func (hs *Handshake_State)finish()(c1,c2 *Cipher_State){
+	hs.turn++
+	if hs.Complete(){
+		c1,c2=hs.ss.Split()
+	}
+	return
+}
+
+//c1 encrypts initiator to responder and c2 responder to initiator, both are nil until the last message
+func (hs *Handshake_State)Write_Message(payload []byte)(msg []byte,c1,c2 *Cipher_State,err error){
+	if !hs.my_turn(){
+		return nil,nil,nil,Err_State
+	}
+	kem:=hs.suite.KEM
+	for _,token:=range hs.pattern.Messages[hs.turn]{
+		switch token{
+		case "e":
+			if hs.e,err=kem.Keygen();err!=nil{
+				return
+			}
+			pk:=hs.e.Public().To_Bytes()
+			hs.ss.Mix_Hash(pk)
+			msg=append(msg,pk...)
+		case "s":
+			c,err:=hs.ss.Encrypt_And_Hash(hs.s.Public().To_Bytes())
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			msg=append(msg,c...)
+		case "ekem","skem":
+			remote:=hs.re
+			if token=="skem"{
+				remote=hs.rs
+			}
+			ct,K,err:=remote.Enc()
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			c,err:=hs.ss.Encrypt_And_Hash(ct)
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			msg=append(msg,c...)
+			hs.ss.Mix_Key(K)
+		}
+	}
+	c,err:=hs.ss.Encrypt_And_Hash(payload)
+	if err!=nil{
+		return
+	}
+	msg=append(msg,c...)
+	if len(msg)>Max_message_len{
+		return nil,nil,nil,Err_Size
+	}
+	c1,c2=hs.finish()
+	return
+}
+
+func (hs *Handshake_State)Read_Message(msg []byte)(payload []byte,c1,c2 *Cipher_State,err error){
+	if hs.Complete()||hs.my_turn(){
+		return nil,nil,nil,Err_State
+	}
+	if len(msg)>Max_message_len{
+		return nil,nil,nil,Err_Size
+	}
+	kem:=hs.suite.KEM
+	next:=func(n int)([]byte,error){
+		if hs.ss.cs.Has_Key(){
+			n+=tag_len
+		}
+		if len(msg)<n{
+			return nil,Err_Short
+		}
+		field:=msg[:n]
+		msg=msg[n:]
+		return field,nil
+	}
+	for _,token:=range hs.pattern.Messages[hs.turn]{
+		switch token{
+		case "e":
+			if len(msg)<kem.Pk_Len(){
+				return nil,nil,nil,Err_Short
+			}
+			if hs.re,err=kem.Bytes_to_Pk(msg[:kem.Pk_Len()]);err!=nil{
+				return
+			}
+			hs.ss.Mix_Hash(msg[:kem.Pk_Len()])
+			msg=msg[kem.Pk_Len():]
+		case "s":
+			field,err:=next(kem.Pk_Len())
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			pk,err:=hs.ss.Decrypt_And_Hash(field)
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			if hs.rs,err=kem.Bytes_to_Pk(pk);err!=nil{
+				return nil,nil,nil,err
+			}
+		case "ekem","skem":
+			local:=hs.e
+			if token=="skem"{
+				local=hs.s
+			}
+			field,err:=next(kem.Ct_Len())
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			ct,err:=hs.ss.Decrypt_And_Hash(field)
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			K,err:=local.Dec(ct)
+			if err!=nil{
+				return nil,nil,nil,err
+			}
+			hs.ss.Mix_Key(K)
+		}
+	}
+	if payload,err=hs.ss.Decrypt_And_Hash(msg);err!=nil{
+		return
+	}
+	c1,c2=hs.finish()
+	return
+}
@@ -0,0 +1,171 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run two in-memory peers through every PQNoise handshake pattern
+*/
+package kyber_noise
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"testing"
+)
+
+type peers struct{
+	I,R *Handshake_State
+	I_s,R_s kyber_kem.Private_Key
+}
+
+func new_peers(t *testing.T,pattern Pattern,suite Suite)*peers{
+	p:=new(peers)
+	p.I_s,_=suite.KEM.Keygen()
+	p.R_s,_=suite.KEM.Keygen()
+	I_conf:=Config{Suite:suite,Pattern:pattern,Initiator:true,Prologue:[]byte("test"),Static:p.I_s}
+	if pattern.Pre_Responder_Static{
+		I_conf.Remote_Static=p.R_s.Public()
+	}
+	var err error
+	if p.I,err=New_Handshake(I_conf);err!=nil{
+		t.Fatal(err)
+	}
+	if p.R,err=New_Handshake(Config{Suite:suite,Pattern:pattern,Prologue:[]byte("test"),Static:p.R_s});err!=nil{
+		t.Fatal(err)
+	}
+	return p
+}
+
+//returns the transport cipher states of the initiator and the responder
+func (p *peers)run()(I_send,I_recv,R_send,R_recv *Cipher_State,err error){
+	writer,reader:=p.I,p.R
+	for !p.I.Complete(){
+		msg,c1,c2,err:=writer.Write_Message([]byte("payload"))
+		if err!=nil{
+			return nil,nil,nil,nil,err
+		}
+		payload,d1,d2,err:=reader.Read_Message(msg)
+		if err!=nil{
+			return nil,nil,nil,nil,err
+		}
+		if !bytes.Equal(payload,[]byte("payload")){
+			return nil,nil,nil,nil,Err_Short
+		}
+		if c1!=nil{
+			if writer==p.I{
+				I_send,I_recv,R_recv,R_send=c1,c2,d1,d2
+			}else{
+				I_send,I_recv,R_recv,R_send=d1,d2,c1,c2
+			}
+		}
+		writer,reader=reader,writer
+	}
+	return
+}
+
+func Test_Patterns(t *testing.T){
+	suites:=[]Suite{
+		{kyber_kem.Kyber_512,Cipher_ChaChaPoly,Hash_SHA256},
+		{kyber_kem.Kyber_768,Cipher_AESGCM,Hash_BLAKE2s},
+		{kyber_kem.Kyber_1024,Cipher_ChaChaPoly,Hash_BLAKE2b},
+		{kyber_kem.Kyber_768_90s,Cipher_AESGCM,Hash_SHA512},
+	}
+	for _,suite:=range suites{
+		for _,pattern:=range Patterns{
+			p:=new_peers(t,pattern,suite)
+			I_send,I_recv,R_send,R_recv,err:=p.run()
+			if err!=nil{
+				t.Fatal(Protocol_Name(pattern,suite)+": "+err.Error())
+			}
+			if !bytes.Equal(p.I.Handshake_Hash(),p.R.Handshake_Hash()){
+				t.Fatal(Protocol_Name(pattern,suite)+" handshake hashes do not match")
+			}
+			for i:=0;i<3;i++{
+				c,_:=I_send.Encrypt_With_Ad(nil,[]byte("ping"))
+				if m,err:=R_recv.Decrypt_With_Ad(nil,c);err!=nil||string(m)!="ping"{
+					t.Fatal(Protocol_Name(pattern,suite)+" transport initiator to responder failed")
+				}
+				c,_=R_send.Encrypt_With_Ad(nil,[]byte("pong"))
+				if m,err:=I_recv.Decrypt_With_Ad(nil,c);err!=nil||string(m)!="pong"{
+					t.Fatal(Protocol_Name(pattern,suite)+" transport responder to initiator failed")
+				}
+			}
+			I_send.Rekey()
+			R_recv.Rekey()
+			c,_:=I_send.Encrypt_With_Ad(nil,[]byte("rekeyed"))
+			if _,err:=R_recv.Decrypt_With_Ad(nil,c);err!=nil{
+				t.Fatal(Protocol_Name(pattern,suite)+" rekey failed")
+			}
+		}
+	}
+}
+
+func Test_Peer_Static(t *testing.T){
+	p:=new_peers(t,PQ_XX,Suite{kyber_kem.Kyber_768,Cipher_ChaChaPoly,Hash_SHA256})
+	if _,_,_,_,err:=p.run();err!=nil{
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.I.Peer_Static().To_Bytes(),p.R_s.Public().To_Bytes())||!bytes.Equal(p.R.Peer_Static().To_Bytes(),p.I_s.Public().To_Bytes()){
+		t.Fatal("pqXX did not transmit the static keys")
+	}
+}
+
+func Test_Wrong_Static(t *testing.T){
+	suite:=Suite{kyber_kem.Kyber_768,Cipher_ChaChaPoly,Hash_SHA256}
+	for _,pattern:=range []Pattern{PQ_NK,PQ_IK}{
+		p:=new_peers(t,pattern,suite)
+		other,_:=suite.KEM.Keygen()
+		p.I,_=New_Handshake(Config{Suite:suite,Pattern:pattern,Initiator:true,Prologue:[]byte("test"),Static:p.I_s,Remote_Static:other.Public()})
+		if _,_,_,_,err:=p.run();err==nil{
+			t.Fatal(pattern.Name+" completed against the wrong responder key")
+		}
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	suite:=Suite{kyber_kem.Kyber_512,Cipher_ChaChaPoly,Hash_SHA256}
+	p:=new_peers(t,PQ_XX,suite)
+	msg,_,_,err:=p.I.Write_Message(nil)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,_,err=p.R.Read_Message(msg);err!=nil{
+		t.Fatal(err)
+	}
+	msg,_,_,_=p.R.Write_Message(nil)
+	msg[len(msg)-1]^=1
+	if _,_,_,err=p.I.Read_Message(msg);err==nil{
+		t.Fatal("tampered handshake message was accepted")
+	}
+	if _,_,_,err=p.R.Write_Message(nil);err!=Err_State{
+		t.Fatal("responder wrote two messages in a row")
+	}
+	p=new_peers(t,PQ_NN,suite)
+	msg,_,_,_=p.I.Write_Message(nil)
+	if _,_,_,err=p.R.Read_Message(msg);err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,_,err=p.R.Read_Message(msg);err!=Err_State{
+		t.Fatal("responder read a message out of turn")
+	}
+	p=new_peers(t,PQ_IK,suite)
+	msg,_,_,_=p.I.Write_Message(nil)
+	if _,_,_,err=p.R.Read_Message(msg[:len(msg)-40]);err==nil{
+		t.Fatal("truncated handshake message was accepted")
+	}
+}
+
+func Test_Prologue(t *testing.T){
+	suite:=Suite{kyber_kem.Kyber_512,Cipher_ChaChaPoly,Hash_SHA256}
+	I,_:=New_Handshake(Config{Suite:suite,Pattern:PQ_NN,Initiator:true,Prologue:[]byte("a")})
+	R,_:=New_Handshake(Config{Suite:suite,Pattern:PQ_NN,Prologue:[]byte("b")})
+	msg,_,_,_:=I.Write_Message(nil)
+	if _,_,_,err:=R.Read_Message(msg);err!=nil{
+		t.Fatal(err)
+	}
+	msg,_,_,_=R.Write_Message(nil)
+	if _,_,_,err:=I.Read_Message(msg);err==nil{
+		t.Fatal("peers with different prologues completed the handshake")
+	}
+}
@@ -0,0 +1,222 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the known length binary HTTP messages of RFC 9292 that OHTTP carries
+	request:  0||method||scheme||authority||path||fields||content||trailers
+	response: 1||[1xx status||fields]*||status||fields||content||trailers
+every length and number is a QUIC variable length integer, fields are a length then name,value pairs, truncated trailing sections and zero padding
+are read as empty, the indeterminate length forms (2 and 3) are not supported
+*/
+package kyber_ohttp
+
+import(
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const(
+	framing_request=0
+	framing_response=1
+)
+
+var Err_BHTTP=errors.New("kyber_ohttp: malformed binary HTTP message")
+
+func put_varint(out []byte,x uint64)[]byte{
+	switch{
+	case x<1<<6:
+		return append(out,byte(x))
+	case x<1<<14:
+		return append(out,byte(x>>8)|0x40,byte(x))
+	case x<1<<30:
+		return append(out,byte(x>>24)|0x80,byte(x>>16),byte(x>>8),byte(x))
+	}
+	return append(out,byte(x>>56)|0xc0,byte(x>>48),byte(x>>40),byte(x>>32),byte(x>>24),byte(x>>16),byte(x>>8),byte(x))
+}
+
+func put_field(out,field []byte)[]byte{
+	return append(put_varint(out,uint64(len(field))),field...)
+}
+
+func put_fields(out []byte,h http.Header)[]byte{
+	var section []byte
+	for name,values:=range h{
+		for _,v:=range values{
+			section=put_field(put_field(section,[]byte(strings.ToLower(name))),[]byte(v))
+		}
+	}
+	return put_field(out,section)
+}
+
+type bhttp_reader struct{
+	data []byte
+	err error
+}
+
+func (r *bhttp_reader)varint()uint64{
+	if r.err!=nil||len(r.data)==0{
+		r.err=Err_BHTTP
+		return 0
+	}
+	n:=1<<(r.data[0]>>6)
+	if len(r.data)<n{
+		r.err=Err_BHTTP
+		return 0
+	}
+	x:=uint64(r.data[0]&0x3f)
+	for i:=1;i<n;i++{
+		x=x<<8|uint64(r.data[i])
+	}
+	r.data=r.data[n:]
+	return x
+}
+
+func (r *bhttp_reader)field()[]byte{
+	n:=r.varint()
+	if r.err!=nil||uint64(len(r.data))<n{
+		r.err=Err_BHTTP
+		return nil
+	}
+	out:=r.data[:n]
+	r.data=r.data[n:]
+	return out
+}
+
+//a section that was cut off with the rest of the message reads as empty
+func (r *bhttp_reader)truncated()bool{
+	return r.err==nil&&len(bytes.TrimLeft(r.data,"\x00"))==0
+}
+
+func (r *bhttp_reader)fields()http.Header{
+	h:=http.Header{}
+	if r.truncated(){
+		r.data=nil
+		return h
+	}
+	section:=&bhttp_reader{data:r.field()}
+	for r.err==nil&&len(section.data)>0{
+		name,value:=section.field(),section.field()
+		if section.err!=nil{
+			r.err=Err_BHTTP
+			break
+		}
+		h.Add(string(name),string(value))
+	}
+	return h
+}
+
+func (r *bhttp_reader)content()[]byte{
+	if r.truncated(){
+		r.data=nil
+		return nil
+	}
+	return r.field()
+}
+
+func read_body(body io.ReadCloser)([]byte,error){
+	if body==nil{
+		return nil,nil
+	}
+	defer body.Close()
+	//one byte past the limit tells a body that is too large from one that fits exactly
+	data,err:=io.ReadAll(io.LimitReader(body,max_message_len+1))
+	if err!=nil{
+		return nil,err
+	}
+	if len(data)>max_message_len{
+		return nil,Err_Too_Large
+	}
+	return data,nil
+}
+
+func Marshal_Request(req *http.Request)([]byte,error){
+	body,err:=read_body(req.Body)
+	if err!=nil{
+		return nil,err
+	}
+	authority:=req.Host
+	if authority==""{
+		authority=req.URL.Host
+	}
+	scheme:=req.URL.Scheme
+	if scheme==""{
+		scheme="https"
+	}
+	out:=put_varint(nil,framing_request)
+	for _,field:=range []string{req.Method,scheme,authority,req.URL.RequestURI()}{
+		out=put_field(out,[]byte(field))
+	}
+	out=put_fields(out,req.Header)
+	out=put_field(out,body)
+	return put_fields(out,req.Trailer),nil
+}
+
+func Unmarshal_Request(data []byte)(*http.Request,error){
+	r:=&bhttp_reader{data:data}
+	if r.varint()!=framing_request{
+		return nil,Err_BHTTP
+	}
+	method,scheme,authority,path:=string(r.field()),string(r.field()),string(r.field()),string(r.field())
+	header:=r.fields()
+	body:=r.content()
+	trailer:=r.fields()
+	if r.err!=nil||!r.truncated(){
+		return nil,Err_BHTTP
+	}
+	u,err:=url.Parse(scheme+"://"+authority+path)
+	if err!=nil||method==""{
+		return nil,Err_BHTTP
+	}
+	req,err:=http.NewRequest(method,u.String(),bytes.NewReader(body))
+	if err!=nil{
+		return nil,Err_BHTTP
+	}
+	req.Header,req.Trailer,req.Host,req.RequestURI=header,trailer,authority,path
+	return req,nil
+}
+
+func Marshal_Response(resp *http.Response)([]byte,error){
+	body,err:=read_body(resp.Body)
+	if err!=nil{
+		return nil,err
+	}
+	out:=put_varint(nil,framing_response)
+	out=put_varint(out,uint64(resp.StatusCode))
+	out=put_fields(out,resp.Header)
+	out=put_field(out,body)
+	return put_fields(out,resp.Trailer),nil
+}
+
+//informational responses are skipped
+func Unmarshal_Response(data []byte)(*http.Response,error){
+	r:=&bhttp_reader{data:data}
+	if r.varint()!=framing_response{
+		return nil,Err_BHTTP
+	}
+	status:=r.varint()
+	for r.err==nil&&status>=100&&status<200{
+		r.fields()
+		status=r.varint()
+	}
+	header:=r.fields()
+	body:=r.content()
+	trailer:=r.fields()
+	if r.err!=nil||!r.truncated()||status<200||status>599{
+		return nil,Err_BHTTP
+	}
+	return &http.Response{
+		Status:strconv.Itoa(int(status))+" "+http.StatusText(int(status)),
+		StatusCode:int(status),
+		Proto:"HTTP/1.1",ProtoMajor:1,ProtoMinor:1,
+		Header:header,Trailer:trailer,
+		Body:io.NopCloser(bytes.NewReader(body)),
+		ContentLength:int64(len(body)),
+	},nil
+}
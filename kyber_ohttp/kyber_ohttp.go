@@ -0,0 +1,427 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains Oblivious HTTP (RFC 9458) with the kyber HPKE KEMs: key configurations, request and response encapsulation,
+a gateway http.Handler, a client http.RoundTripper and a relay
+
+	key config:        key id(1)||kem id(2)||public key||suites length(2)||(kdf id(2)||aead id(2))*
+	ohttp keys:        (length(2)||key config)*
+	request:           key id(1)||kem id(2)||kdf id(2)||aead id(2)||enc||HPKE seal(info="message/bhttp request"||0||header,binary request)
+	response:          nonce(max(Nn,Nk))||AEAD(key and nonce from HKDF(enc||nonce,export("message/bhttp response")),binary response)
+the relay only sees the encapsulated request and which gateway it goes to, the gateway only sees the request and the relay's address
+*/
+package kyber_ohttp
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hpke"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const(
+	Request_Type="message/ohttp-req"
+	Response_Type="message/ohttp-res"
+	Keys_Type="application/ohttp-keys"
+	request_label="message/bhttp request"
+	response_label="message/bhttp response"
+	header_len=7
+	max_message_len=1<<24
+)
+
+var(
+	Err_Key_Config=errors.New("kyber_ohttp: malformed or unsupported key configuration")
+	Err_Request=errors.New("kyber_ohttp: encapsulated request could not be opened")
+	Err_Response=errors.New("kyber_ohttp: encapsulated response could not be opened")
+	Err_Too_Large=errors.New("kyber_ohttp: message is larger than 16 MiB")
+)
+
+type Symmetric struct{
+	KDF uint16
+	AEAD uint16
+}
+
+type Key_Config struct{
+	Key_ID byte
+	KEM *kyber_hpke.KEM
+	Public_Key kyber_kem.Public_Key
+	Suites []Symmetric
+}
+
+//the gateway side of a key config
+type Gateway_Key struct{
+	Config *Key_Config
+	Private_Key kyber_kem.Private_Key
+}
+
+func Generate_Key(key_id byte,kem *kyber_hpke.KEM,suites ...Symmetric)(*Gateway_Key,error){
+	if len(suites)==0{
+		suites=[]Symmetric{{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_AES_128_GCM}}
+	}
+	sk,err:=kem.Scheme.Keygen()
+	if err!=nil{
+		return nil,err
+	}
+	return &Gateway_Key{&Key_Config{key_id,kem,sk.Public(),suites},sk},nil
+}
+
+func (c *Key_Config)To_Bytes()[]byte{
+	out:=binary.BigEndian.AppendUint16([]byte{c.Key_ID},c.KEM.ID)
+	out=append(out,c.Public_Key.To_Bytes()...)
+	out=binary.BigEndian.AppendUint16(out,uint16(4*len(c.Suites)))
+	for _,s:=range c.Suites{
+		out=binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(out,s.KDF),s.AEAD)
+	}
+	return out
+}
+
+func Bytes_to_Key_Config(data []byte)(*Key_Config,error){
+	if len(data)<3{
+		return nil,Err_Key_Config
+	}
+	kem,err:=kyber_hpke.KEM_by_ID(binary.BigEndian.Uint16(data[1:3]))
+	if err!=nil{
+		return nil,Err_Key_Config
+	}
+	n:=kem.Scheme.Pk_Len()
+	if len(data)<3+n+2{
+		return nil,Err_Key_Config
+	}
+	pk,err:=kem.Scheme.Bytes_to_Pk(data[3:3+n])
+	if err!=nil{
+		return nil,Err_Key_Config
+	}
+	suites:=data[3+n+2:]
+	if int(binary.BigEndian.Uint16(data[3+n:]))!=len(suites)||len(suites)==0||len(suites)%4!=0{
+		return nil,Err_Key_Config
+	}
+	c:=&Key_Config{Key_ID:data[0],KEM:kem,Public_Key:pk}
+	for i:=0;i<len(suites);i+=4{
+		c.Suites=append(c.Suites,Symmetric{binary.BigEndian.Uint16(suites[i:]),binary.BigEndian.Uint16(suites[i+2:])})
+	}
+	return c,nil
+}
+
+func Marshal_Key_Configs(configs []*Key_Config)[]byte{
+	var out []byte
+	for _,c:=range configs{
+		data:=c.To_Bytes()
+		out=append(binary.BigEndian.AppendUint16(out,uint16(len(data))),data...)
+	}
+	return out
+}
+
+//configs with a KEM this package does not know are skipped as RFC 9458 asks of clients
+func Unmarshal_Key_Configs(data []byte)([]*Key_Config,error){
+	var configs []*Key_Config
+	for len(data)>0{
+		if len(data)<2||len(data)-2<int(binary.BigEndian.Uint16(data)){
+			return nil,Err_Key_Config
+		}
+		n:=int(binary.BigEndian.Uint16(data))
+		if n>=3{
+			if _,err:=kyber_hpke.KEM_by_ID(binary.BigEndian.Uint16(data[3:5]));err==nil{
+				c,err:=Bytes_to_Key_Config(data[2:2+n])
+				if err!=nil{
+					return nil,err
+				}
+				configs=append(configs,c)
+			}
+		}
+		data=data[2+n:]
+	}
+	return configs,nil
+}
+
+func (c *Key_Config)suite(s Symmetric)(*kyber_hpke.Suite,error){
+	for _,offered:=range c.Suites{
+		if offered==s{
+			return &kyber_hpke.Suite{KEM:c.KEM,KDF:s.KDF,AEAD:s.AEAD},nil
+		}
+	}
+	return nil,Err_Key_Config
+}
+
+func request_header(key_id byte,kem,kdf,aead uint16)[]byte{
+	out:=binary.BigEndian.AppendUint16([]byte{key_id},kem)
+	return binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(out,kdf),aead)
+}
+
+//the HPKE context of one request, kept to open its response
+type Request_Context struct{
+	suite *kyber_hpke.Suite
+	hpke *kyber_hpke.Context
+	enc []byte
+}
+
+//encapsulates a binary HTTP request with the first suite of the config
+func (c *Key_Config)Encapsulate_Request(request []byte)(encapsulated []byte,rc *Request_Context,err error){
+	if len(c.Suites)==0{
+		return nil,nil,Err_Key_Config
+	}
+	suite,err:=c.suite(c.Suites[0])
+	if err!=nil{
+		return
+	}
+	header:=request_header(c.Key_ID,c.KEM.ID,suite.KDF,suite.AEAD)
+	enc,hpke,err:=suite.Setup_Sender(c.Public_Key,append(append([]byte(request_label),0),header...))
+	if err!=nil{
+		return
+	}
+	ct,err:=hpke.Seal(nil,request)
+	if err!=nil{
+		return
+	}
+	return append(append(header,enc...),ct...),&Request_Context{suite,hpke,enc},nil
+}
+
+func Decapsulate_Request(keys []*Gateway_Key,encapsulated []byte)(request []byte,rc *Request_Context,err error){
+	if len(encapsulated)<header_len{
+		return nil,nil,Err_Request
+	}
+	header:=encapsulated[:header_len]
+	for _,k:=range keys{
+		c:=k.Config
+		if c.Key_ID!=header[0]||c.KEM.ID!=binary.BigEndian.Uint16(header[1:]){
+			continue
+		}
+		suite,err:=c.suite(Symmetric{binary.BigEndian.Uint16(header[3:]),binary.BigEndian.Uint16(header[5:])})
+		if err!=nil{
+			return nil,nil,Err_Request
+		}
+		n:=c.KEM.Scheme.Ct_Len()
+		if len(encapsulated)<header_len+n{
+			return nil,nil,Err_Request
+		}
+		enc:=encapsulated[header_len:header_len+n]
+		hpke,err:=suite.Setup_Receiver(k.Private_Key,enc,append(append([]byte(request_label),0),header...))
+		if err!=nil{
+			return nil,nil,Err_Request
+		}
+		if request,err=hpke.Open(nil,encapsulated[header_len+n:]);err!=nil{
+			return nil,nil,Err_Request
+		}
+		return request,&Request_Context{suite,hpke,enc},nil
+	}
+	return nil,nil,Err_Request
+}
+
+func (rc *Request_Context)response_aead(nonce []byte)(key,aead_nonce []byte,err error){
+	secret,err:=rc.hpke.Export([]byte(response_label),len(nonce))
+	if err!=nil{
+		return
+	}
+	prk:=hkdf.Extract(sha256.New,secret,append(append([]byte(nil),rc.enc...),nonce...))
+	key=make([]byte,rc.suite.N_k())
+	aead_nonce=make([]byte,kyber_hpke.N_n)
+	if _,err=io.ReadFull(hkdf.Expand(sha256.New,prk,[]byte("key")),key);err!=nil{
+		return
+	}
+	_,err=io.ReadFull(hkdf.Expand(sha256.New,prk,[]byte("nonce")),aead_nonce)
+	return
+}
+
+func (rc *Request_Context)Encapsulate_Response(response []byte)([]byte,error){
+	nonce:=make([]byte,max(rc.suite.N_k(),kyber_hpke.N_n))
+	if _,err:=rand.Read(nonce);err!=nil{
+		return nil,err
+	}
+	key,aead_nonce,err:=rc.response_aead(nonce)
+	if err!=nil{
+		return nil,err
+	}
+	aead,err:=rc.suite.New_AEAD(key)
+	if err!=nil{
+		return nil,err
+	}
+	return aead.Seal(nonce,aead_nonce,response,nil),nil
+}
+
+func (rc *Request_Context)Decapsulate_Response(encapsulated []byte)([]byte,error){
+	n:=max(rc.suite.N_k(),kyber_hpke.N_n)
+	if len(encapsulated)<n{
+		return nil,Err_Response
+	}
+	key,aead_nonce,err:=rc.response_aead(encapsulated[:n])
+	if err!=nil{
+		return nil,err
+	}
+	aead,err:=rc.suite.New_AEAD(key)
+	if err!=nil{
+		return nil,err
+	}
+	response,err:=aead.Open(nil,aead_nonce,encapsulated[n:],nil)
+	if err!=nil{
+		return nil,Err_Response
+	}
+	return response,nil
+}
+
+//serves the key configs on GET and encapsulated requests on POST, the inner requests go to Target
+type Gateway struct{
+	Keys []*Gateway_Key
+	Target http.Handler
+}
+
+func (g *Gateway)Key_Configs()[]byte{
+	var configs []*Key_Config
+	for _,k:=range g.Keys{
+		configs=append(configs,k.Config)
+	}
+	return Marshal_Key_Configs(configs)
+}
+
+//collects what Target writes so it can be encapsulated as one binary response
+type response_buffer struct{
+	header http.Header
+	status int
+	body bytes.Buffer
+}
+
+func (b *response_buffer)Header()http.Header{
+	return b.header
+}
+
+func (b *response_buffer)WriteHeader(status int){
+	if b.status==0{
+		b.status=status
+	}
+}
+
+func (b *response_buffer)Write(data []byte)(int,error){
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(data)
+}
+
+func (g *Gateway)ServeHTTP(w http.ResponseWriter,r *http.Request){
+	switch{
+	case r.Method==http.MethodGet:
+		w.Header().Set("Content-Type",Keys_Type)
+		w.Write(g.Key_Configs())
+		return
+	case r.Method!=http.MethodPost:
+		http.Error(w,"method not allowed",http.StatusMethodNotAllowed)
+		return
+	case !strings.HasPrefix(r.Header.Get("Content-Type"),Request_Type):
+		http.Error(w,"unsupported media type",http.StatusUnsupportedMediaType)
+		return
+	}
+	encapsulated,err:=read_body(r.Body)
+	if err!=nil{
+		http.Error(w,"bad request",http.StatusBadRequest)
+		return
+	}
+	request,rc,err:=Decapsulate_Request(g.Keys,encapsulated)
+	if err!=nil{
+		http.Error(w,err.Error(),http.StatusBadRequest)
+		return
+	}
+	inner,err:=Unmarshal_Request(request)
+	if err!=nil{
+		http.Error(w,err.Error(),http.StatusBadRequest)
+		return
+	}
+	inner=inner.WithContext(r.Context())
+	buffer:=&response_buffer{header:http.Header{}}
+	g.Target.ServeHTTP(buffer,inner)
+	if buffer.status==0{
+		buffer.status=http.StatusOK
+	}
+	response,_:=Marshal_Response(&http.Response{StatusCode:buffer.status,Header:buffer.header,Body:io.NopCloser(&buffer.body)})
+	out,err:=rc.Encapsulate_Response(response)
+	if err!=nil{
+		http.Error(w,"internal error",http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type",Response_Type)
+	w.Write(out)
+}
+
+//sends every request through Relay, which may be the gateway itself, encapsulated to Config
+type Transport struct{
+	Config *Key_Config
+	Relay string
+	Base http.RoundTripper//defaults to http.DefaultTransport
+}
+
+func (t *Transport)RoundTrip(req *http.Request)(*http.Response,error){
+	request,err:=Marshal_Request(req)
+	if err!=nil{
+		return nil,err
+	}
+	encapsulated,rc,err:=t.Config.Encapsulate_Request(request)
+	if err!=nil{
+		return nil,err
+	}
+	outer,err:=http.NewRequestWithContext(req.Context(),http.MethodPost,t.Relay,bytes.NewReader(encapsulated))
+	if err!=nil{
+		return nil,err
+	}
+	outer.Header.Set("Content-Type",Request_Type)
+	base:=t.Base
+	if base==nil{
+		base=http.DefaultTransport
+	}
+	resp,err:=base.RoundTrip(outer)
+	if err!=nil{
+		return nil,err
+	}
+	body,err:=read_body(resp.Body)
+	if err!=nil{
+		return nil,err
+	}
+	if resp.StatusCode!=http.StatusOK||resp.Header.Get("Content-Type")!=Response_Type{
+		return nil,errors.New("kyber_ohttp: relay or gateway answered "+resp.Status)
+	}
+	response,err:=rc.Decapsulate_Response(body)
+	if err!=nil{
+		return nil,err
+	}
+	inner,err:=Unmarshal_Response(response)
+	if err!=nil{
+		return nil,err
+	}
+	inner.Request=req
+	return inner,nil
+}
+
+//forwards encapsulated requests to the gateway without any of the client's headers
+func Relay(gateway string,client *http.Client)http.Handler{
+	if client==nil{
+		client=http.DefaultClient
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter,r *http.Request){
+		if r.Method!=http.MethodPost||r.Header.Get("Content-Type")!=Request_Type{
+			http.Error(w,"bad request",http.StatusBadRequest)
+			return
+		}
+		body,err:=read_body(r.Body)
+		if err!=nil{
+			http.Error(w,"bad request",http.StatusBadRequest)
+			return
+		}
+		resp,err:=client.Post(gateway,Request_Type,bytes.NewReader(body))
+		if err!=nil{
+			http.Error(w,"bad gateway",http.StatusBadGateway)
+			return
+		}
+		out,err:=read_body(resp.Body)
+		if err!=nil{
+			http.Error(w,"bad gateway",http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type",resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(out)
+	})
+}
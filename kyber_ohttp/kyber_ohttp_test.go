@@ -0,0 +1,169 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on binary HTTP and oblivious HTTP through a relay and gateway on httptest servers
+*/
+package kyber_ohttp
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hpke"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_BHTTP(t *testing.T){
+	req,_:=http.NewRequest("POST","https://example.com/path?q=1",strings.NewReader("request body"))
+	req.Header.Set("X-Test","value")
+	data,err:=Marshal_Request(req)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,padded:=range [][]byte{data,append(data,0,0,0)}{
+		got,err:=Unmarshal_Request(padded)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		body,_:=io.ReadAll(got.Body)
+		if got.Method!="POST"||got.Host!="example.com"||got.URL.String()!="https://example.com/path?q=1"||got.Header.Get("X-Test")!="value"||string(body)!="request body"{
+			t.Fatal("binary request did not round trip")
+		}
+	}
+	//RFC 9292 section 5 known length GET request, fields and content cut off
+	truncated:=[]byte{0x00,0x03,'G','E','T',0x05,'h','t','t','p','s',0x00,0x0a,'/','h','e','l','l','o','.','t','x','t'}
+	if got,err:=Unmarshal_Request(truncated);err!=nil||got.URL.Path!="/hello.txt"||got.Method!="GET"{
+		t.Fatal("truncated request was not read")
+	}
+	resp:=&http.Response{StatusCode:404,Header:http.Header{"Content-Type":{"text/plain"}},Body:io.NopCloser(strings.NewReader("missing"))}
+	data,_=Marshal_Response(resp)
+	informational:=append(put_fields(put_varint([]byte{framing_response},103),http.Header{"Link":{"</style.css>"}}),data[1:]...)
+	for _,d:=range [][]byte{data,informational}{
+		got,err:=Unmarshal_Response(d)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		body,_:=io.ReadAll(got.Body)
+		if got.StatusCode!=404||got.Header.Get("Content-Type")!="text/plain"||string(body)!="missing"{
+			t.Fatal("binary response did not round trip")
+		}
+	}
+	for _,bad:=range [][]byte{{},{0x02},data[:len(data)-3],append(data,1)}{
+		if _,err:=Unmarshal_Response(bad);err==nil{
+			t.Fatal("malformed binary response was read")
+		}
+	}
+}
+
+func Test_Body_Limit(t *testing.T){
+	if data,err:=read_body(io.NopCloser(bytes.NewReader(make([]byte,max_message_len))));err!=nil||len(data)!=max_message_len{
+		t.Fatal("body at the limit was not read")
+	}
+	req,_:=http.NewRequest("POST","https://example.com/",bytes.NewReader(make([]byte,max_message_len+1)))
+	if _,err:=Marshal_Request(req);err!=Err_Too_Large{
+		t.Fatal("body over the limit was cut off instead of refused")
+	}
+}
+
+func Test_Key_Configs(t *testing.T){
+	a,_:=Generate_Key(1,kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	b,_:=Generate_Key(2,kyber_hpke.KEM_Kyber1024,Symmetric{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_ChaCha20_Poly1305},Symmetric{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_AES_256_GCM})
+	data:=Marshal_Key_Configs([]*Key_Config{a.Config,b.Config})
+	unknown:=[]byte{9,0x00,0x20}//a DHKEM(X25519) config this package can not use
+	unknown=append(append(unknown,make([]byte,32)...),0,4,0,1,0,1)
+	data=append(binary.BigEndian.AppendUint16(data,uint16(len(unknown))),unknown...)
+	configs,err:=Unmarshal_Key_Configs(data)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(configs)!=2||!bytes.Equal(configs[0].To_Bytes(),a.Config.To_Bytes())||!bytes.Equal(configs[1].To_Bytes(),b.Config.To_Bytes())||len(configs[1].Suites)!=2{
+		t.Fatal("key configs did not round trip")
+	}
+	if _,err=Unmarshal_Key_Configs(data[:len(data)-1]);err==nil{
+		t.Fatal("truncated key configs were read")
+	}
+}
+
+func Test_Oblivious_HTTP(t *testing.T){
+	target:=http.HandlerFunc(func(w http.ResponseWriter,r *http.Request){
+		body,_:=io.ReadAll(r.Body)
+		w.Header().Set("X-Seen-Host",r.Host)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("echo: "),body...))
+	})
+	var keys []*Gateway_Key
+	for i,kem:=range kyber_hpke.KEMs{
+		k,err:=Generate_Key(byte(i),kem,Symmetric{kyber_hpke.KDF_HKDF_SHA256,kyber_hpke.AEAD_AES_128_GCM})
+		if err!=nil{
+			t.Fatal(err)
+		}
+		keys=append(keys,k)
+	}
+	gateway:=httptest.NewServer(&Gateway{Keys:keys,Target:target})
+	defer gateway.Close()
+	var relayed int
+	relay_handler:=Relay(gateway.URL,nil)
+	relay:=httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,r *http.Request){
+		relayed++
+		relay_handler.ServeHTTP(w,r)
+	}))
+	defer relay.Close()
+	resp,err:=http.Get(gateway.URL)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	data,_:=io.ReadAll(resp.Body)
+	resp.Body.Close()
+	configs,err:=Unmarshal_Key_Configs(data)
+	if err!=nil||len(configs)!=len(keys)||resp.Header.Get("Content-Type")!=Keys_Type{
+		t.Fatal("gateway did not serve its key configs")
+	}
+	for _,config:=range configs{
+		client:=&http.Client{Transport:&Transport{Config:config,Relay:relay.URL}}
+		resp,err:=client.Post("https://target.example/api","text/plain",strings.NewReader(config.KEM.Scheme.Name()))
+		if err!=nil{
+			t.Fatal(err)
+		}
+		body,_:=io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode!=http.StatusCreated||string(body)!="echo: "+config.KEM.Scheme.Name()||resp.Header.Get("X-Seen-Host")!="target.example"{
+			t.Fatal(config.KEM.Scheme.Name()+" request did not go through the gateway")
+		}
+	}
+	if relayed!=len(configs){
+		t.Fatal("requests did not go through the relay")
+	}
+	//a tampered request is rejected by the gateway and never reaches the target
+	encapsulated,_,_:=configs[0].Encapsulate_Request([]byte{0})
+	encapsulated[len(encapsulated)-1]^=1
+	resp,err=http.Post(gateway.URL,Request_Type,bytes.NewReader(encapsulated))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode!=http.StatusBadRequest{
+		t.Fatal("tampered request was accepted")
+	}
+	other,_:=Generate_Key(0,kyber_hpke.KEM_X25519_Kyber768_Draft00)
+	request,_:=Marshal_Request(httptest.NewRequest("GET","https://target.example/",nil))
+	encapsulated,_,_=other.Config.Encapsulate_Request(request)
+	if _,_,err=Decapsulate_Request(keys,encapsulated);err!=Err_Request{
+		t.Fatal("request to another key was opened")
+	}
+	encapsulated,rc,_:=configs[0].Encapsulate_Request(request)
+	_,gateway_rc,err:=Decapsulate_Request(keys,encapsulated)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	response,_:=gateway_rc.Encapsulate_Response([]byte("response"))
+	response[0]^=1
+	if _,err=rc.Decapsulate_Response(response);err!=Err_Response{
+		t.Fatal("tampered response was opened")
+	}
+}
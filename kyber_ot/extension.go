@@ -0,0 +1,87 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the batch of 1 out of 2 base OTs that seeds an OT extension like IKNP
+the roles swap against the extension, the extension sender picks the choice bits s and learns seed_i^(s_i) while the extension receiver learns both seeds of every pair
+every base OT runs under session||i so a batch is one request and one response
+*/
+package kyber_ot
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/binary"
+)
+
+//number of base OTs an extension with 128 bit security needs
+const Kappa=128
+
+type Seed_Receiver struct{
+	receivers []*Receiver
+}
+
+func batch_session(session []byte,i int)[]byte{
+	out:=binary.BigEndian.AppendUint32(nil,uint32(len(session)))
+	return binary.BigEndian.AppendUint32(append(out,session...),uint32(i))
+}
+
+//starts len(choices) base OTs, the request goes to the party that learns both seeds
+func New_Seed_Receiver(s kyber_kem.Scheme,session []byte,choices []bool)(*Seed_Receiver,[]byte,error){
+	if len(choices)==0{
+		return nil,nil,Err_Choice
+	}
+	r:=&Seed_Receiver{}
+	var request []byte
+	for i,b:=range choices{
+		choice:=0
+		if b{
+			choice=1
+		}
+		receiver,part,err:=New_Receiver(s,batch_session(session,i),2,choice)
+		if err!=nil{
+			return nil,nil,err
+		}
+		r.receivers=append(r.receivers,receiver)
+		request=append(request,part...)
+	}
+	return r,request,nil
+}
+
+//answers a batch request with the seed pairs, seeds[i][b] is seed b of base OT i
+func Respond_Seeds(s kyber_kem.Scheme,session []byte,request []byte)(seeds [][2][]byte,response []byte,err error){
+	if _,err=rank(s);err!=nil{
+		return nil,nil,err
+	}
+	l:=Request_Len(s,2)
+	if len(request)==0||len(request)%l!=0{
+		return nil,nil,Err_Format
+	}
+	for i:=0;i<len(request)/l;i++{
+		keys,part,err:=Respond(s,batch_session(session,i),2,request[i*l:(i+1)*l])
+		if err!=nil{
+			return nil,nil,err
+		}
+		seeds=append(seeds,[2][]byte{keys[0],keys[1]})
+		response=append(response,part...)
+	}
+	return seeds,response,nil
+}
+
+//the chosen seed of every base OT
+func (r *Seed_Receiver)Seeds(response []byte)([][]byte,error){
+	l:=Response_Len(r.receivers[0].scheme,2)
+	if len(response)!=l*len(r.receivers){
+		return nil,Err_Format
+	}
+	var seeds [][]byte
+	for i,receiver:=range r.receivers{
+		seed,err:=receiver.Key(response[i*l:(i+1)*l])
+		if err!=nil{
+			return nil,err
+		}
+		seeds=append(seeds,seed)
+	}
+	return seeds,nil
+}
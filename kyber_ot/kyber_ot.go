@@ -0,0 +1,310 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains 1 out of N oblivious transfer from kyber in the style of Masny and Rindal (ePrint 2019/706)
+the receiver with choice b makes a real key pair (t_b,rho), fills every other slot with a uniform r_j and sets r_b=t_b-H(b,r_-b) so that
+the sender's keys t_j=r_j+H(j,r_-j) all look alike while only t_b has a private key, H is SHAKE128 read into R_q^k by rejection
+	request:   rho(32)||r_0||...||r_(N-1)                  every r_j is 12 bit packed coefficients of k polynomials, all below q
+	response:  c_0||...||c_(N-1)                            kyber ciphertexts to t_0,...,t_(N-1)
+	key_j:     KMAC256(K_j,session||j||c_j,"kyber_ot key",32)
+the sender learns nothing about b since every r_j is uniform mod q (t_b is under MLWE), the receiver can only decapsulate c_b
+this is the semi-honest and random oracle setting of the paper, the session has to be unique per transfer
+*/
+package kyber_ot
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_ops"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	q=3329
+	rho_len=32
+	poly_len=384
+	Key_len=32
+	Max_N=256
+)
+
+var(
+	Err_Scheme=errors.New("kyber_ot: scheme is not a kyber parameter set")
+	Err_Choice=errors.New("kyber_ot: choice is out of range")
+	Err_Format=errors.New("kyber_ot: malformed message")
+	Err_Message=errors.New("kyber_ot: message could not be opened")
+)
+
+type vector [][256]int16
+
+//number of polynomials in t, read off the public key length
+func rank(s kyber_kem.Scheme)(int,error){
+	for _,known:=range kyber_kem.All{
+		if s==known{
+			return (s.Pk_Len()-rho_len)/poly_len,nil
+		}
+	}
+	return 0,Err_Scheme
+}
+
+func encode_poly(f *[256]int16,out []byte){
+	for i:=0;i<256;i+=2{
+		out[3*i/2]=byte(f[i])
+		out[3*i/2+1]=byte(f[i]>>8)|byte(f[i+1]<<4)
+		out[3*i/2+2]=byte(f[i+1]>>4)
+	}
+}
+
+//false for a coefficient that is not below q
+func decode_poly(data []byte,f *[256]int16)bool{
+	for i:=0;i<256;i+=2{
+		f[i]=int16(data[3*i/2])|int16(data[3*i/2+1]&15)<<8
+		f[i+1]=int16(data[3*i/2+1]>>4)|int16(data[3*i/2+2])<<4
+		if f[i]>=q||f[i+1]>=q{
+			return false
+		}
+	}
+	return true
+}
+
+func encode_vector(v vector)[]byte{
+	out:=make([]byte,poly_len*len(v))
+	for i:=range v{
+		encode_poly(&v[i],out[poly_len*i:])
+	}
+	return out
+}
+
+func decode_vector(data []byte,k int)(vector,bool){
+	v:=make(vector,k)
+	for i:=range v{
+		if !decode_poly(data[poly_len*i:],&v[i]){
+			return nil,false
+		}
+	}
+	return v,true
+}
+
+//uniform element of R_q^k read from a SHAKE128 stream by rejection
+func sample(stream sha3.ShakeHash,k int)vector{
+	v:=make(vector,k)
+	for i:=range v{
+		kyber_ops.Parse_shake(&v[i],stream)
+	}
+	return v
+}
+
+//H(j,r_-j) over every slot but j
+func hash(session,rho []byte,r []vector,j int)vector{
+	h:=sha3.NewShake128()
+	h.Write([]byte("kyber_ot hash"))
+	h.Write(binary.BigEndian.AppendUint32(nil,uint32(len(session))))
+	h.Write(session)
+	h.Write(rho)
+	h.Write(binary.BigEndian.AppendUint32(nil,uint32(j)))
+	for i:=range r{
+		if i!=j{
+			h.Write(encode_vector(r[i]))
+		}
+	}
+	return sample(h,len(r[0]))
+}
+
+func add(f,g vector)vector{
+	out:=make(vector,len(f))
+	for i:=range f{
+		kyber_ops.Add_poly(&f[i],&g[i],&out[i])
+		kyber_ops.Mod_poly(&out[i])
+		kyber_ops.CSUBQ_poly(&out[i])
+	}
+	return out
+}
+
+func sub(f,g vector)vector{
+	out:=make(vector,len(f))
+	for i:=range f{
+		kyber_ops.Sub_poly(&f[i],&g[i],&out[i])
+		kyber_ops.Mod_poly(&out[i])
+		kyber_ops.CSUBQ_poly(&out[i])
+	}
+	return out
+}
+
+func derive_key(K,session []byte,j int,c []byte)([]byte,error){
+	context:=binary.BigEndian.AppendUint32(nil,uint32(len(session)))
+	context=append(binary.BigEndian.AppendUint32(append(context,session...),uint32(j)),c...)
+	return kyber_kdf.KMAC256.Derive(K,context,"kyber_ot key",Key_len)
+}
+
+type Receiver struct{
+	scheme kyber_kem.Scheme
+	session []byte
+	n int
+	choice int
+	sk kyber_kem.Private_Key
+}
+
+func Request_Len(s kyber_kem.Scheme,n int)int{
+	return rho_len+n*(s.Pk_Len()-rho_len)
+}
+
+func Response_Len(s kyber_kem.Scheme,n int)int{
+	return n*s.Ct_Len()
+}
+
+//starts a transfer of one of n slots, the request goes to the sender
+func New_Receiver(s kyber_kem.Scheme,session []byte,n,choice int)(*Receiver,[]byte,error){
+	k,err:=rank(s)
+	if err!=nil{
+		return nil,nil,err
+	}
+	if n<2||n>Max_N||choice<0||choice>=n{
+		return nil,nil,Err_Choice
+	}
+	sk,err:=s.Keygen()
+	if err!=nil{
+		return nil,nil,err
+	}
+	pk:=sk.Public().To_Bytes()
+	t,_:=decode_vector(pk,k)
+	rho:=pk[len(pk)-rho_len:]
+	r:=make([]vector,n)
+	for j:=range r{
+		if j==choice{
+			continue
+		}
+		var seed [32]byte
+		if _,err=rand.Read(seed[:]);err!=nil{
+			return nil,nil,err
+		}
+		stream:=sha3.NewShake128()
+		stream.Write(seed[:])
+		r[j]=sample(stream,k)
+	}
+	r[choice]=make(vector,k)//hash skips slot b so its placeholder never counts
+	r[choice]=sub(t,hash(session,rho,r,choice))
+	request:=append([]byte(nil),rho...)
+	for j:=range r{
+		request=append(request,encode_vector(r[j])...)
+	}
+	return &Receiver{s,append([]byte(nil),session...),n,choice,sk},request,nil
+}
+
+func parse_request(s kyber_kem.Scheme,n int,request []byte)(rho []byte,r []vector,err error){
+	k,err:=rank(s)
+	if err!=nil{
+		return nil,nil,err
+	}
+	if n<2||n>Max_N{
+		return nil,nil,Err_Choice
+	}
+	if len(request)!=Request_Len(s,n){
+		return nil,nil,Err_Format
+	}
+	rho,request=request[:rho_len],request[rho_len:]
+	r=make([]vector,n)
+	for j:=range r{
+		var ok bool
+		if r[j],ok=decode_vector(request[j*k*poly_len:],k);!ok{
+			return nil,nil,Err_Format
+		}
+	}
+	return rho,r,nil
+}
+
+//answers a request with a ciphertext to every slot, keys[j] is the key of slot j and the receiver learns only one of them
+func Respond(s kyber_kem.Scheme,session []byte,n int,request []byte)(keys [][]byte,response []byte,err error){
+	rho,r,err:=parse_request(s,n,request)
+	if err!=nil{
+		return nil,nil,err
+	}
+	for j:=range r{
+		t:=add(r[j],hash(session,rho,r,j))
+		pk,err:=s.Bytes_to_Pk(append(encode_vector(t),rho...))
+		if err!=nil{
+			return nil,nil,err
+		}
+		c,K,err:=pk.Enc()
+		if err!=nil{
+			return nil,nil,err
+		}
+		key,err:=derive_key(K,session,j,c)
+		if err!=nil{
+			return nil,nil,err
+		}
+		keys=append(keys,key)
+		response=append(response,c...)
+	}
+	return keys,response,nil
+}
+
+//the key of the chosen slot
+func (r *Receiver)Key(response []byte)([]byte,error){
+	if len(response)<Response_Len(r.scheme,r.n){
+		return nil,Err_Format
+	}
+	n:=r.scheme.Ct_Len()
+	c:=response[r.choice*n:(r.choice+1)*n]
+	K,err:=r.sk.Dec(c)
+	if err!=nil{
+		return nil,err
+	}
+	return derive_key(K,r.session,r.choice,c)
+}
+
+//chosen message transfer, every message is sealed under its slot's key after the response
+func Transfer(s kyber_kem.Scheme,session []byte,request []byte,messages [][]byte)([]byte,error){
+	keys,response,err:=Respond(s,session,len(messages),request)
+	if err!=nil{
+		return nil,err
+	}
+	for j,m:=range messages{
+		aead,err:=chacha20poly1305.New(keys[j])
+		if err!=nil{
+			return nil,err
+		}
+		sealed:=aead.Seal(nil,make([]byte,aead.NonceSize()),m,nil)
+		response=append(binary.BigEndian.AppendUint32(response,uint32(len(sealed))),sealed...)
+	}
+	return response,nil
+}
+
+func (r *Receiver)Message(response []byte)([]byte,error){
+	key,err:=r.Key(response)
+	if err!=nil{
+		return nil,err
+	}
+	sealed:=response[Response_Len(r.scheme,r.n):]
+	var mine []byte
+	for j:=0;j<r.n;j++{
+		if len(sealed)<4{
+			return nil,Err_Format
+		}
+		l:=binary.BigEndian.Uint32(sealed)
+		if uint64(len(sealed)-4)<uint64(l){
+			return nil,Err_Format
+		}
+		if j==r.choice{
+			mine=sealed[4:4+l]
+		}
+		sealed=sealed[4+l:]
+	}
+	if len(sealed)!=0{
+		return nil,Err_Format
+	}
+	aead,err:=chacha20poly1305.New(key)
+	if err!=nil{
+		return nil,err
+	}
+	m,err:=aead.Open(nil,make([]byte,aead.NonceSize()),mine,nil)
+	if err!=nil{
+		return nil,Err_Message
+	}
+	return m,nil
+}
@@ -0,0 +1,192 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on 1 out of 2 and 1 out of N oblivious transfer, the base OT batch and that requests do not show the choice
+*/
+package kyber_ot
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func Test_Transfer(t *testing.T){
+	session:=[]byte("test session")
+	for _,s:=range kyber_kem.All{
+		for _,n:=range []int{2,5}{
+			for b:=0;b<n;b++{
+				r,request,err:=New_Receiver(s,session,n,b)
+				if err!=nil{
+					t.Fatal(err)
+				}
+				if len(request)!=Request_Len(s,n){
+					t.Fatal(s.Name()+" request has the wrong length")
+				}
+				keys,response,err:=Respond(s,session,n,request)
+				if err!=nil{
+					t.Fatal(err)
+				}
+				key,err:=r.Key(response)
+				if err!=nil{
+					t.Fatal(err)
+				}
+				for j:=range keys{
+					if bytes.Equal(key,keys[j])!=(j==b){
+						t.Fatalf("%s %d of %d: receiver key against slot %d",s.Name(),b,n,j)
+					}
+				}
+				if _,response,_=Respond(s,[]byte("other session"),n,request);bytes.Equal(must_key(t,r,response),keys[b]){
+					t.Fatal(s.Name()+" key does not depend on the session")
+				}
+			}
+		}
+	}
+}
+
+func must_key(t *testing.T,r *Receiver,response []byte)[]byte{
+	key,err:=r.Key(response)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return key
+}
+
+func Test_Messages(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	var messages [][]byte
+	for i:=0;i<4;i++{
+		messages=append(messages,[]byte(fmt.Sprintf("message %d",i)))
+	}
+	messages[2]=nil
+	for b:=range messages{
+		r,request,_:=New_Receiver(s,[]byte("messages"),len(messages),b)
+		response,err:=Transfer(s,[]byte("messages"),request,messages)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		m,err:=r.Message(response)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(m,messages[b]){
+			t.Fatalf("received %q for slot %d",m,b)
+		}
+		//the receiver's slot opened against another slot's ciphertext
+		cheat:=*r
+		cheat.choice=(b+1)%len(messages)
+		if _,err=cheat.Message(response);err!=Err_Message{
+			t.Fatal("message opened without its key")
+		}
+		if _,err=r.Message(response[:len(response)-1]);err!=Err_Format{
+			t.Fatal("truncated response was read")
+		}
+	}
+}
+
+func Test_Seeds(t *testing.T){
+	s:=kyber_kem.Kyber_512
+	choices:=make([]bool,Kappa)
+	for i:=range choices{
+		choices[i]=i%3==0
+	}
+	r,request,err:=New_Seed_Receiver(s,[]byte("extension"),choices)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	pairs,response,err:=Respond_Seeds(s,[]byte("extension"),request)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	seeds,err:=r.Seeds(response)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if len(seeds)!=Kappa||len(pairs)!=Kappa{
+		t.Fatal("wrong number of base OTs")
+	}
+	for i,b:=range choices{
+		choice:=0
+		if b{
+			choice=1
+		}
+		if !bytes.Equal(seeds[i],pairs[i][choice])||bytes.Equal(seeds[i],pairs[i][1-choice]){
+			t.Fatalf("base OT %d gave the wrong seed",i)
+		}
+	}
+	if _,_,err=Respond_Seeds(s,[]byte("extension"),request[1:]);err!=Err_Format{
+		t.Fatal("truncated batch was answered")
+	}
+}
+
+func Test_Errors(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	for _,c:=range [][2]int{{1,0},{2,2},{2,-1},{Max_N+1,0}}{
+		if _,_,err:=New_Receiver(s,nil,c[0],c[1]);err!=Err_Choice{
+			t.Fatalf("receiver started with n=%d choice=%d",c[0],c[1])
+		}
+	}
+	_,request,_:=New_Receiver(s,nil,3,1)
+	if _,_,err:=Respond(s,nil,2,request);err!=Err_Format{
+		t.Fatal("request was answered for the wrong n")
+	}
+	//a coefficient of q is not a canonical encoding
+	bad:=append([]byte(nil),request...)
+	bad[rho_len]=byte(q&0xff)
+	bad[rho_len+1]=bad[rho_len+1]&0xf0|byte(q>>8)
+	if _,_,err:=Respond(s,nil,3,bad);err!=Err_Format{
+		t.Fatal("request with a coefficient of q was answered")
+	}
+}
+
+//every slot of a request has to look the same whatever the choice, the r_j are read as coefficients and their spread is compared
+func Test_Hidden_Choice(t *testing.T){
+	s:=kyber_kem.Kyber_512
+	const n,runs,buckets=3,40,16
+	k,_:=rank(s)
+	var counts [n][n][buckets]float64//choice, slot, bucket
+	for b:=0;b<n;b++{
+		var lengths []int
+		for i:=0;i<runs;i++{
+			_,request,err:=New_Receiver(s,[]byte("hidden"),n,b)
+			if err!=nil{
+				t.Fatal(err)
+			}
+			lengths=append(lengths,len(request))
+			_,r,err:=parse_request(s,n,request)
+			if err!=nil{
+				t.Fatal("request of choice "+fmt.Sprint(b)+" is not canonical")
+			}
+			for j:=range r{
+				for p:=0;p<k;p++{
+					for _,c:=range r[j][p]{
+						counts[b][j][int(c)*buckets/q]++
+					}
+				}
+			}
+		}
+		if lengths[0]!=Request_Len(s,n){
+			t.Fatal("request length depends on the choice")
+		}
+	}
+	//chi-square of every slot against uniform mod q, 15 degrees of freedom and a 0.0001 cut off
+	total:=float64(runs*k*256)
+	for b:=0;b<n;b++{
+		for j:=0;j<n;j++{
+			chi:=0.0
+			for x:=0;x<buckets;x++{
+				lo,hi:=(x*q+buckets-1)/buckets,((x+1)*q+buckets-1)/buckets
+				want:=total*float64(hi-lo)/q
+				d:=counts[b][j][x]-want
+				chi+=d*d/want
+			}
+			if chi>44.3{
+				t.Fatalf("choice %d slot %d is not uniform, chi-square %.1f",b,j,chi)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the OCAKE password authenticated key exchange (Beguinet, Chevalier, Pointcheval, Ricosset, Rossi 2023) over kyber
+only the ephemeral public key is encrypted under the password, so the kyber ciphertext needs no uniform encoding unlike CAKE
+
+	message 1 (client->server): apk=E_pw(uniform encoding of pk)
+	message 2 (server->client): c||tag_S        c encapsulates to D_pw(apk)
+	message 3 (client->server): tag_C
+session key,tag_S,tag_C=KMAC256(K,sid||client id||server id||pk||apk||c,"kyber_pake",96)
+apk is uniform under every password and c does not depend on it, so a transcript gives no way to test password guesses offline,
+each guess needs a live run against the other side
+*/
+package kyber_pake
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	Tag_len=32
+	Session_key_len=32
+)
+
+const(
+	state_start=iota
+	state_sent
+	state_done
+)
+
+var(
+	Err_State=errors.New("kyber_pake: message handled out of order")
+	Err_Confirm=errors.New("kyber_pake: key confirmation failed, the password is wrong or the messages were changed")
+	Err_Format=errors.New("kyber_pake: malformed message")
+)
+
+//both sides must agree on every field, Session_ID should be unique per run when it can be
+type Config struct{
+	Scheme kyber_kem.Scheme//defaults to kyber_768
+	Password []byte
+	Session_ID []byte
+	Client_ID,Server_ID []byte
+}
+
+type Client struct{
+	config Config
+	cipher *ideal_cipher
+	sk kyber_kem.Private_Key
+	apk []byte
+	state int
+	Session_Key []byte
+}
+
+type Server struct{
+	config Config
+	cipher *ideal_cipher
+	tag_C []byte
+	key []byte
+	state int
+	Session_Key []byte
+}
+
+func (c *Config)setup()(Config,*ideal_cipher,error){
+	out:=*c
+	if out.Scheme==nil{
+		out.Scheme=kyber_kem.Kyber_768
+	}
+	if _,err:=rank(out.Scheme);err!=nil{
+		return out,nil,err
+	}
+	ic,err:=new_ideal_cipher(out.Password,out.context())
+	return out,ic,err
+}
+
+//the cipher is tweaked by the session and both identities so one encrypted key can not be replayed into another run
+func (c *Config)context()(out []byte){
+	for _,field:=range [][]byte{[]byte(c.Scheme.Name()),c.Session_ID,c.Client_ID,c.Server_ID}{
+		out=binary.BigEndian.AppendUint32(out,uint32(len(field)))
+		out=append(out,field...)
+	}
+	return
+}
+
+func New_Client(config *Config)(*Client,error){
+	c,ic,err:=config.setup()
+	if err!=nil{
+		return nil,err
+	}
+	return &Client{config:c,cipher:ic},nil
+}
+
+func New_Server(config *Config)(*Server,error){
+	c,ic,err:=config.setup()
+	if err!=nil{
+		return nil,err
+	}
+	return &Server{config:c,cipher:ic},nil
+}
+
+func (C *Client)Message_1()(msg []byte,err error){
+	if C.state!=state_start{
+		return nil,Err_State
+	}
+	if C.sk,err=C.config.Scheme.Keygen();err!=nil{
+		return
+	}
+	encoded,err:=Uniform_Encode(C.sk.Public())
+	if err!=nil{
+		return
+	}
+	C.apk=C.cipher.encrypt(encoded)
+	C.state=state_sent
+	return append([]byte(nil),C.apk...),nil
+}
+
+func (S *Server)Message_2(msg1 []byte)(msg []byte,err error){
+	if S.state!=state_start{
+		return nil,Err_State
+	}
+	n,_:=Encoded_Len(S.config.Scheme)
+	if len(msg1)!=n{
+		return nil,Err_Format
+	}
+	pk,err:=Uniform_Decode(S.config.Scheme,S.cipher.decrypt(msg1))
+	if err!=nil{
+		return
+	}
+	c,K,err:=pk.Enc()
+	if err!=nil{
+		return
+	}
+	keys,err:=derive(&S.config,K,pk,msg1,c)
+	if err!=nil{
+		return
+	}
+	S.key=keys[:Session_key_len]//only released once the client proves it derived the same key
+	S.tag_C=keys[Session_key_len+Tag_len:]
+	S.state=state_sent
+	return append(c,keys[Session_key_len:Session_key_len+Tag_len]...),nil
+}
+
+func (C *Client)Finish(msg2 []byte)(msg3 []byte,err error){
+	if C.state!=state_sent{
+		return nil,Err_State
+	}
+	ct_len:=C.config.Scheme.Ct_Len()
+	if len(msg2)!=ct_len+Tag_len{
+		return nil,Err_Format
+	}
+	K,err:=C.sk.Dec(msg2[:ct_len])
+	if err!=nil{
+		return
+	}
+	keys,err:=derive(&C.config,K,C.sk.Public(),C.apk,msg2[:ct_len])
+	if err!=nil{
+		return
+	}
+	C.state=state_done
+	if subtle.ConstantTimeCompare(keys[Session_key_len:Session_key_len+Tag_len],msg2[ct_len:])!=1{
+		return nil,Err_Confirm
+	}
+	C.Session_Key=keys[:Session_key_len]
+	return keys[Session_key_len+Tag_len:],nil
+}
+
+func (S *Server)Finish(msg3 []byte)error{
+	if S.state!=state_sent{
+		return Err_State
+	}
+	S.state=state_done
+	if subtle.ConstantTimeCompare(S.tag_C,msg3)!=1{
+		return Err_Confirm
+	}
+	S.Session_Key=S.key
+	return nil
+}
+
+func derive(c *Config,K []byte,pk kyber_kem.Public_Key,apk,ct []byte)([]byte,error){
+	transcript:=c.context()
+	for _,field:=range [][]byte{pk.To_Bytes(),apk,ct}{
+		transcript=binary.BigEndian.AppendUint32(transcript,uint32(len(field)))
+		transcript=append(transcript,field...)
+	}
+	return kyber_kdf.KMAC256.Derive(K,transcript,"kyber_pake",Session_key_len+2*Tag_len)
+}
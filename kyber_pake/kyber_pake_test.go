@@ -0,0 +1,157 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the OCAKE password authenticated key exchange and the uniform public key encoding
+*/
+package kyber_pake
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/rand"
+	"bytes"
+	"math"
+	"testing"
+)
+
+func run(t *testing.T,client_config,server_config *Config)(C *Client,S *Server,transcript [][]byte,client_err,server_err error){
+	C,err:=New_Client(client_config)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	S,err=New_Server(server_config)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	msg1,err:=C.Message_1()
+	if err!=nil{
+		t.Fatal(err)
+	}
+	msg2,err:=S.Message_2(msg1)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	transcript=[][]byte{msg1,msg2}
+	msg3,client_err:=C.Finish(msg2)
+	if client_err!=nil{
+		return
+	}
+	transcript=append(transcript,msg3)
+	server_err=S.Finish(msg3)
+	return
+}
+
+func Test_Exchange(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		config:=&Config{Scheme:s,Password:[]byte("correct horse"),Session_ID:[]byte("sid"),Client_ID:[]byte("phone"),Server_ID:[]byte("laptop")}
+		C,S,_,client_err,server_err:=run(t,config,config)
+		if client_err!=nil||server_err!=nil{
+			t.Fatal(s.Name()+" exchange failed")
+		}
+		if len(C.Session_Key)!=Session_key_len||!bytes.Equal(C.Session_Key,S.Session_Key){
+			t.Fatal(s.Name()+" session keys do not match")
+		}
+		if _,err:=C.Message_1();err!=Err_State{
+			t.Fatal("client state machine restarted")
+		}
+	}
+}
+
+func Test_Wrong_Password(t *testing.T){
+	client:=&Config{Password:[]byte("correct horse"),Session_ID:[]byte("sid")}
+	server:=&Config{Password:[]byte("correct horsf"),Session_ID:[]byte("sid")}
+	C,S,transcript,client_err,_:=run(t,client,server)
+	if client_err!=Err_Confirm||C.Session_Key!=nil||S.Session_Key!=nil{
+		t.Fatal("wrong password was accepted")
+	}
+	if len(transcript)!=2{
+		t.Fatal("client answered after failing")
+	}
+	//a server never releases its key to a client that sends a guessed confirmation tag
+	S,_=New_Server(client)
+	S.Message_2(transcript[0])
+	if S.Finish(make([]byte,Tag_len))!=Err_Confirm||S.Session_Key!=nil{
+		t.Fatal("server accepted a guessed confirmation tag")
+	}
+	if S.Finish(make([]byte,Tag_len))!=Err_State{
+		t.Fatal("server allowed a second guess")
+	}
+	other_session:=&Config{Password:[]byte("correct horse"),Session_ID:[]byte("other sid")}
+	if _,_,_,client_err,_=run(t,client,other_session);client_err!=Err_Confirm{
+		t.Fatal("sessions with different ids agreed on a key")
+	}
+}
+
+//an eavesdropper who decrypts message 1 under every guess always gets a valid public key, so no guess is ruled out by the format
+func Test_No_Offline_Test(t *testing.T){
+	config:=&Config{Password:[]byte("hunter2"),Session_ID:[]byte("sid")}
+	_,_,transcript,_,_:=run(t,config,config)
+	for _,guess:=range []string{"hunter2","hunter3","password","123456","letmein",""}{
+		c:=*config
+		c.Password=[]byte(guess)
+		_,ic,_:=c.setup()
+		if _,err:=Uniform_Decode(kyber_kem.Kyber_768,ic.decrypt(transcript[0]));err!=nil{
+			t.Fatal("a password guess was ruled out by the format of message 1")
+		}
+	}
+	n,_:=Encoded_Len(kyber_kem.Kyber_768)
+	for i:=0;i<50;i++{
+		random:=make([]byte,n)
+		rand.Read(random)
+		if _,err:=Uniform_Decode(kyber_kem.Kyber_768,random);err!=nil{
+			t.Fatal("a random string did not decode to a public key")
+		}
+	}
+}
+
+func Test_Uniform_Encoding(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		sk,_:=s.Keygen()
+		encoded,err:=Uniform_Encode(sk.Public())
+		if err!=nil{
+			t.Fatal(err)
+		}
+		decoded,err:=Uniform_Decode(s,encoded)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded.To_Bytes(),sk.Public().To_Bytes()){
+			t.Fatal(s.Name()+" encoding did not round trip")
+		}
+	}
+	//the plain 12 bit encoding never has the top nibble of a coefficient above 0xC, the uniform one has every value of the leading byte
+	var counts [256]float64
+	samples:=512
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	for i:=0;i<samples;i++{
+		encoded,_:=Uniform_Encode(sk.Public())
+		counts[encoded[0]]++
+		counts[encoded[len(encoded)/2]]++
+	}
+	chi:=0.0
+	expected:=float64(2*samples)/256
+	for _,c:=range counts{
+		chi+=(c-expected)*(c-expected)/expected
+	}
+	if chi>255+6*math.Sqrt(2*255){
+		t.Fatal("uniform encoding bytes are not uniform")
+	}
+}
+
+func Test_Ideal_Cipher(t *testing.T){
+	ic,_:=new_ideal_cipher([]byte("pw"),[]byte("context"))
+	other,_:=new_ideal_cipher([]byte("pw2"),[]byte("context"))
+	for _,n:=range []int{1,2,33,1201}{
+		data:=make([]byte,n)
+		rand.Read(data)
+		c:=ic.encrypt(data)
+		if !bytes.Equal(ic.decrypt(c),data){
+			t.Fatal("ideal cipher did not round trip")
+		}
+		if n>1&&(bytes.Equal(c,data)||bytes.Equal(other.decrypt(c),data)){
+			t.Fatal("ideal cipher does not depend on the password")
+		}
+	}
+}
@@ -0,0 +1,207 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the uniform encoding of kyber public keys and the ideal cipher that encrypts them under the password
+t is read as the base q integer v=sum t_i*q^i<q^(256k) and sent as v+r*q^(256k) for a random r, padded so the byte string is within 2^-128 of uniform
+every byte string of the right length decodes to a valid public key, so decrypting under a wrong password can never be told apart by its format
+the ideal cipher is a 14 round Feistel network over the whole encoding with SHAKE256 round functions keyed by the password and session
+*/
+package kyber_pake
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_ops"
+	"golang.org/x/crypto/sha3"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+const(
+	q=3329
+	rho_len=32
+	security_bits=128
+	feistel_rounds=14
+)
+
+var Err_Scheme=errors.New("kyber_pake: scheme is not a kyber parameter set")
+
+//number of polynomials in t, read off the public key length
+func rank(s kyber_kem.Scheme)(int,error){
+	for _,known:=range kyber_kem.All{
+		if s==known{
+			return (s.Pk_Len()-rho_len)/384,nil
+		}
+	}
+	return 0,Err_Scheme
+}
+
+func modulus(k int)*big.Int{
+	return new(big.Int).Exp(big.NewInt(q),big.NewInt(int64(256*k)),nil)
+}
+
+//length of the uniform encoding in bytes, including rho
+func Encoded_Len(s kyber_kem.Scheme)(int,error){
+	k,err:=rank(s)
+	if err!=nil{
+		return 0,err
+	}
+	return (modulus(k).BitLen()+security_bits+7)/8+rho_len,nil
+}
+
+func decode_t(t []byte,k int)(coeffs []int16){
+	switch k{
+	case kyber_ops.K_512:
+		var f [kyber_ops.K_512][256]int16
+		kyber_ops.Decode_12(t,&f)
+		for i:=range f{
+			coeffs=append(coeffs,f[i][:]...)
+		}
+	case kyber_ops.K_768:
+		var f [kyber_ops.K_768][256]int16
+		kyber_ops.Decode_12(t,&f)
+		for i:=range f{
+			coeffs=append(coeffs,f[i][:]...)
+		}
+	case kyber_ops.K_1024:
+		var f [kyber_ops.K_1024][256]int16
+		kyber_ops.Decode_12(t,&f)
+		for i:=range f{
+			coeffs=append(coeffs,f[i][:]...)
+		}
+	}
+	return
+}
+
+func encode_t(coeffs []int16,k int)[]byte{
+	t:=make([]byte,384*k)
+	switch k{
+	case kyber_ops.K_512:
+		var f [kyber_ops.K_512][256]int16
+		for i:=range coeffs{
+			f[i/256][i%256]=coeffs[i]
+		}
+		kyber_ops.Encode_12(&f,t)
+	case kyber_ops.K_768:
+		var f [kyber_ops.K_768][256]int16
+		for i:=range coeffs{
+			f[i/256][i%256]=coeffs[i]
+		}
+		kyber_ops.Encode_12(&f,t)
+	case kyber_ops.K_1024:
+		var f [kyber_ops.K_1024][256]int16
+		for i:=range coeffs{
+			f[i/256][i%256]=coeffs[i]
+		}
+		kyber_ops.Encode_12(&f,t)
+	}
+	return t
+}
+
+func Uniform_Encode(pk kyber_kem.Public_Key)([]byte,error){
+	k,err:=rank(pk.Scheme())
+	if err!=nil{
+		return nil,err
+	}
+	pk_bytes:=pk.To_Bytes()
+	coeffs:=decode_t(pk_bytes[:384*k],k)
+	v:=new(big.Int)
+	Q:=big.NewInt(q)
+	for i:=len(coeffs)-1;i>=0;i--{
+		if coeffs[i]<0||coeffs[i]>=q{
+			return nil,errors.New("kyber_pake: public key coefficient is not reduced")
+		}
+		v.Mul(v,Q)
+		v.Add(v,big.NewInt(int64(coeffs[i])))
+	}
+	n,_:=Encoded_Len(pk.Scheme())
+	m:=modulus(k)
+	bound:=new(big.Int).Lsh(big.NewInt(1),uint(8*(n-rho_len)))
+	bound.Div(bound,m)
+	r,err:=rand.Int(rand.Reader,bound)
+	if err!=nil{
+		return nil,err
+	}
+	v.Add(v,r.Mul(r,m))
+	out:=make([]byte,n)
+	v.FillBytes(out[:n-rho_len])
+	copy(out[n-rho_len:],pk_bytes[384*k:])
+	return out,nil
+}
+
+//any string of Encoded_Len bytes gives a public key
+func Uniform_Decode(s kyber_kem.Scheme,data []byte)(kyber_kem.Public_Key,error){
+	k,err:=rank(s)
+	if err!=nil{
+		return nil,err
+	}
+	n,_:=Encoded_Len(s)
+	if len(data)!=n{
+		return nil,errors.New("kyber_pake: encoded public key has the wrong length")
+	}
+	v:=new(big.Int).SetBytes(data[:n-rho_len])
+	v.Mod(v,modulus(k))
+	Q:=big.NewInt(q)
+	digit:=new(big.Int)
+	coeffs:=make([]int16,256*k)
+	for i:=range coeffs{
+		v.DivMod(v,Q,digit)
+		coeffs[i]=int16(digit.Int64())
+	}
+	return s.Bytes_to_Pk(append(encode_t(coeffs,k),data[n-rho_len:]...))
+}
+
+type ideal_cipher struct{
+	key []byte
+}
+
+func new_ideal_cipher(password,context []byte)(*ideal_cipher,error){
+	key,err:=kyber_kdf.KMAC256.Derive(password,context,"kyber_pake ideal cipher",64)
+	if err!=nil{
+		return nil,err
+	}
+	return &ideal_cipher{key},nil
+}
+
+func (c *ideal_cipher)round(i int,in,out []byte){
+	h:=sha3.NewShake256()
+	h.Write(c.key)
+	h.Write([]byte{byte(i)})
+	h.Write(in)
+	mask:=make([]byte,len(out))
+	h.Read(mask)
+	for j:=range out{
+		out[j]^=mask[j]
+	}
+}
+
+//even rounds mask the right half with the left half and odd rounds the other way around
+func (c *ideal_cipher)encrypt(plaintext []byte)[]byte{
+	out:=append([]byte(nil),plaintext...)
+	a,b:=out[:len(out)/2],out[len(out)/2:]
+	for i:=0;i<feistel_rounds;i++{
+		if i%2==0{
+			c.round(i,a,b)
+		}else{
+			c.round(i,b,a)
+		}
+	}
+	return out
+}
+
+func (c *ideal_cipher)decrypt(ciphertext []byte)[]byte{
+	out:=append([]byte(nil),ciphertext...)
+	a,b:=out[:len(out)/2],out[len(out)/2:]
+	for i:=feistel_rounds-1;i>=0;i--{
+		if i%2==0{
+			c.round(i,a,b)
+		}else{
+			c.round(i,b,a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,469 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the PQXDH asynchronous key agreement protocol from Signal using X25519 and kyber_1024
+Identity keys are an Ed25519 signing key paired with an X25519 key instead of a single XEdDSA key
+SK=HKDF-SHA512(0xFF*32||DH1||DH2||DH3||[DH4]||SS) where
+	DH1=DH(IK_A,SPK_B) DH2=DH(EK_A,IK_B) DH3=DH(EK_A,SPK_B) DH4=DH(EK_A,OPK_B) SS=kyber_1024 shared key for PQPK_B
+*/
+package kyber_pqxdh
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const(
+	info="PQXDH_CURVE25519_SHA-512_CRYSTALS-KYBER-1024"
+	key_type_x25519=0x05
+	key_type_kyber_1024=0x08
+	Identity_len=ed25519.PublicKeySize+32
+	Shared_key_len=32
+)
+
+var kem=kyber_kem.Kyber_1024
+
+var(
+	Err_Signature=errors.New("kyber_pqxdh: prekey signature is invalid")
+	Err_Unknown_Prekey=errors.New("kyber_pqxdh: prekey is unknown or was already used")
+	Err_Format=errors.New("kyber_pqxdh: malformed message")
+)
+
+type Identity_Key struct{
+	Sign ed25519.PrivateKey
+	DH *ecdh.PrivateKey
+}
+
+type Identity struct{
+	Sign ed25519.PublicKey
+	DH *ecdh.PublicKey
+}
+
+type Bundle struct{
+	Identity Identity
+	Signed_Prekey_ID uint32
+	Signed_Prekey *ecdh.PublicKey
+	Signed_Prekey_Sig []byte
+	Kyber_Prekey_ID uint32
+	Kyber_Prekey kyber_kem.Public_Key
+	Kyber_Prekey_Sig []byte
+	One_Time_Prekey_ID uint32
+	One_Time_Prekey *ecdh.PublicKey//nil when the responder has run out of one-time prekeys
+}
+
+type Initial_Message struct{
+	Identity Identity
+	Ephemeral *ecdh.PublicKey
+	Kyber_Ciphertext []byte
+	Signed_Prekey_ID,Kyber_Prekey_ID,One_Time_Prekey_ID uint32
+	Has_One_Time_Prekey bool
+	Ciphertext []byte
+}
+
+type kyber_prekey struct{
+	sk kyber_kem.Private_Key
+	sig []byte
+	last_resort bool
+}
+
+type Prekey_Store struct{
+	Identity *Identity_Key
+	signed_id uint32
+	signed *ecdh.PrivateKey
+	signed_sig []byte
+	kyber map[uint32]*kyber_prekey
+	one_time map[uint32]*ecdh.PrivateKey
+	unpublished_kyber,unpublished_one_time []uint32
+	last_resort_id,next_id uint32
+}
+
+func New_Identity_Key()(*Identity_Key,error){
+	_,sign,err:=ed25519.GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	dh,err:=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return nil,err
+	}
+	return &Identity_Key{sign,dh},nil
+}
+
+func (ik *Identity_Key)Public()Identity{
+	return Identity{ik.Sign.Public().(ed25519.PublicKey),ik.DH.PublicKey()}
+}
+
+func (id Identity)To_Bytes()[]byte{
+	return append(append([]byte(nil),id.Sign...),id.DH.Bytes()...)
+}
+
+func Bytes_to_Identity(data []byte)(id Identity,err error){
+	if len(data)!=Identity_len{
+		err=Err_Format
+		return
+	}
+	id.Sign=append(ed25519.PublicKey(nil),data[:ed25519.PublicKeySize]...)
+	id.DH,err=ecdh.X25519().NewPublicKey(data[ed25519.PublicKeySize:])
+	return
+}
+
+func encode_x25519(pk *ecdh.PublicKey)[]byte{
+	return append([]byte{key_type_x25519},pk.Bytes()...)
+}
+
+func encode_kyber(pk kyber_kem.Public_Key)[]byte{
+	return append([]byte{key_type_kyber_1024},pk.To_Bytes()...)
+}
+
+//creates a signed prekey and a signed last-resort kyber prekey
+func New_Prekey_Store(ik *Identity_Key)(*Prekey_Store,error){
+	var err error
+	s:=&Prekey_Store{Identity:ik,kyber:map[uint32]*kyber_prekey{},one_time:map[uint32]*ecdh.PrivateKey{},next_id:1}
+	if err=s.Rotate_Signed_Prekey();err!=nil{
+		return nil,err
+	}
+	s.last_resort_id,err=s.new_kyber_prekey(true)
+	if err!=nil{
+		return nil,err
+	}
+	return s,nil
+}
+
+func (s *Prekey_Store)Rotate_Signed_Prekey()(err error){
+	s.signed,err=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return
+	}
+	s.signed_id=s.next_id
+	s.next_id++
+	s.signed_sig=ed25519.Sign(s.Identity.Sign,encode_x25519(s.signed.PublicKey()))
+	return
+}
+
+func (s *Prekey_Store)new_kyber_prekey(last_resort bool)(id uint32,err error){
+	sk,err:=kem.Keygen()
+	if err!=nil{
+		return
+	}
+	id=s.next_id
+	s.next_id++
+	s.kyber[id]=&kyber_prekey{sk,ed25519.Sign(s.Identity.Sign,encode_kyber(sk.Public())),last_resort}
+	return
+}
+
+//adds n one-time X25519 prekeys and n one-time signed kyber prekeys
+func (s *Prekey_Store)Generate_One_Time_Prekeys(n int)error{
+	for i:=0;i<n;i++{
+		id,err:=s.new_kyber_prekey(false)
+		if err!=nil{
+			return err
+		}
+		s.unpublished_kyber=append(s.unpublished_kyber,id)
+		opk,err:=ecdh.X25519().GenerateKey(rand.Reader)
+		if err!=nil{
+			return err
+		}
+		s.one_time[s.next_id]=opk
+		s.unpublished_one_time=append(s.unpublished_one_time,s.next_id)
+		s.next_id++
+	}
+	return nil
+}
+
+//each one-time prekey is handed out in at most one bundle, the last-resort kyber prekey is used once they run out
+func (s *Prekey_Store)Bundle()*Bundle{
+	b:=&Bundle{Identity:s.Identity.Public(),Signed_Prekey_ID:s.signed_id,Signed_Prekey:s.signed.PublicKey(),Signed_Prekey_Sig:s.signed_sig}
+	b.Kyber_Prekey_ID=s.last_resort_id
+	if len(s.unpublished_kyber)>0{
+		b.Kyber_Prekey_ID=s.unpublished_kyber[0]
+		s.unpublished_kyber=s.unpublished_kyber[1:]
+	}
+	pq:=s.kyber[b.Kyber_Prekey_ID]
+	b.Kyber_Prekey=pq.sk.Public()
+	b.Kyber_Prekey_Sig=pq.sig
+	if len(s.unpublished_one_time)>0{
+		b.One_Time_Prekey_ID=s.unpublished_one_time[0]
+		b.One_Time_Prekey=s.one_time[b.One_Time_Prekey_ID].PublicKey()
+		s.unpublished_one_time=s.unpublished_one_time[1:]
+	}
+	return b
+}
+
+func (b *Bundle)Verify()error{
+	if len(b.Identity.Sign)!=ed25519.PublicKeySize||b.Identity.DH==nil||b.Signed_Prekey==nil||b.Kyber_Prekey==nil{
+		return Err_Format
+	}
+	if !ed25519.Verify(b.Identity.Sign,encode_x25519(b.Signed_Prekey),b.Signed_Prekey_Sig){
+		return Err_Signature
+	}
+	if !ed25519.Verify(b.Identity.Sign,encode_kyber(b.Kyber_Prekey),b.Kyber_Prekey_Sig){
+		return Err_Signature
+	}
+	return nil
+}
+
+//Alice verifies the bundle from Bob, derives SK and encrypts the first message to Bob
+func Initiate(ik *Identity_Key,b *Bundle,plaintext []byte)(msg *Initial_Message,SK []byte,err error){
+	if err=b.Verify();err!=nil{
+		return
+	}
+	ek,err:=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return
+	}
+	ct,ss,err:=b.Kyber_Prekey.Enc()
+	if err!=nil{
+		return
+	}
+	dh,err:=dh_concat([4]*ecdh.PrivateKey{ik.DH,ek,ek,ek},[4]*ecdh.PublicKey{b.Signed_Prekey,b.Identity.DH,b.Signed_Prekey,b.One_Time_Prekey},b.One_Time_Prekey!=nil)
+	if err!=nil{
+		return
+	}
+	SK,err=kdf(dh,ss)
+	if err!=nil{
+		return
+	}
+	msg=&Initial_Message{Identity:ik.Public(),Ephemeral:ek.PublicKey(),Kyber_Ciphertext:ct,
+		Signed_Prekey_ID:b.Signed_Prekey_ID,Kyber_Prekey_ID:b.Kyber_Prekey_ID,One_Time_Prekey_ID:b.One_Time_Prekey_ID,Has_One_Time_Prekey:b.One_Time_Prekey!=nil}
+	msg.Ciphertext,err=seal(SK,ad(msg.Identity,b.Identity),plaintext)
+	return
+}
+
+//Bob mirrors the computation, one-time prekeys are only deleted once the message decrypts so forged messages naming public prekey ids can not use them up
+func (s *Prekey_Store)Respond(msg *Initial_Message)(SK,plaintext []byte,err error){
+	if len(msg.Identity.Sign)!=ed25519.PublicKeySize||msg.Identity.DH==nil||msg.Ephemeral==nil{
+		return nil,nil,Err_Format
+	}
+	if msg.Signed_Prekey_ID!=s.signed_id{
+		return nil,nil,Err_Unknown_Prekey
+	}
+	pq,ok:=s.kyber[msg.Kyber_Prekey_ID]
+	if !ok{
+		return nil,nil,Err_Unknown_Prekey
+	}
+	var opk *ecdh.PrivateKey
+	if msg.Has_One_Time_Prekey{
+		if opk,ok=s.one_time[msg.One_Time_Prekey_ID];!ok{
+			return nil,nil,Err_Unknown_Prekey
+		}
+	}
+	ss,err:=pq.sk.Dec(msg.Kyber_Ciphertext)
+	if err!=nil{
+		return
+	}
+	dh,err:=dh_concat([4]*ecdh.PrivateKey{s.signed,s.Identity.DH,s.signed,opk},[4]*ecdh.PublicKey{msg.Identity.DH,msg.Ephemeral,msg.Ephemeral,msg.Ephemeral},msg.Has_One_Time_Prekey)
+	if err!=nil{
+		return
+	}
+	SK,err=kdf(dh,ss)
+	if err!=nil{
+		return
+	}
+	plaintext,err=open(SK,ad(msg.Identity,s.Identity.Public()),msg.Ciphertext)
+	if err!=nil{
+		return nil,nil,err
+	}
+	if msg.Has_One_Time_Prekey{
+		delete(s.one_time,msg.One_Time_Prekey_ID)
+	}
+	if !pq.last_resort{
+		delete(s.kyber,msg.Kyber_Prekey_ID)
+	}
+	return
+}
+
+//DH1 to DH3 always, DH4 only when there is a one-time prekey, a missing key in any pair that is used is an error
+func dh_concat(sk [4]*ecdh.PrivateKey,pk [4]*ecdh.PublicKey,one_time bool)(dh []byte,err error){
+	n:=3
+	if one_time{
+		n=4
+	}
+	for i:=0;i<n;i++{
+		if sk[i]==nil||pk[i]==nil{
+			return nil,Err_Format
+		}
+		out,err:=sk[i].ECDH(pk[i])
+		if err!=nil{
+			return nil,err
+		}
+		dh=append(dh,out...)
+	}
+	return
+}
+
+func kdf(dh,ss []byte)([]byte,error){
+	F:=make([]byte,32,32+len(dh)+len(ss))
+	for i:=range F{
+		F[i]=0xFF
+	}
+	ikm:=append(append(F,dh...),ss...)
+	SK:=make([]byte,Shared_key_len)
+	_,err:=io.ReadFull(hkdf.New(sha512.New,ikm,make([]byte,sha512.Size),[]byte(info)),SK)
+	return SK,err
+}
+
+func ad(initiator,responder Identity)[]byte{
+	return append(initiator.To_Bytes(),responder.To_Bytes()...)
+}
+
+//SK is used once so the AEAD nonce is fixed at zero
+func seal(SK,ad,plaintext []byte)([]byte,error){
+	aead,err:=chacha20poly1305.New(SK)
+	if err!=nil{
+		return nil,err
+	}
+	return aead.Seal(nil,make([]byte,aead.NonceSize()),plaintext,ad),nil
+}
+
+func open(SK,ad,ciphertext []byte)([]byte,error){
+	aead,err:=chacha20poly1305.New(SK)
+	if err!=nil{
+		return nil,err
+	}
+	return aead.Open(nil,make([]byte,aead.NonceSize()),ciphertext,ad)
+}
+
+func put_bytes(data,field []byte)[]byte{
+	data=binary.BigEndian.AppendUint32(data,uint32(len(field)))
+	return append(data,field...)
+}
+
+type reader struct{
+	data []byte
+	err error
+}
+
+func (r *reader)uint32()(x uint32){
+	if r.err!=nil||len(r.data)<4{
+		r.err=Err_Format
+		return
+	}
+	x=binary.BigEndian.Uint32(r.data)
+	r.data=r.data[4:]
+	return
+}
+
+func (r *reader)bytes()(field []byte){
+	n:=r.uint32()
+	if r.err!=nil||uint64(len(r.data))<uint64(n){
+		r.err=Err_Format
+		return
+	}
+	field=r.data[:n]
+	r.data=r.data[n:]
+	return
+}
+
+func (b *Bundle)To_Bytes()(data []byte){
+	data=put_bytes(data,b.Identity.To_Bytes())
+	data=binary.BigEndian.AppendUint32(data,b.Signed_Prekey_ID)
+	data=put_bytes(data,encode_x25519(b.Signed_Prekey))
+	data=put_bytes(data,b.Signed_Prekey_Sig)
+	data=binary.BigEndian.AppendUint32(data,b.Kyber_Prekey_ID)
+	data=put_bytes(data,encode_kyber(b.Kyber_Prekey))
+	data=put_bytes(data,b.Kyber_Prekey_Sig)
+	if b.One_Time_Prekey!=nil{
+		data=binary.BigEndian.AppendUint32(data,b.One_Time_Prekey_ID)
+		data=put_bytes(data,encode_x25519(b.One_Time_Prekey))
+	}
+	return
+}
+
+func Bytes_to_Bundle(data []byte)(b *Bundle,err error){
+	r:=&reader{data:data}
+	b=new(Bundle)
+	id:=r.bytes()
+	b.Signed_Prekey_ID=r.uint32()
+	spk:=r.bytes()
+	b.Signed_Prekey_Sig=r.bytes()
+	b.Kyber_Prekey_ID=r.uint32()
+	pqpk:=r.bytes()
+	b.Kyber_Prekey_Sig=r.bytes()
+	var opk []byte
+	if r.err==nil&&len(r.data)>0{
+		b.One_Time_Prekey_ID=r.uint32()
+		opk=r.bytes()
+	}
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	if b.Identity,err=Bytes_to_Identity(id);err!=nil{
+		return nil,err
+	}
+	if b.Signed_Prekey,err=decode_x25519(spk);err!=nil{
+		return nil,err
+	}
+	if len(pqpk)<1||pqpk[0]!=key_type_kyber_1024{
+		return nil,Err_Format
+	}
+	if b.Kyber_Prekey,err=kem.Bytes_to_Pk(pqpk[1:]);err!=nil{
+		return nil,err
+	}
+	if opk!=nil{
+		if b.One_Time_Prekey,err=decode_x25519(opk);err!=nil{
+			return nil,err
+		}
+	}
+	return
+}
+
+func decode_x25519(data []byte)(*ecdh.PublicKey,error){
+	if len(data)<1||data[0]!=key_type_x25519{
+		return nil,Err_Format
+	}
+	return ecdh.X25519().NewPublicKey(data[1:])
+}
+
+func (m *Initial_Message)To_Bytes()(data []byte){
+	data=put_bytes(data,m.Identity.To_Bytes())
+	data=put_bytes(data,encode_x25519(m.Ephemeral))
+	data=put_bytes(data,m.Kyber_Ciphertext)
+	data=binary.BigEndian.AppendUint32(data,m.Signed_Prekey_ID)
+	data=binary.BigEndian.AppendUint32(data,m.Kyber_Prekey_ID)
+	if m.Has_One_Time_Prekey{
+		data=append(data,1)
+	}else{
+		data=append(data,0)
+	}
+	data=binary.BigEndian.AppendUint32(data,m.One_Time_Prekey_ID)
+	data=put_bytes(data,m.Ciphertext)
+	return
+}
+
+func Bytes_to_Initial_Message(data []byte)(m *Initial_Message,err error){
+	r:=&reader{data:data}
+	m=new(Initial_Message)
+	id:=r.bytes()
+	ek:=r.bytes()
+	m.Kyber_Ciphertext=r.bytes()
+	m.Signed_Prekey_ID=r.uint32()
+	m.Kyber_Prekey_ID=r.uint32()
+	if r.err!=nil||len(r.data)<1||r.data[0]>1{
+		return nil,Err_Format
+	}
+	m.Has_One_Time_Prekey=r.data[0]==1
+	r.data=r.data[1:]
+	m.One_Time_Prekey_ID=r.uint32()
+	m.Ciphertext=r.bytes()
+	if r.err!=nil||len(r.data)!=0||len(m.Kyber_Ciphertext)!=kem.Ct_Len(){
+		return nil,Err_Format
+	}
+	if m.Identity,err=Bytes_to_Identity(id);err!=nil{
+		return nil,err
+	}
+	if m.Ephemeral,err=decode_x25519(ek);err!=nil{
+		return nil,err
+	}
+	return
+}
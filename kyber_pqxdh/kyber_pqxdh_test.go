@@ -0,0 +1,162 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the PQXDH key agreement
+*/
+package kyber_pqxdh
+
+import(
+	"bytes"
+	"testing"
+)
+
+//runs the full flow through the wire formats
+func exchange(t *testing.T,alice *Identity_Key,bob *Prekey_Store,plaintext []byte)(SK_A,SK_B,got []byte,err error){
+	b,err:=Bytes_to_Bundle(bob.Bundle().To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	msg,SK_A,err:=Initiate(alice,b,plaintext)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	wire,err:=Bytes_to_Initial_Message(msg.To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	SK_B,got,err=bob.Respond(wire)
+	return
+}
+
+func Test_PQXDH(t *testing.T){
+	alice,_:=New_Identity_Key()
+	bob_ik,_:=New_Identity_Key()
+	bob,err:=New_Prekey_Store(bob_ik)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if err=bob.Generate_One_Time_Prekeys(2);err!=nil{
+		t.Fatal(err)
+	}
+	for i:=0;i<4;i++{//two sessions with one-time prekeys then two with the last-resort kyber prekey
+		SK_A,SK_B,got,err:=exchange(t,alice,bob,[]byte("hello bob"))
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(SK_A,SK_B)||!bytes.Equal(got,[]byte("hello bob")){
+			t.Fatal("PQXDH shared keys do not match")
+		}
+	}
+}
+
+func Test_Replay(t *testing.T){
+	alice,_:=New_Identity_Key()
+	bob_ik,_:=New_Identity_Key()
+	bob,_:=New_Prekey_Store(bob_ik)
+	bob.Generate_One_Time_Prekeys(1)
+	msg,_,err:=Initiate(alice,bob.Bundle(),nil)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,err=bob.Respond(msg);err!=nil{
+		t.Fatal(err)
+	}
+	if _,_,err=bob.Respond(msg);err!=Err_Unknown_Prekey{
+		t.Fatal("one-time prekeys were reused")
+	}
+}
+
+//forged initial messages can name the public prekey ids but must not use the prekeys up
+func Test_Forged_Messages(t *testing.T){
+	alice,_:=New_Identity_Key()
+	mallory,_:=New_Identity_Key()
+	bob_ik,_:=New_Identity_Key()
+	bob,_:=New_Prekey_Store(bob_ik)
+	bob.Generate_One_Time_Prekeys(1)
+	b:=bob.Bundle()
+	if b.One_Time_Prekey==nil||bob.kyber[b.Kyber_Prekey_ID].last_resort{
+		t.Fatal("bundle does not hand out one-time prekeys")
+	}
+	forged,_,_:=Initiate(mallory,b,[]byte("forged"))
+	forged.Ciphertext[0]^=1
+	if _,_,err:=bob.Respond(forged);err==nil{
+		t.Fatal("forged message was accepted")
+	}
+	forged,_,_=Initiate(mallory,b,[]byte("forged"))
+	forged.Identity=alice.Public()
+	if _,_,err:=bob.Respond(forged);err==nil{
+		t.Fatal("message claiming another identity was accepted")
+	}
+	msg,SK_A,err:=Initiate(alice,b,[]byte("hello bob"))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	SK_B,got,err:=bob.Respond(msg)
+	if err!=nil||!bytes.Equal(SK_A,SK_B)||!bytes.Equal(got,[]byte("hello bob")){
+		t.Fatal("forged messages used up the prekeys")
+	}
+	if _,_,err=bob.Respond(msg);err!=Err_Unknown_Prekey{
+		t.Fatal("prekeys were kept after a good message")
+	}
+}
+
+func Test_Bad_Signature(t *testing.T){
+	alice,_:=New_Identity_Key()
+	bob_ik,_:=New_Identity_Key()
+	mallory,_:=New_Identity_Key()
+	bob,_:=New_Prekey_Store(bob_ik)
+	b:=bob.Bundle()
+	b.Identity=mallory.Public()
+	if _,_,err:=Initiate(alice,b,nil);err!=Err_Signature{
+		t.Fatal("bundle signed by a different identity was accepted")
+	}
+	other,_:=New_Prekey_Store(bob_ik)
+	b=bob.Bundle()
+	b.Kyber_Prekey=other.Bundle().Kyber_Prekey
+	if _,_,err:=Initiate(alice,b,nil);err!=Err_Signature{
+		t.Fatal("unsigned kyber prekey was accepted")
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	alice,_:=New_Identity_Key()
+	bob_ik,_:=New_Identity_Key()
+	bob,_:=New_Prekey_Store(bob_ik)
+	msg,_,err:=Initiate(alice,bob.Bundle(),[]byte("hello bob"))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	msg.Kyber_Ciphertext[0]^=1
+	if _,_,err=bob.Respond(msg);err==nil{
+		t.Fatal("tampered kyber ciphertext was accepted")
+	}
+	mallory,_:=New_Identity_Key()
+	msg,_,_=Initiate(alice,bob.Bundle(),[]byte("hello bob"))
+	msg.Identity=mallory.Public()
+	if _,_,err=bob.Respond(msg);err==nil{
+		t.Fatal("initial message with a swapped identity was accepted")
+	}
+	//messages missing a key are refused rather than derived from fewer DHs
+	for _,strip:=range []func(m *Initial_Message){
+		func(m *Initial_Message){m.Ephemeral=nil},
+		func(m *Initial_Message){m.Identity.DH=nil},
+		func(m *Initial_Message){m.Identity.Sign=nil},
+	}{
+		msg,_,_=Initiate(alice,bob.Bundle(),[]byte("hello bob"))
+		strip(msg)
+		if _,_,err=bob.Respond(msg);err!=Err_Format{
+			t.Fatal("initial message with a missing key was not refused")
+		}
+	}
+	b:=bob.Bundle()
+	b.Identity.DH=nil
+	if _,_,err=Initiate(alice,b,nil);err!=Err_Format{
+		t.Fatal("bundle without an identity DH key was accepted")
+	}
+	if _,err=Bytes_to_Bundle([]byte{0,0,0,9});err==nil{
+		t.Fatal("malformed bundle was accepted")
+	}
+}
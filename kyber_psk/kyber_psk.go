@@ -0,0 +1,502 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a rosenpass style daemon that runs a Kyber.AKE handshake with each peer over UDP and exports a fresh 32 byte WireGuard preshared key
+the side with the smaller SHA3-256(static public key) starts every handshake, again after each Rotation, and the other side only answers
+
+	init     (1): sid(4)||SHA3-256(initiator public key)||timestamp(8)||kyber_ake message 1||mac1(16)||mac2(16)
+	response (2): sid(4)||kyber_ake message 2
+	confirm  (3): sid(4)||kyber_ake message 3
+	ack      (4): sid(4)||KMAC256(session key,"kyber_psk ack",32)
+	cookie   (5): sid(4)||nonce(24)||XChaCha20-Poly1305(SHA3-256("kyber_psk cookie"||responder public key),cookie,mac1)
+mac1 is keyed by the responder public key so only senders that know it get any kyber work done, under load the responder also wants mac2 keyed by
+a cookie bound to the source address, like WireGuard, and answers anything else with a cookie reply
+psk=KMAC256(session key,"kyber_psk wireguard psk",32), the responder exports on a valid confirm and the initiator on the ack
+the init header up to the timestamp is the kyber_ake context so a changed timestamp fails key confirmation, and like WireGuard the responder drops any init
+that is not newer than the last one that finished a handshake with that peer, so a replayed init can not replace the handshake in progress
+the newest accepted timestamp is only kept in memory, a restarted responder takes the next init it sees
+the initiator hash is sent in the clear so the responder knows which static key to authenticate, the exchange hides the psk but not who is talking
+a peer that has not completed a handshake for 3 rotations gets a random psk so WireGuard stops using a stale key
+*/
+package kyber_psk
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_ake"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+	"crypto/rand"
+	"crypto/subtle"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const(
+	type_init byte=1
+	type_response byte=2
+	type_confirm byte=3
+	type_ack byte=4
+	type_cookie byte=5
+	PSK_len=32
+	header_len=5
+	id_len=32
+	timestamp_len=8
+	mac_len=16
+	cookie_len=16
+	ack_len=32
+	max_attempts=8
+	load_limit=20//handshakes a second before cookies are required
+	cookie_lifetime=2*time.Minute
+	Default_rotation=2*time.Minute
+	Default_retransmit=time.Second
+)
+
+var(
+	Err_Config=errors.New("kyber_psk: invalid configuration")
+	Err_Closed=errors.New("kyber_psk: daemon is closed")
+)
+
+type Peer struct{
+	Name string
+	Public_Key kyber_kem.Public_Key
+	Endpoint net.Addr//needed when this side starts the handshakes, otherwise learned from the peer
+	PSK_File string//written as base64 like wg genpsk, empty for none
+	id [id_len]byte
+	mac1_key,cookie_key []byte
+	initiator bool
+	//initiator side
+	handshake *kyber_ake.Initiator
+	sid uint32
+	timestamp uint64
+	init_body,mac1 []byte
+	session []byte
+	packet []byte
+	sent time.Time
+	attempts int
+	cookie []byte
+	cookie_time time.Time
+	//responder side
+	responder *kyber_ake.Responder
+	responder_sid uint32
+	responder_time,accepted uint64
+	responder_init,response []byte
+	acked_sid uint32
+	ack []byte
+	exchanged time.Time
+	stale bool
+}
+
+type Config struct{
+	Static kyber_kem.Private_Key
+	Peers []*Peer
+	Rotation time.Duration//defaults to 2 minutes
+	Retransmit time.Duration//defaults to 1 second
+	Export func(peer *Peer,psk []byte)//called from the daemon goroutine after PSK_File is written
+	Under_Load func()bool//defaults to more than 20 handshakes in the last second
+	On_Error func(peer *Peer,err error)//local failures such as a psk file that can not be written
+}
+
+type packet struct{
+	data []byte
+	addr net.Addr
+}
+
+type Daemon struct{
+	config Config
+	conn net.PacketConn
+	id [id_len]byte
+	mac1_key,cookie_key []byte
+	peers map[[id_len]byte]*Peer
+	cookie_secret []byte
+	cookie_secret_time time.Time
+	load_count int
+	load_window time.Time
+	packets chan packet
+	done chan struct{}
+	close_once sync.Once
+	wg sync.WaitGroup
+}
+
+func peer_id(pk kyber_kem.Public_Key)[id_len]byte{
+	return sha3.Sum256(pk.To_Bytes())
+}
+
+func keyed(label string,pk kyber_kem.Public_Key)[]byte{
+	h:=sha3.Sum256(append([]byte(label),pk.To_Bytes()...))
+	return h[:]
+}
+
+//starts the daemon on conn, it owns conn from here on and closes it on Close
+func New(conn net.PacketConn,config *Config)(*Daemon,error){
+	if config.Static==nil{
+		return nil,Err_Config
+	}
+	d:=&Daemon{config:*config,conn:conn,peers:map[[id_len]byte]*Peer{},packets:make(chan packet,64),done:make(chan struct{})}
+	if d.config.Rotation<=0{
+		d.config.Rotation=Default_rotation
+	}
+	if d.config.Retransmit<=0{
+		d.config.Retransmit=Default_retransmit
+	}
+	public:=config.Static.Public()
+	d.id=peer_id(public)
+	d.mac1_key=keyed("kyber_psk mac1",public)
+	d.cookie_key=keyed("kyber_psk cookie",public)
+	for _,p:=range config.Peers{
+		if p.Public_Key==nil||p.Public_Key.Scheme()!=config.Static.Scheme(){
+			return nil,Err_Config
+		}
+		p.id=peer_id(p.Public_Key)
+		if _,dup:=d.peers[p.id];dup||p.id==d.id{
+			return nil,Err_Config
+		}
+		p.mac1_key=keyed("kyber_psk mac1",p.Public_Key)
+		p.cookie_key=keyed("kyber_psk cookie",p.Public_Key)
+		p.initiator=bytes.Compare(d.id[:],p.id[:])<0
+		if p.initiator&&p.Endpoint==nil{
+			return nil,Err_Config
+		}
+		d.peers[p.id]=p
+	}
+	d.wg.Add(2)
+	go d.read_loop()
+	go d.loop()
+	return d,nil
+}
+
+func (d *Daemon)Close()(err error){
+	err=Err_Closed
+	d.close_once.Do(func(){
+		close(d.done)
+		err=d.conn.Close()
+		d.wg.Wait()
+	})
+	return
+}
+
+func (d *Daemon)read_loop(){
+	defer d.wg.Done()
+	buf:=make([]byte,1<<16)
+	for{
+		n,addr,err:=d.conn.ReadFrom(buf)
+		if err!=nil{
+			select{
+			case <-d.done:
+				return
+			default:
+			}
+			if errors.Is(err,net.ErrClosed){
+				return
+			}
+			continue
+		}
+		select{
+		case d.packets<-packet{append([]byte(nil),buf[:n]...),addr}:
+		case <-d.done:
+			return
+		}
+	}
+}
+
+//all protocol state is only touched from this goroutine
+func (d *Daemon)loop(){
+	defer d.wg.Done()
+	tick:=min(d.config.Rotation,d.config.Retransmit)/4
+	ticker:=time.NewTicker(max(tick,5*time.Millisecond))
+	defer ticker.Stop()
+	d.timers(time.Now())
+	for{
+		select{
+		case <-d.done:
+			return
+		case p:=<-d.packets:
+			d.handle(p.data,p.addr,time.Now())
+		case now:=<-ticker.C:
+			d.timers(now)
+		}
+	}
+}
+
+func (d *Daemon)fail(p *Peer,err error){
+	if d.config.On_Error!=nil{
+		d.config.On_Error(p,err)
+	}
+}
+
+func (d *Daemon)timers(now time.Time){
+	for _,p:=range d.config.Peers{
+		if p.initiator{
+			switch{
+			case p.handshake==nil&&(p.exchanged.IsZero()||now.Sub(p.exchanged)>=d.config.Rotation):
+				d.start(p,now)
+			case p.handshake!=nil&&now.Sub(p.sent)>=d.config.Retransmit:
+				if p.attempts>=max_attempts{
+					d.start(p,now)
+				}else{
+					d.send(p,p.packet,now)
+				}
+			}
+		}
+		if !p.exchanged.IsZero()&&!p.stale&&now.Sub(p.exchanged)>=3*d.config.Rotation{
+			psk:=make([]byte,PSK_len)
+			if _,err:=rand.Read(psk);err!=nil{
+				d.fail(p,err)
+				continue
+			}
+			p.stale=true
+			d.export(p,psk)
+		}
+	}
+}
+
+func (d *Daemon)start(p *Peer,now time.Time){
+	I,err:=kyber_ake.New_Initiator(d.config.Static,p.Public_Key)
+	if err!=nil{
+		d.fail(p,err)
+		return
+	}
+	var sid [4]byte
+	if _,err=rand.Read(sid[:]);err!=nil{
+		d.fail(p,err)
+		return
+	}
+	//strictly increasing even if the clock steps back while the daemon runs
+	timestamp:=max(uint64(now.UnixNano()),p.timestamp+1)
+	header:=append(append([]byte{type_init},sid[:]...),d.id[:]...)
+	header=binary.BigEndian.AppendUint64(header,timestamp)
+	I.Context=header
+	msg1,err:=I.Message_1()
+	if err!=nil{
+		d.fail(p,err)
+		return
+	}
+	p.handshake,p.session,p.sid,p.timestamp,p.attempts=I,nil,binary.BigEndian.Uint32(sid[:]),timestamp,0
+	p.init_body=append(append([]byte(nil),header...),msg1...)
+	d.send(p,seal_init(p,now),now)
+}
+
+func mac(key,data []byte,label string)[]byte{
+	return kyber_kdf.KMAC_256(key,data,mac_len,[]byte(label))
+}
+
+func seal_init(p *Peer,now time.Time)[]byte{
+	p.mac1=mac(p.mac1_key,p.init_body,"kyber_psk mac1")
+	packet:=append(append([]byte(nil),p.init_body...),p.mac1...)
+	if p.cookie!=nil&&now.Sub(p.cookie_time)<cookie_lifetime{
+		return append(packet,mac(p.cookie,packet,"kyber_psk mac2")...)
+	}
+	return append(packet,make([]byte,mac_len)...)
+}
+
+func (d *Daemon)send(p *Peer,packet []byte,now time.Time){
+	p.packet,p.sent=packet,now
+	p.attempts++
+	d.conn.WriteTo(packet,p.Endpoint)
+}
+
+func (d *Daemon)export(p *Peer,psk []byte){
+	if p.PSK_File!=""{
+		if err:=write_psk(p.PSK_File,psk);err!=nil{
+			d.fail(p,err)
+		}
+	}
+	if d.config.Export!=nil{
+		d.config.Export(p,psk)
+	}
+}
+
+//written next to the target and renamed over it so wg never reads half a key
+func write_psk(path string,psk []byte)error{
+	tmp:=path+".tmp"
+	if err:=os.WriteFile(tmp,[]byte(base64.StdEncoding.EncodeToString(psk)+"\n"),0600);err!=nil{
+		return err
+	}
+	return os.Rename(tmp,path)
+}
+
+func psk(session []byte)[]byte{
+	out,_:=kyber_kdf.KMAC256.Derive(session,nil,"kyber_psk wireguard psk",PSK_len)
+	return out
+}
+
+func ack_tag(session []byte)[]byte{
+	out,_:=kyber_kdf.KMAC256.Derive(session,nil,"kyber_psk ack",ack_len)
+	return out
+}
+
+func (d *Daemon)handle(data []byte,addr net.Addr,now time.Time){
+	if len(data)<header_len{
+		return
+	}
+	sid:=binary.BigEndian.Uint32(data[1:header_len])
+	body:=data[header_len:]
+	switch data[0]{
+	case type_init:
+		d.handle_init(data,sid,addr,now)
+	case type_response:
+		p:=d.initiating(sid)
+		if p==nil||p.session!=nil{
+			return
+		}
+		msg3,err:=p.handshake.Finish(body)
+		if err!=nil{
+			return//a forged response leaves the handshake waiting for the real one
+		}
+		p.session=p.handshake.Session_Key
+		p.attempts=0
+		d.send(p,append(append([]byte{type_confirm},data[1:header_len]...),msg3...),now)
+	case type_ack:
+		p:=d.initiating(sid)
+		if p==nil||p.session==nil||subtle.ConstantTimeCompare(ack_tag(p.session),body)!=1{
+			return
+		}
+		key:=psk(p.session)
+		p.handshake,p.session,p.packet=nil,nil,nil
+		p.exchanged,p.stale=now,false
+		d.export(p,key)
+	case type_cookie:
+		p:=d.initiating(sid)
+		if p==nil||p.session!=nil||len(body)!=chacha20poly1305.NonceSizeX+cookie_len+chacha20poly1305.Overhead{
+			return
+		}
+		aead,_:=chacha20poly1305.NewX(p.cookie_key)
+		cookie,err:=aead.Open(nil,body[:aead.NonceSize()],body[aead.NonceSize():],p.mac1)
+		if err!=nil{
+			return
+		}
+		p.cookie,p.cookie_time=cookie,now
+		d.send(p,seal_init(p,now),now)
+	case type_confirm:
+		for _,p:=range d.config.Peers{
+			switch{
+			case p.responder!=nil&&p.responder_sid==sid:
+				if p.responder.Finish(body)!=nil{
+					return
+				}
+				session:=p.responder.Session_Key
+				p.responder,p.responder_init,p.response=nil,nil,nil
+				p.accepted=p.responder_time
+				p.acked_sid,p.ack=sid,append(append([]byte{type_ack},data[1:header_len]...),ack_tag(session)...)
+				p.Endpoint=addr
+				p.exchanged,p.stale=now,false
+				d.export(p,psk(session))
+				d.conn.WriteTo(p.ack,addr)
+				return
+			case p.ack!=nil&&p.acked_sid==sid:
+				d.conn.WriteTo(p.ack,addr)//the ack was lost and the initiator sent its confirm again
+				return
+			}
+		}
+	}
+}
+
+func (d *Daemon)initiating(sid uint32)*Peer{
+	for _,p:=range d.config.Peers{
+		if p.initiator&&p.handshake!=nil&&p.sid==sid{
+			return p
+		}
+	}
+	return nil
+}
+
+func init_len(s kyber_kem.Scheme)int{
+	return header_len+id_len+timestamp_len+1+s.Pk_Len()+s.Ct_Len()+2*mac_len
+}
+
+func (d *Daemon)under_load(now time.Time)bool{
+	if d.config.Under_Load!=nil{
+		return d.config.Under_Load()
+	}
+	if now.Sub(d.load_window)>=time.Second{
+		d.load_window,d.load_count=now,0
+	}
+	d.load_count++
+	return d.load_count>load_limit
+}
+
+func (d *Daemon)cookie(addr net.Addr,now time.Time)([]byte,error){
+	if d.cookie_secret==nil||now.Sub(d.cookie_secret_time)>=cookie_lifetime{
+		d.cookie_secret=make([]byte,32)
+		if _,err:=rand.Read(d.cookie_secret);err!=nil{
+			d.cookie_secret=nil
+			return nil,err
+		}
+		d.cookie_secret_time=now
+	}
+	return kyber_kdf.KMAC_256(d.cookie_secret,[]byte(addr.String()),cookie_len,[]byte("kyber_psk cookie")),nil
+}
+
+func (d *Daemon)handle_init(data []byte,sid uint32,addr net.Addr,now time.Time){
+	n:=init_len(d.config.Static.Scheme())
+	if len(data)!=n{
+		return
+	}
+	body,mac1,mac2:=data[:n-2*mac_len],data[n-2*mac_len:n-mac_len],data[n-mac_len:]
+	if subtle.ConstantTimeCompare(mac(d.mac1_key,body,"kyber_psk mac1"),mac1)!=1{
+		return
+	}
+	if d.under_load(now){
+		cookie,err:=d.cookie(addr,now)
+		if err!=nil{
+			return
+		}
+		if subtle.ConstantTimeCompare(mac(cookie,data[:n-mac_len],"kyber_psk mac2"),mac2)!=1{
+			d.send_cookie(data[1:header_len],cookie,mac1,addr)
+			return
+		}
+	}
+	var id [id_len]byte
+	copy(id[:],body[header_len:])
+	p:=d.peers[id]
+	if p==nil||p.initiator{
+		return
+	}
+	context:=body[:header_len+id_len+timestamp_len]
+	timestamp:=binary.BigEndian.Uint64(context[header_len+id_len:])
+	if timestamp<=p.accepted{
+		return//replayed from a handshake that already finished
+	}
+	if p.responder!=nil{
+		if bytes.Equal(body,p.responder_init){
+			d.conn.WriteTo(p.response,addr)//a retransmitted init gets the same response and keeps the handshake
+			return
+		}
+		if timestamp<=p.responder_time{
+			return
+		}
+	}
+	R,err:=kyber_ake.New_Responder(d.config.Static,p.Public_Key)
+	if err!=nil{
+		d.fail(p,err)
+		return
+	}
+	R.Context=context
+	msg2,err:=R.Message_2(body[len(context):])
+	if err!=nil{
+		return
+	}
+	p.responder,p.responder_sid,p.responder_time=R,sid,timestamp
+	p.responder_init=append([]byte(nil),body...)
+	p.response=append(append([]byte{type_response},data[1:header_len]...),msg2...)
+	d.conn.WriteTo(p.response,addr)
+}
+
+func (d *Daemon)send_cookie(sid,cookie,mac1 []byte,addr net.Addr){
+	aead,_:=chacha20poly1305.NewX(d.cookie_key)
+	nonce:=make([]byte,aead.NonceSize())
+	if _,err:=rand.Read(nonce);err!=nil{
+		return
+	}
+	packet:=append(append([]byte{type_cookie},sid...),nonce...)
+	d.conn.WriteTo(aead.Seal(packet,nonce,cookie,mac1),addr)
+}
@@ -0,0 +1,233 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the preshared key daemon with two peers over UDP loopback
+*/
+package kyber_psk
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"bytes"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type export struct{
+	daemon string
+	psk []byte
+}
+
+type test_pair struct{
+	a,b *Daemon
+	peer_a,peer_b *Peer//a's view of b and b's view of a
+	exports chan export
+}
+
+func listen(t *testing.T)net.PacketConn{
+	conn,err:=net.ListenPacket("udp","127.0.0.1:0")
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func new_pair(t *testing.T,s kyber_kem.Scheme,rotation time.Duration,under_load func()bool)*test_pair{
+	sk_a,_:=s.Keygen()
+	sk_b,_:=s.Keygen()
+	conn_a,conn_b:=listen(t),listen(t)
+	tp:=&test_pair{exports:make(chan export,64)}
+	tp.peer_a=&Peer{Name:"b",Public_Key:sk_b.Public(),Endpoint:conn_b.LocalAddr(),PSK_File:filepath.Join(t.TempDir(),"psk")}
+	tp.peer_b=&Peer{Name:"a",Public_Key:sk_a.Public(),Endpoint:conn_a.LocalAddr()}
+	config:=func(sk kyber_kem.Private_Key,p *Peer,name string)*Config{
+		return &Config{Static:sk,Peers:[]*Peer{p},Rotation:rotation,Retransmit:100*time.Millisecond,Under_Load:under_load,
+			Export:func(p *Peer,psk []byte){tp.exports<-export{name,psk}},
+			On_Error:func(p *Peer,err error){t.Error(err)}}
+	}
+	var err error
+	if tp.a,err=New(conn_a,config(sk_a,tp.peer_a,"a"));err!=nil{
+		t.Fatal(err)
+	}
+	if tp.b,err=New(conn_b,config(sk_b,tp.peer_b,"b"));err!=nil{
+		t.Fatal(err)
+	}
+	t.Cleanup(func(){
+		tp.a.Close()
+		tp.b.Close()
+	})
+	return tp
+}
+
+//waits for both daemons to export and checks they got the same key
+func (tp *test_pair)next(t *testing.T)[]byte{
+	got:=map[string][]byte{}
+	timeout:=time.After(10*time.Second)
+	for len(got)<2{
+		select{
+		case e:=<-tp.exports:
+			if got[e.daemon]!=nil{
+				t.Fatal(e.daemon+" exported twice in one rotation")
+			}
+			got[e.daemon]=e.psk
+		case <-timeout:
+			t.Fatal("no preshared key was exported")
+		}
+	}
+	if len(got["a"])!=PSK_len||!bytes.Equal(got["a"],got["b"]){
+		t.Fatal("peers exported different keys")
+	}
+	return got["a"]
+}
+
+func Test_Rotation(t *testing.T){
+	for _,s:=range []kyber_kem.Scheme{kyber_kem.Kyber_768,kyber_kem.Kyber_1024}{
+		tp:=new_pair(t,s,300*time.Millisecond,nil)
+		first:=tp.next(t)
+		second:=tp.next(t)
+		if bytes.Equal(first,second){
+			t.Fatal(s.Name()+" key was not rotated")
+		}
+		data,err:=os.ReadFile(tp.peer_a.PSK_File)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if string(data)!=base64.StdEncoding.EncodeToString(second)+"\n"{
+			t.Fatal("psk file does not hold the latest key")
+		}
+	}
+}
+
+func Test_Cookies(t *testing.T){
+	tp:=new_pair(t,kyber_kem.Kyber_768,time.Minute,func()bool{return true})
+	tp.next(t)
+	responder:=tp.b
+	if !tp.peer_a.initiator{
+		responder=tp.a
+	}
+	//a raw init with a valid mac1 and no cookie gets a cookie reply and no kyber work
+	probe:=listen(t)
+	defer probe.Close()
+	s:=kyber_kem.Kyber_768
+	stranger:=&Peer{Public_Key:responder.config.Static.Public()}
+	stranger.mac1_key=keyed("kyber_psk mac1",stranger.Public_Key)
+	body:=append([]byte{type_init,0,0,0,7},make([]byte,id_len+timestamp_len+1+s.Pk_Len()+s.Ct_Len())...)
+	stranger.init_body=body
+	packet:=seal_init(stranger,time.Now())
+	reply:=make([]byte,1<<16)
+	probe.WriteTo(packet,responder.conn.LocalAddr())
+	probe.SetReadDeadline(time.Now().Add(2*time.Second))
+	n,_,err:=probe.ReadFrom(reply)
+	if err!=nil||reply[0]!=type_cookie||n!=header_len+chacha20poly1305.NonceSizeX+cookie_len+chacha20poly1305.Overhead{
+		t.Fatal("responder under load did not answer with a cookie")
+	}
+	//a bad mac1 is dropped without any answer
+	packet[len(packet)-mac_len-1]^=1
+	probe.WriteTo(packet,responder.conn.LocalAddr())
+	probe.SetReadDeadline(time.Now().Add(300*time.Millisecond))
+	if _,_,err=probe.ReadFrom(reply);err==nil{
+		t.Fatal("init with a bad mac1 was answered")
+	}
+}
+
+//the initiator talks to the responder through a relay that sends every init twice and replays the first init it saw
+//before every packet of a later handshake, including right before the confirm
+func Test_Replay(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	sk_i,_:=s.Keygen()
+	sk_r,_:=s.Keygen()
+	if id_i,id_r:=peer_id(sk_i.Public()),peer_id(sk_r.Public());bytes.Compare(id_i[:],id_r[:])>0{
+		sk_i,sk_r=sk_r,sk_i
+	}
+	conn_i,conn_r,relay:=listen(t),listen(t),listen(t)
+	t.Cleanup(func(){relay.Close()})
+	go func(){
+		buf:=make([]byte,1<<16)
+		var old []byte
+		for{
+			n,addr,err:=relay.ReadFrom(buf)
+			if err!=nil{
+				return
+			}
+			data:=append([]byte(nil),buf[:n]...)
+			if addr.String()==conn_r.LocalAddr().String(){
+				relay.WriteTo(data,conn_i.LocalAddr())
+				continue
+			}
+			if old==nil&&data[0]==type_init{
+				old=data
+			}
+			if !bytes.Equal(data[1:header_len],old[1:header_len]){
+				relay.WriteTo(old,conn_r.LocalAddr())
+			}
+			relay.WriteTo(data,conn_r.LocalAddr())
+			if data[0]==type_init{
+				relay.WriteTo(data,conn_r.LocalAddr())
+			}
+		}
+	}()
+	exports:=make(chan []byte,64)
+	config:=func(sk kyber_kem.Private_Key,p *Peer)*Config{
+		return &Config{Static:sk,Peers:[]*Peer{p},Rotation:300*time.Millisecond,Retransmit:100*time.Millisecond,
+			Export:func(p *Peer,psk []byte){exports<-psk},
+			On_Error:func(p *Peer,err error){t.Error(err)}}
+	}
+	a,err:=New(conn_i,config(sk_i,&Peer{Public_Key:sk_r.Public(),Endpoint:relay.LocalAddr()}))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b,err:=New(conn_r,config(sk_r,&Peer{Public_Key:sk_i.Public()}))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	defer b.Close()
+	timeout:=time.After(10*time.Second)
+	for i:=0;i<6;i++{//three handshakes, each exported by both sides
+		select{
+		case <-exports:
+		case <-timeout:
+			t.Fatal("a replayed init stopped the handshake from finishing")
+		}
+	}
+}
+
+func Test_Config(t *testing.T){
+	sk,_:=kyber_kem.Kyber_768.Keygen()
+	other,_:=kyber_kem.Kyber_1024.Keygen()
+	bad:=[]*Config{
+		{},
+		{Static:sk,Peers:[]*Peer{{Public_Key:other.Public(),Endpoint:&net.UDPAddr{}}}},
+		{Static:sk,Peers:[]*Peer{{Public_Key:sk.Public(),Endpoint:&net.UDPAddr{}}}},
+	}
+	for _,config:=range bad{
+		conn:=listen(t)
+		if _,err:=New(conn,config);err!=Err_Config{
+			t.Fatal("bad configuration was accepted")
+		}
+		conn.Close()
+	}
+}
+
+func Test_Expiry(t *testing.T){
+	tp:=new_pair(t,kyber_kem.Kyber_768,200*time.Millisecond,nil)
+	first:=tp.next(t)
+	tp.b.Close()//b stops answering so a never gets a fresh key
+	timeout:=time.After(5*time.Second)
+	for{
+		select{
+		case e:=<-tp.exports:
+			if e.daemon=="a"&&!bytes.Equal(e.psk,first){
+				return
+			}
+		case <-timeout:
+			t.Fatal("stale key was never replaced")
+		}
+	}
+}
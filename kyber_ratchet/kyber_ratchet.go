@@ -0,0 +1,554 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the double ratchet with header encryption from Signal where the asymmetric ratchet step mixes a kyber_768 encapsulation into the root KDF
+Mode_Hybrid runs the X25519 ratchet on every step and mixes kyber in every KEM_Interval sending epochs, so the kyber public key and ciphertext
+only ride along on some epochs like the sparse post quantum ratchet, Mode_KEM drops X25519 and encapsulates on every step
+Each new sending epoch encapsulates to the newest kyber public key of the peer and sends a fresh one, the peer decapsulates with its current key
+	RK,CK,NHK=HKDF-SHA256(salt RK,DH||SS,"kyber_ratchet root")
+	MK=HMAC-SHA256(CK,0x01) CK=HMAC-SHA256(CK,0x02)
+message=header length(2)||nonce(24)||XChaCha20-Poly1305(HK,header)||ChaCha20-Poly1305(MK,nonce 0,plaintext,ad||encrypted header)
+header=flags(1)||[X25519 public key(32)]||[kyber public key||kyber ciphertext]||PN(4)||N(4)
+*/
+package kyber_ratchet
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const(
+	Mode_Hybrid=1
+	Mode_KEM=2
+	Default_Max_Skip=1000
+	Default_Max_Stored=2000
+	key_len=32
+	flag_dh=1
+	flag_kem=2
+	state_version=1
+)
+
+var kem=kyber_kem.Kyber_768
+
+var(
+	Err_Decrypt=errors.New("kyber_ratchet: message could not be decrypted")
+	Err_Too_Many_Skipped=errors.New("kyber_ratchet: too many skipped messages")
+	Err_State=errors.New("kyber_ratchet: session can not send until it has received a message")
+	Err_Format=errors.New("kyber_ratchet: malformed session state")
+	Err_Config=errors.New("kyber_ratchet: invalid config")
+)
+
+//Max_Skip bounds the message keys derived for one chain in a single step, Max_Stored bounds all kept skipped keys and the oldest are dropped first
+type Config struct{
+	Mode int
+	KEM_Interval int
+	Max_Skip int
+	Max_Stored int
+}
+
+type skipped_key struct{
+	hk [key_len]byte
+	n uint32
+	mk [key_len]byte
+}
+
+type Session struct{
+	config Config
+	dhs *ecdh.PrivateKey
+	dhr *ecdh.PublicKey
+	kems kyber_kem.Private_Key
+	kemr kyber_kem.Public_Key
+	send_kem []byte//kyber public key||ciphertext sent in every header of the current sending epoch, nil on epochs without kyber
+	rk,cks,ckr,hks,hkr,nhks,nhkr []byte
+	ns,nr,pn,epoch uint32
+	skipped []skipped_key
+}
+
+type header struct{
+	dh *ecdh.PublicKey
+	kem_pk kyber_kem.Public_Key
+	kem_ct []byte
+	pn,n uint32
+}
+
+func (c *Config)check()(Config,error){
+	out:=*c
+	if out.Mode!=Mode_Hybrid&&out.Mode!=Mode_KEM{
+		return out,Err_Config
+	}
+	if out.KEM_Interval==0||out.Mode==Mode_KEM{
+		out.KEM_Interval=1
+	}
+	if out.Max_Skip==0{
+		out.Max_Skip=Default_Max_Skip
+	}
+	if out.Max_Stored==0{
+		out.Max_Stored=Default_Max_Stored
+	}
+	if out.KEM_Interval<0||out.Max_Skip<0||out.Max_Stored<0{
+		return out,Err_Config
+	}
+	return out,nil
+}
+
+//the shared secret from the key agreement gives the first root key and both initial header keys
+func initial_keys(SK []byte)(rk,hka,nhkb []byte,err error){
+	out:=make([]byte,3*key_len)
+	if _,err=io.ReadFull(hkdf.New(sha256.New,SK,nil,[]byte("kyber_ratchet init")),out);err!=nil{
+		return
+	}
+	return out[:key_len],out[key_len:2*key_len],out[2*key_len:],nil
+}
+
+//the initiator knows the ratchet public keys of the responder, dh is nil in Mode_KEM
+func Init_Initiator(config *Config,SK []byte,dh *ecdh.PublicKey,kem_pk kyber_kem.Public_Key)(*Session,error){
+	c,err:=config.check()
+	if err!=nil{
+		return nil,err
+	}
+	if (c.Mode==Mode_Hybrid)!=(dh!=nil)||kem_pk==nil||kem_pk.Scheme()!=kem{
+		return nil,Err_Config
+	}
+	s:=&Session{config:c,dhr:dh,kemr:kem_pk}
+	var hka,nhkb []byte
+	if s.rk,hka,nhkb,err=initial_keys(SK);err!=nil{
+		return nil,err
+	}
+	if err=s.send_step();err!=nil{
+		return nil,err
+	}
+	s.hks=hka
+	s.nhkr=nhkb
+	return s,nil
+}
+
+//the responder can only send once the first message from the initiator has arrived
+func Init_Responder(config *Config,SK []byte,dh *ecdh.PrivateKey,kem_sk kyber_kem.Private_Key)(*Session,error){
+	c,err:=config.check()
+	if err!=nil{
+		return nil,err
+	}
+	if (c.Mode==Mode_Hybrid)!=(dh!=nil)||kem_sk==nil||kem_sk.Scheme()!=kem{
+		return nil,Err_Config
+	}
+	s:=&Session{config:c,dhs:dh,kems:kem_sk}
+	if s.rk,s.nhkr,s.nhks,err=initial_keys(SK);err!=nil{
+		return nil,err
+	}
+	return s,nil
+}
+
+func kdf_rk(rk,ikm []byte)(new_rk,ck,nhk []byte,err error){
+	out:=make([]byte,3*key_len)
+	if _,err=io.ReadFull(hkdf.New(sha256.New,ikm,rk,[]byte("kyber_ratchet root")),out);err!=nil{
+		return
+	}
+	return out[:key_len],out[key_len:2*key_len],out[2*key_len:],nil
+}
+
+func kdf_ck(ck []byte)(next,mk []byte){
+	mac:=hmac.New(sha256.New,ck)
+	mac.Write([]byte{1})
+	mk=mac.Sum(nil)
+	mac=hmac.New(sha256.New,ck)
+	mac.Write([]byte{2})
+	return mac.Sum(nil),mk
+}
+
+//starts a new sending epoch with a fresh X25519 key and, on kyber epochs, a fresh kyber key plus an encapsulation to the peer
+func (s *Session)send_step()(err error){
+	var ikm []byte
+	if s.config.Mode==Mode_Hybrid{
+		if s.dhs,err=ecdh.X25519().GenerateKey(rand.Reader);err!=nil{
+			return
+		}
+		dh,err:=s.dhs.ECDH(s.dhr)
+		if err!=nil{
+			return err
+		}
+		ikm=dh
+	}
+	s.send_kem=nil
+	if s.epoch%uint32(s.config.KEM_Interval)==0{
+		ct,ss,err:=s.kemr.Enc()
+		if err!=nil{
+			return err
+		}
+		if s.kems,err=kem.Keygen();err!=nil{
+			return err
+		}
+		s.send_kem=append(s.kems.Public().To_Bytes(),ct...)
+		ikm=append(ikm,ss...)
+	}
+	s.epoch++
+	s.rk,s.cks,s.nhks,err=kdf_rk(s.rk,ikm)
+	return
+}
+
+func (s *Session)receive_step(h *header)(err error){
+	var ikm []byte
+	if s.config.Mode==Mode_Hybrid{
+		s.dhr=h.dh
+		if ikm,err=s.dhs.ECDH(s.dhr);err!=nil{
+			return
+		}
+	}
+	if h.kem_pk!=nil{
+		ss,err:=s.kems.Dec(h.kem_ct)
+		if err!=nil{
+			return err
+		}
+		s.kemr=h.kem_pk
+		ikm=append(ikm,ss...)
+	}
+	s.pn=s.ns
+	s.ns=0
+	s.nr=0
+	s.hks=s.nhks
+	s.hkr=s.nhkr
+	if s.rk,s.ckr,s.nhkr,err=kdf_rk(s.rk,ikm);err!=nil{
+		return
+	}
+	return s.send_step()
+}
+
+func (s *Session)encode_header()[]byte{
+	var flags byte
+	var out []byte
+	if s.config.Mode==Mode_Hybrid{
+		flags|=flag_dh
+		out=append(out,s.dhs.PublicKey().Bytes()...)
+	}
+	if s.send_kem!=nil{
+		flags|=flag_kem
+		out=append(out,s.send_kem...)
+	}
+	out=binary.BigEndian.AppendUint32(out,s.pn)
+	out=binary.BigEndian.AppendUint32(out,s.ns)
+	return append([]byte{flags},out...)
+}
+
+func (s *Session)decode_header(data []byte)(*header,error){
+	if len(data)<1{
+		return nil,Err_Decrypt
+	}
+	flags:=data[0]
+	data=data[1:]
+	h:=new(header)
+	want:=8
+	if flags&flag_dh!=0{
+		want+=32
+	}
+	if flags&flag_kem!=0{
+		want+=kem.Pk_Len()+kem.Ct_Len()
+	}
+	if flags&^(flag_dh|flag_kem)!=0||(flags&flag_dh!=0)!=(s.config.Mode==Mode_Hybrid)||len(data)!=want{
+		return nil,Err_Decrypt
+	}
+	var err error
+	if flags&flag_dh!=0{
+		if h.dh,err=ecdh.X25519().NewPublicKey(data[:32]);err!=nil{
+			return nil,Err_Decrypt
+		}
+		data=data[32:]
+	}
+	if flags&flag_kem!=0{
+		if h.kem_pk,err=kem.Bytes_to_Pk(data[:kem.Pk_Len()]);err!=nil{
+			return nil,Err_Decrypt
+		}
+		h.kem_ct=data[kem.Pk_Len():kem.Pk_Len()+kem.Ct_Len()]
+		data=data[kem.Pk_Len()+kem.Ct_Len():]
+	}
+	h.pn=binary.BigEndian.Uint32(data)
+	h.n=binary.BigEndian.Uint32(data[4:])
+	return h,nil
+}
+
+//header keys are used for a whole epoch so the header gets a random extended nonce
+func seal_header(hk,plaintext []byte)([]byte,error){
+	aead,err:=chacha20poly1305.NewX(hk)
+	if err!=nil{
+		return nil,err
+	}
+	nonce:=make([]byte,aead.NonceSize(),aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _,err=rand.Read(nonce);err!=nil{
+		return nil,err
+	}
+	return aead.Seal(nonce,nonce,plaintext,nil),nil
+}
+
+func open_header(hk,data []byte)([]byte,bool){
+	if hk==nil||len(data)<chacha20poly1305.NonceSizeX{
+		return nil,false
+	}
+	aead,_:=chacha20poly1305.NewX(hk)
+	plaintext,err:=aead.Open(nil,data[:chacha20poly1305.NonceSizeX],data[chacha20poly1305.NonceSizeX:],nil)
+	return plaintext,err==nil
+}
+
+//message keys are only used once so the nonce stays at zero
+func seal_body(mk,ad,plaintext []byte)[]byte{
+	aead,_:=chacha20poly1305.New(mk)
+	return aead.Seal(nil,make([]byte,aead.NonceSize()),plaintext,ad)
+}
+
+func open_body(mk,ad,ciphertext []byte)([]byte,error){
+	aead,_:=chacha20poly1305.New(mk)
+	return aead.Open(nil,make([]byte,aead.NonceSize()),ciphertext,ad)
+}
+
+func (s *Session)Encrypt(plaintext,ad []byte)([]byte,error){
+	if s.cks==nil{
+		return nil,Err_State
+	}
+	var mk []byte
+	enc_header,err:=seal_header(s.hks,s.encode_header())
+	if err!=nil{
+		return nil,err
+	}
+	s.cks,mk=kdf_ck(s.cks)
+	s.ns++
+	msg:=binary.BigEndian.AppendUint16(nil,uint16(len(enc_header)))
+	msg=append(msg,enc_header...)
+	return append(msg,seal_body(mk,append(append([]byte(nil),ad...),enc_header...),plaintext)...),nil
+}
+
+//the session is left untouched when a message fails to decrypt
+func (s *Session)Decrypt(msg,ad []byte)([]byte,error){
+	if len(msg)<2||len(msg)<2+int(binary.BigEndian.Uint16(msg)){
+		return nil,Err_Decrypt
+	}
+	enc_header:=msg[2:2+int(binary.BigEndian.Uint16(msg))]
+	body:=msg[2+len(enc_header):]
+	ad=append(append([]byte(nil),ad...),enc_header...)
+	for i,k:=range s.skipped{
+		plain,ok:=open_header(k.hk[:],enc_header)
+		if !ok{
+			continue
+		}
+		h,err:=s.decode_header(plain)
+		if err!=nil||h.n!=k.n{
+			continue
+		}
+		plaintext,err:=open_body(k.mk[:],ad,body)
+		if err!=nil{
+			return nil,Err_Decrypt
+		}
+		s.skipped=append(s.skipped[:i:i],s.skipped[i+1:]...)
+		return plaintext,nil
+	}
+	next:=s.clone()
+	plaintext,err:=next.decrypt(enc_header,body,ad)
+	if err!=nil{
+		return nil,err
+	}
+	*s=*next
+	return plaintext,nil
+}
+
+func (s *Session)decrypt(enc_header,body,ad []byte)([]byte,error){
+	plain,ok:=open_header(s.hkr,enc_header)
+	new_epoch:=false
+	if !ok{
+		if plain,ok=open_header(s.nhkr,enc_header);!ok{
+			return nil,Err_Decrypt
+		}
+		new_epoch=true
+	}
+	h,err:=s.decode_header(plain)
+	if err!=nil{
+		return nil,err
+	}
+	if new_epoch{
+		if err=s.skip(h.pn);err!=nil{
+			return nil,err
+		}
+		if h.kem_pk==nil&&s.config.Mode==Mode_KEM{
+			return nil,Err_Decrypt
+		}
+		if err=s.receive_step(h);err!=nil{
+			return nil,Err_Decrypt
+		}
+	}
+	if h.n<s.nr{
+		return nil,Err_Decrypt//a replay of a message that was already decrypted
+	}
+	if err=s.skip(h.n);err!=nil{
+		return nil,err
+	}
+	var mk []byte
+	s.ckr,mk=kdf_ck(s.ckr)
+	s.nr++
+	plaintext,err:=open_body(mk,ad,body)
+	if err!=nil{
+		return nil,Err_Decrypt
+	}
+	return plaintext,nil
+}
+
+func (s *Session)skip(until uint32)error{
+	if s.ckr==nil{
+		return nil
+	}
+	if until>s.nr&&until-s.nr>uint32(s.config.Max_Skip){
+		return Err_Too_Many_Skipped
+	}
+	for s.nr<until{
+		var k skipped_key
+		var mk []byte
+		s.ckr,mk=kdf_ck(s.ckr)
+		copy(k.hk[:],s.hkr)
+		copy(k.mk[:],mk)
+		k.n=s.nr
+		s.skipped=append(s.skipped,k)
+		s.nr++
+	}
+	if over:=len(s.skipped)-s.config.Max_Stored;over>0{
+		s.skipped=append([]skipped_key(nil),s.skipped[over:]...)
+	}
+	return nil
+}
+
+func (s *Session)clone()*Session{
+	c:=*s
+	c.skipped=append([]skipped_key(nil),s.skipped...)
+	return &c
+}
+
+func put_bytes(data,field []byte)[]byte{
+	data=binary.BigEndian.AppendUint32(data,uint32(len(field)))
+	return append(data,field...)
+}
+
+type reader struct{
+	data []byte
+	err error
+}
+
+func (r *reader)uint32()(x uint32){
+	if r.err!=nil||len(r.data)<4{
+		r.err=Err_Format
+		return
+	}
+	x=binary.BigEndian.Uint32(r.data)
+	r.data=r.data[4:]
+	return
+}
+
+func (r *reader)bytes()(field []byte){
+	n:=r.uint32()
+	if r.err!=nil||uint64(len(r.data))<uint64(n){
+		r.err=Err_Format
+		return
+	}
+	field=r.data[:n]
+	r.data=r.data[n:]
+	return
+}
+
+//the serialized state holds every secret of the session and must be stored as carefully as a private key
+func (s *Session)To_Bytes()(data []byte){
+	data=append(data,state_version)
+	for _,x:=range []int{s.config.Mode,s.config.KEM_Interval,s.config.Max_Skip,s.config.Max_Stored}{
+		data=binary.BigEndian.AppendUint32(data,uint32(x))
+	}
+	var dhs,dhr,kems,kemr []byte
+	if s.dhs!=nil{
+		dhs=s.dhs.Bytes()
+	}
+	if s.dhr!=nil{
+		dhr=s.dhr.Bytes()
+	}
+	if s.kems!=nil{
+		kems=s.kems.To_Bytes()
+	}
+	if s.kemr!=nil{
+		kemr=s.kemr.To_Bytes()
+	}
+	for _,field:=range [][]byte{dhs,dhr,kems,kemr,s.send_kem,s.rk,s.cks,s.ckr,s.hks,s.hkr,s.nhks,s.nhkr}{
+		data=put_bytes(data,field)
+	}
+	for _,x:=range []uint32{s.ns,s.nr,s.pn,s.epoch,uint32(len(s.skipped))}{
+		data=binary.BigEndian.AppendUint32(data,x)
+	}
+	for _,k:=range s.skipped{
+		data=append(data,k.hk[:]...)
+		data=binary.BigEndian.AppendUint32(data,k.n)
+		data=append(data,k.mk[:]...)
+	}
+	return
+}
+
+func Bytes_to_Session(data []byte)(s *Session,err error){
+	if len(data)<1||data[0]!=state_version{
+		return nil,Err_Format
+	}
+	r:=&reader{data:data[1:]}
+	s=new(Session)
+	s.config=Config{int(r.uint32()),int(r.uint32()),int(r.uint32()),int(r.uint32())}
+	var fields [12][]byte
+	for i:=range fields{
+		fields[i]=r.bytes()
+	}
+	for _,x:=range []*uint32{&s.ns,&s.nr,&s.pn,&s.epoch}{
+		*x=r.uint32()
+	}
+	n:=r.uint32()
+	if r.err!=nil||uint64(len(r.data))!=uint64(n)*(2*key_len+4){
+		return nil,Err_Format
+	}
+	if c,err:=s.config.check();err!=nil||c!=s.config{
+		return nil,Err_Format
+	}
+	for i:=uint32(0);i<n;i++{
+		var k skipped_key
+		copy(k.hk[:],r.data)
+		k.n=binary.BigEndian.Uint32(r.data[key_len:])
+		copy(k.mk[:],r.data[key_len+4:])
+		r.data=r.data[2*key_len+4:]
+		s.skipped=append(s.skipped,k)
+	}
+	if len(fields[0])>0{
+		if s.dhs,err=ecdh.X25519().NewPrivateKey(fields[0]);err!=nil{
+			return nil,Err_Format
+		}
+	}
+	if len(fields[1])>0{
+		if s.dhr,err=ecdh.X25519().NewPublicKey(fields[1]);err!=nil{
+			return nil,Err_Format
+		}
+	}
+	if len(fields[2])>0{
+		if s.kems,err=kem.Bytes_to_Sk(fields[2]);err!=nil{
+			return nil,Err_Format
+		}
+	}
+	if len(fields[3])>0{
+		if s.kemr,err=kem.Bytes_to_Pk(fields[3]);err!=nil{
+			return nil,Err_Format
+		}
+	}
+	keys:=[]*[]byte{&s.send_kem,&s.rk,&s.cks,&s.ckr,&s.hks,&s.hkr,&s.nhks,&s.nhkr}
+	for i,k:=range keys{
+		f:=fields[4+i]
+		if len(f)==0{
+			continue
+		}
+		if i>0&&len(f)!=key_len||i==0&&len(f)!=kem.Pk_Len()+kem.Ct_Len(){
+			return nil,Err_Format
+		}
+		*k=append([]byte(nil),f...)
+	}
+	return s,nil
+}
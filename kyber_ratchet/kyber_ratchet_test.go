@@ -0,0 +1,194 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the post quantum double ratchet
+*/
+package kyber_ratchet
+
+import(
+	"crypto/ecdh"
+	"crypto/rand"
+	"bytes"
+	"fmt"
+	mrand "math/rand"
+	"testing"
+)
+
+func new_pair(t *testing.T,config Config)(alice,bob *Session){
+	SK:=make([]byte,32)
+	rand.Read(SK)
+	kem_sk,_:=kem.Keygen()
+	var dh *ecdh.PrivateKey
+	var dh_pub *ecdh.PublicKey
+	if config.Mode==Mode_Hybrid{
+		dh,_=ecdh.X25519().GenerateKey(rand.Reader)
+		dh_pub=dh.PublicKey()
+	}
+	alice,err:=Init_Initiator(&config,SK,dh_pub,kem_sk.Public())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	bob,err=Init_Responder(&config,SK,dh,kem_sk)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return
+}
+
+func Test_Conversation(t *testing.T){
+	alice,bob:=new_pair(t,Config{Mode:Mode_Hybrid})
+	if _,err:=bob.Encrypt([]byte("too early"),nil);err!=Err_State{
+		t.Fatal("responder sent before receiving")
+	}
+	for i:=0;i<5;i++{
+		for _,pair:=range [][2]*Session{{alice,bob},{bob,alice}}{
+			msg:=[]byte(fmt.Sprintf("message %d",i))
+			c,err:=pair[0].Encrypt(msg,[]byte("ad"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			p,err:=pair[1].Decrypt(c,[]byte("ad"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p,msg){
+				t.Fatal("decrypted message does not match")
+			}
+			if _,err=pair[1].Decrypt(c,[]byte("ad"));err==nil{
+				t.Fatal("replayed message was accepted")
+			}
+		}
+	}
+}
+
+type packet struct{
+	from_alice bool
+	id int
+	c []byte
+}
+
+//each round both sides send a few messages, some are lost and the rest arrive shuffled, sessions are serialized between rounds
+func Test_Lossy_Reordering(t *testing.T){
+	for _,config:=range []Config{{Mode:Mode_Hybrid},{Mode:Mode_Hybrid,KEM_Interval:3},{Mode:Mode_KEM}}{
+		rng:=mrand.New(mrand.NewSource(int64(config.Mode*10+config.KEM_Interval)))
+		alice,bob:=new_pair(t,config)
+		sent:=map[int][]byte{}
+		var in_flight []packet
+		id:=0
+		delivered:=0
+		for round:=0;round<30;round++{
+			for _,from_alice:=range []bool{true,false}{
+				sender:=alice
+				if !from_alice{
+					sender=bob
+				}
+				if sender.cks==nil{
+					continue
+				}
+				for i:=rng.Intn(4);i>=0;i--{
+					msg:=make([]byte,rng.Intn(100))
+					rand.Read(msg)
+					c,err:=sender.Encrypt(msg,nil)
+					if err!=nil{
+						t.Fatal(err)
+					}
+					sent[id]=msg
+					if rng.Intn(5)>0{//one in five messages is lost
+						in_flight=append(in_flight,packet{from_alice,id,c})
+					}
+					id++
+				}
+			}
+			rng.Shuffle(len(in_flight),func(i,j int){in_flight[i],in_flight[j]=in_flight[j],in_flight[i]})
+			keep:=rng.Intn(len(in_flight)+1)//some messages stay in flight for a later round
+			for _,p:=range in_flight[keep:]{
+				receiver:=bob
+				if !p.from_alice{
+					receiver=alice
+				}
+				plaintext,err:=receiver.Decrypt(p.c,nil)
+				if err!=nil{
+					t.Fatal(fmt.Sprintf("mode %d interval %d: message %d: %v",config.Mode,config.KEM_Interval,p.id,err))
+				}
+				if !bytes.Equal(plaintext,sent[p.id]){
+					t.Fatal("decrypted message does not match")
+				}
+				delivered++
+			}
+			in_flight=in_flight[:keep]
+			var err error
+			if alice,err=Bytes_to_Session(alice.To_Bytes());err!=nil{
+				t.Fatal(err)
+			}
+			if bob,err=Bytes_to_Session(bob.To_Bytes());err!=nil{
+				t.Fatal(err)
+			}
+		}
+		if delivered<id/2{
+			t.Fatal("too few messages were delivered to exercise the ratchet")
+		}
+	}
+}
+
+func Test_Skip_Limits(t *testing.T){
+	alice,bob:=new_pair(t,Config{Mode:Mode_Hybrid,Max_Skip:10,Max_Stored:15})
+	var msgs [][]byte
+	for i:=0;i<30;i++{
+		c,_:=alice.Encrypt([]byte{byte(i)},nil)
+		msgs=append(msgs,c)
+	}
+	if _,err:=bob.Decrypt(msgs[11],nil);err!=Err_Too_Many_Skipped{
+		t.Fatal("skipped past the limit")
+	}
+	if _,err:=bob.Decrypt(msgs[10],nil);err!=nil{
+		t.Fatal(err)
+	}
+	if _,err:=bob.Decrypt(msgs[20],nil);err!=nil{
+		t.Fatal(err)
+	}
+	if len(bob.skipped)!=15{
+		t.Fatal("stored skipped keys were not capped")
+	}
+	if _,err:=bob.Decrypt(msgs[0],nil);err==nil{
+		t.Fatal("evicted skipped key still decrypted")
+	}
+	for _,i:=range []int{19,5}{
+		p,err:=bob.Decrypt(msgs[i],nil)
+		if err!=nil||p[0]!=byte(i){
+			t.Fatal("out of order message did not decrypt")
+		}
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	alice,bob:=new_pair(t,Config{Mode:Mode_Hybrid})
+	c,_:=alice.Encrypt([]byte("hello"),[]byte("ad"))
+	state:=bob.To_Bytes()
+	for _,i:=range []int{0,5,len(c)/2,len(c)-1}{
+		c[i]^=1
+		if _,err:=bob.Decrypt(c,[]byte("ad"));err==nil{
+			t.Fatal("tampered message was accepted")
+		}
+		c[i]^=1
+	}
+	if _,err:=bob.Decrypt(c,[]byte("other"));err==nil{
+		t.Fatal("wrong associated data was accepted")
+	}
+	if !bytes.Equal(state,bob.To_Bytes()){
+		t.Fatal("failed decryption changed the session")
+	}
+	if _,err:=bob.Decrypt(c,[]byte("ad"));err!=nil{
+		t.Fatal(err)
+	}
+	eve,_:=new_pair(t,Config{Mode:Mode_Hybrid})
+	forged,_:=eve.Encrypt([]byte("hello"),[]byte("ad"))
+	if _,err:=bob.Decrypt(forged,[]byte("ad"));err==nil{
+		t.Fatal("message from another session was accepted")
+	}
+	if _,err:=Bytes_to_Session(state[:len(state)-1]);err==nil{
+		t.Fatal("truncated state was accepted")
+	}
+}
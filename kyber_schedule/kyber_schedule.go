@@ -0,0 +1,128 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a key schedule that binds a decapsulated kyber secret to a protocol label, both peer identities and a transcript hash
+the raw secret from Enc/Dec carries no context, so every subkey is taken from a secret that was first extracted over all of it:
+
+	context=len||scheme name||len||protocol label||len||initiator id||len||responder id||len||transcript hash||len||public key||len||ciphertext
+	secret=KDF(K,context,"kyber_schedule extract",32)
+	subkey=KDF(secret,len||subkey label,"kyber_schedule expand",length)
+lengths are 4 byte big endian, any kyber_kdf.KDF can be used and the same schedule works for every scheme in kyber_kem.All
+*/
+package kyber_schedule
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	Secret_len=32
+	Key_len=32
+	IV_len=12
+)
+
+//subkey labels used by Traffic
+const(
+	Label_Encryption="encryption key"
+	Label_MAC="mac key"
+	Label_IV="iv"
+)
+
+var(
+	Err_Label=errors.New("kyber_schedule: protocol label is empty")
+	Err_Format=errors.New("kyber_schedule: shared secret or ciphertext has the wrong length")
+)
+
+//both sides must fill in the same context, the initiator is the side that encapsulates
+type Context struct{
+	KDF kyber_kdf.KDF//defaults to HKDF-SHA256
+	Label string
+	Initiator_ID,Responder_ID []byte
+	Transcript_Hash []byte
+}
+
+type Schedule struct{
+	kdf kyber_kdf.KDF
+	secret []byte
+}
+
+type Traffic_Keys struct{
+	Encryption_Key []byte
+	MAC_Key []byte
+	IV []byte
+}
+
+func append_field(out,field []byte)[]byte{
+	out=binary.BigEndian.AppendUint32(out,uint32(len(field)))
+	return append(out,field...)
+}
+
+//K and c are the output of an encapsulation to pk
+func New(ctx *Context,pk kyber_kem.Public_Key,K,c []byte)(*Schedule,error){
+	if ctx.Label==""{
+		return nil,Err_Label
+	}
+	s:=pk.Scheme()
+	if len(K)!=s.Ss_Len()||len(c)!=s.Ct_Len(){
+		return nil,Err_Format
+	}
+	kdf:=ctx.KDF
+	if kdf==nil{
+		kdf=kyber_kdf.HKDF_SHA256
+	}
+	var context []byte
+	for _,field:=range [][]byte{[]byte(s.Name()),[]byte(ctx.Label),ctx.Initiator_ID,ctx.Responder_ID,ctx.Transcript_Hash,pk.To_Bytes(),c}{
+		context=append_field(context,field)
+	}
+	secret,err:=kdf.Derive(K,context,"kyber_schedule extract",Secret_len)
+	if err!=nil{
+		return nil,err
+	}
+	return &Schedule{kdf,secret},nil
+}
+
+func Encap(ctx *Context,pk kyber_kem.Public_Key)(c []byte,s *Schedule,err error){
+	c,K,err:=pk.Enc()
+	if err!=nil{
+		return
+	}
+	s,err=New(ctx,pk,K,c)
+	return
+}
+
+func Decap(ctx *Context,sk kyber_kem.Private_Key,c []byte)(*Schedule,error){
+	if len(c)!=sk.Scheme().Ct_Len(){
+		return nil,Err_Format
+	}
+	K,err:=sk.Dec(c)
+	if err!=nil{
+		return nil,err
+	}
+	return New(ctx,sk.Public(),K,c)
+}
+
+//subkeys with different labels are independent, the length is bound in by KMAC256 but not by HKDF-SHA256
+func (s *Schedule)Key(label string,length int)([]byte,error){
+	return s.kdf.Derive(s.secret,append_field(nil,[]byte(label)),"kyber_schedule expand",length)
+}
+
+//keys for one direction of a connection, usually "initiator" and "responder"
+func (s *Schedule)Traffic(direction string)(keys *Traffic_Keys,err error){
+	keys=new(Traffic_Keys)
+	for _,sub:=range []struct{
+		label string
+		length int
+		out *[]byte
+	}{{Label_Encryption,Key_len,&keys.Encryption_Key},{Label_MAC,Key_len,&keys.MAC_Key},{Label_IV,IV_len,&keys.IV}}{
+		if *sub.out,err=s.Key(direction+" "+sub.label,sub.length);err!=nil{
+			return nil,err
+		}
+	}
+	return
+}
@@ -0,0 +1,120 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the context bound key schedule
+*/
+package kyber_schedule
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"testing"
+)
+
+func test_context(kdf kyber_kdf.KDF)*Context{
+	return &Context{KDF:kdf,Label:"test protocol v1",Initiator_ID:[]byte("alice"),Responder_ID:[]byte("bob"),Transcript_Hash:bytes.Repeat([]byte{7},32)}
+}
+
+func Test_Schedule(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		for _,kdf:=range []kyber_kdf.KDF{kyber_kdf.HKDF_SHA256,kyber_kdf.SHA3_256,kyber_kdf.KMAC256}{
+			sk,_:=s.Keygen()
+			c,initiator,err:=Encap(test_context(kdf),sk.Public())
+			if err!=nil{
+				t.Fatal(err)
+			}
+			responder,err:=Decap(test_context(kdf),sk,c)
+			if err!=nil{
+				t.Fatal(err)
+			}
+			seen:=map[string]bool{}
+			for _,direction:=range []string{"initiator","responder"}{
+				a,err:=initiator.Traffic(direction)
+				if err!=nil{
+					t.Fatal(err)
+				}
+				b,_:=responder.Traffic(direction)
+				if !bytes.Equal(a.Encryption_Key,b.Encryption_Key)||!bytes.Equal(a.MAC_Key,b.MAC_Key)||!bytes.Equal(a.IV,b.IV){
+					t.Fatal(s.Name()+" "+kdf.Name()+" traffic keys do not match")
+				}
+				if len(a.Encryption_Key)!=Key_len||len(a.MAC_Key)!=Key_len||len(a.IV)!=IV_len{
+					t.Fatal("traffic keys have the wrong length")
+				}
+				for _,key:=range [][]byte{a.Encryption_Key,a.MAC_Key}{
+					if seen[string(key)]{
+						t.Fatal(kdf.Name()+" subkeys are not independent")
+					}
+					seen[string(key)]=true
+				}
+			}
+		}
+	}
+}
+
+//changing any part of the context gives unrelated keys for the same kem output
+func Test_Context_Binding(t *testing.T){
+	s:=kyber_kem.Kyber_768
+	sk,_:=s.Keygen()
+	other,_:=s.Keygen()
+	c,K,_:=sk.Public().Enc()
+	key:=func(ctx *Context,pk kyber_kem.Public_Key,c []byte)[]byte{
+		schedule,err:=New(ctx,pk,K,c)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		out,_:=schedule.Key("k",32)
+		return out
+	}
+	base:=key(test_context(nil),sk.Public(),c)
+	changes:=[]func(*Context){
+		func(ctx *Context){ctx.Label="test protocol v2"},
+		func(ctx *Context){ctx.Initiator_ID=[]byte("mallory")},
+		func(ctx *Context){ctx.Initiator_ID,ctx.Responder_ID=ctx.Responder_ID,ctx.Initiator_ID},
+		func(ctx *Context){ctx.Transcript_Hash[0]^=1},
+		func(ctx *Context){ctx.Initiator_ID,ctx.Responder_ID=[]byte("alicebob"),nil},
+		func(ctx *Context){ctx.KDF=kyber_kdf.KMAC256},
+	}
+	for i,change:=range changes{
+		ctx:=test_context(nil)
+		change(ctx)
+		if bytes.Equal(key(ctx,sk.Public(),c),base){
+			t.Fatal("key does not depend on context change",i)
+		}
+	}
+	if bytes.Equal(key(test_context(nil),other.Public(),c),base){
+		t.Fatal("key does not depend on the public key")
+	}
+	c2:=append([]byte(nil),c...)
+	c2[0]^=1
+	if bytes.Equal(key(test_context(nil),sk.Public(),c2),base){
+		t.Fatal("key does not depend on the ciphertext")
+	}
+	schedule,_:=New(test_context(nil),sk.Public(),K,c)
+	a,_:=schedule.Key("a",32)
+	b,_:=schedule.Key("b",32)
+	if bytes.Equal(a,b){
+		t.Fatal("subkey does not depend on its label")
+	}
+}
+
+func Test_Errors(t *testing.T){
+	sk,_:=kyber_kem.Kyber_512.Keygen()
+	c,K,_:=sk.Public().Enc()
+	if _,err:=New(&Context{},sk.Public(),K,c);err!=Err_Label{
+		t.Fatal("empty label was accepted")
+	}
+	if _,err:=New(test_context(nil),sk.Public(),K[1:],c);err!=Err_Format{
+		t.Fatal("short secret was accepted")
+	}
+	if _,err:=Decap(test_context(nil),sk,c[1:]);err!=Err_Format{
+		t.Fatal("short ciphertext was accepted")
+	}
+	schedule,_:=New(test_context(kyber_kdf.SHA3_256),sk.Public(),K,c)
+	if _,err:=schedule.Key("long",64);err==nil{
+		t.Fatal("SHA3-256 schedule derived more than 32 bytes")
+	}
+}
@@ -0,0 +1,93 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains anonymous sealed boxes that encrypt a message to a kyber public key (KEM-DEM)
+sealed=version(1)||scheme id(1)||kem ciphertext||ChaCha20-Poly1305(key,nonce 0,plaintext,version||scheme id||aad)
+key=KMAC256(K,kem ciphertext||recipient public key,"kyber_seal",32)
+*/
+package kyber_seal
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/chacha20poly1305"
+	"crypto/cipher"
+	"errors"
+)
+
+const(
+	Version byte=1
+	header_len=2
+	Scheme_other byte=0
+)
+
+var Err_Open=errors.New("kyber_seal: message authentication failed")
+
+//scheme ids follow the order of kyber_kem.All, any other scheme such as a hybrid is tagged 0
+func Scheme_ID(s kyber_kem.Scheme)byte{
+	for i,known:=range kyber_kem.All{
+		if s==known{
+			return byte(i+1)
+		}
+	}
+	return Scheme_other
+}
+
+func Overhead(s kyber_kem.Scheme)int{
+	return header_len+s.Ct_Len()+chacha20poly1305.Overhead
+}
+
+func Seal(pk kyber_kem.Public_Key,plaintext,aad []byte)([]byte,error){
+	c,K,err:=pk.Enc()
+	if err!=nil{
+		return nil,err
+	}
+	aead,err:=new_aead(K,c,pk)
+	if err!=nil{
+		return nil,err
+	}
+	header:=[]byte{Version,Scheme_ID(pk.Scheme())}
+	sealed:=make([]byte,0,len(plaintext)+Overhead(pk.Scheme()))
+	sealed=append(append(sealed,header...),c...)
+	return aead.Seal(sealed,make([]byte,aead.NonceSize()),plaintext,append(header,aad...)),nil
+}
+
+func Open(sk kyber_kem.Private_Key,sealed,aad []byte)([]byte,error){
+	s:=sk.Scheme()
+	if len(sealed)<Overhead(s){
+		return nil,errors.New("kyber_seal: sealed message is too short")
+	}
+	if sealed[0]!=Version{
+		return nil,errors.New("kyber_seal: unknown version")
+	}
+	if sealed[1]!=Scheme_ID(s){
+		return nil,errors.New("kyber_seal: message was sealed for a different scheme")
+	}
+	c:=sealed[header_len:header_len+s.Ct_Len()]
+	K,err:=sk.Dec(c)
+	if err!=nil{
+		return nil,err
+	}
+	aead,err:=new_aead(K,c,sk.Public())
+	if err!=nil{
+		return nil,err
+	}
+	header:=sealed[:header_len]
+	plaintext,err:=aead.Open(nil,make([]byte,aead.NonceSize()),sealed[header_len+s.Ct_Len():],append(append([]byte(nil),header...),aad...))
+	if err!=nil{
+		return nil,Err_Open
+	}
+	return plaintext,nil
+}
+
+//each kem ciphertext gives a fresh key so the nonce can stay at zero
+func new_aead(K,c []byte,pk kyber_kem.Public_Key)(cipher.AEAD,error){
+	key,err:=kyber_kdf.KMAC256.Derive(K,append(append([]byte(nil),c...),pk.To_Bytes()...),"kyber_seal",chacha20poly1305.KeySize)
+	if err!=nil{
+		return nil,err
+	}
+	return chacha20poly1305.New(key)
+}
@@ -0,0 +1,71 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on sealed boxes
+*/
+package kyber_seal
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_hybrid"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto/ecdh"
+	"bytes"
+	"testing"
+)
+
+func Test_Seal(t *testing.T){
+	hybrid,_:=kyber_hybrid.New(ecdh.X25519(),kyber_kem.Kyber_768,kyber_kdf.HKDF_SHA256)
+	for _,s:=range append(kyber_kem.All,hybrid){
+		sk,_:=s.Keygen()
+		for _,msg:=range [][]byte{nil,[]byte("attack at dawn"),bytes.Repeat([]byte{7},100000)}{
+			sealed,err:=Seal(sk.Public(),msg,[]byte("aad"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			if len(sealed)!=len(msg)+Overhead(s){
+				t.Fatal(s.Name()+" sealed length does not match the overhead")
+			}
+			opened,err:=Open(sk,sealed,[]byte("aad"))
+			if err!=nil{
+				t.Fatal(err)
+			}
+			if !bytes.Equal(opened,msg){
+				t.Fatal(s.Name()+" opened message does not match")
+			}
+		}
+	}
+}
+
+func Test_Tampering(t *testing.T){
+	for _,s:=range kyber_kem.All{
+		sk,_:=s.Keygen()
+		sealed,_:=Seal(sk.Public(),[]byte("attack at dawn"),nil)
+		for _,i:=range []int{0,1,2,header_len+s.Ct_Len()-1,header_len+s.Ct_Len(),len(sealed)-1}{
+			sealed[i]^=1
+			if _,err:=Open(sk,sealed,nil);err==nil{
+				t.Fatal(s.Name()+" accepted a tampered message")
+			}
+			sealed[i]^=1
+		}
+		if _,err:=Open(sk,sealed,[]byte("other"));err==nil{
+			t.Fatal(s.Name()+" accepted the wrong associated data")
+		}
+		if _,err:=Open(sk,sealed[:len(sealed)-1],nil);err==nil{
+			t.Fatal(s.Name()+" accepted a truncated message")
+		}
+		wrong,_:=s.Keygen()
+		if _,err:=Open(wrong,sealed,nil);err!=Err_Open{
+			t.Fatal(s.Name()+" opened with the wrong key")
+		}
+	}
+	sk_512,_:=kyber_kem.Kyber_512.Keygen()
+	sk_512_90s,_:=kyber_kem.Kyber_512_90s.Keygen()
+	sealed,_:=Seal(sk_512.Public(),nil,nil)
+	if _,err:=Open(sk_512_90s,sealed,nil);err==nil{
+		t.Fatal("opened a message sealed for a different scheme")
+	}
+}
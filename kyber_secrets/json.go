@@ -0,0 +1,186 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains a JSON tree that keeps the order of object keys, so an encrypted document diffs line by line against the plain one
+values are *object, []any, string, json.Number, bool or nil, written back with two space indents
+*/
+package kyber_secrets
+
+import(
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type object struct{
+	keys []string
+	values []any
+}
+
+func (o *object)get(key string)(any,bool){
+	for i,k:=range o.keys{
+		if k==key{
+			return o.values[i],true
+		}
+	}
+	return nil,false
+}
+
+func (o *object)set(key string,v any){
+	for i,k:=range o.keys{
+		if k==key{
+			o.values[i]=v
+			return
+		}
+	}
+	o.keys=append(o.keys,key)
+	o.values=append(o.values,v)
+}
+
+func (o *object)remove(key string){
+	for i,k:=range o.keys{
+		if k==key{
+			o.keys=append(o.keys[:i:i],o.keys[i+1:]...)
+			o.values=append(o.values[:i:i],o.values[i+1:]...)
+			return
+		}
+	}
+}
+
+//duplicate keys are an error since the MAC would only cover one reading of them
+func parse_json(data []byte)(any,error){
+	dec:=json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v,err:=decode_value(dec)
+	if err!=nil{
+		return nil,Err_Format
+	}
+	if _,err=dec.Token();err!=io.EOF{
+		return nil,Err_Format
+	}
+	return v,nil
+}
+
+func decode_value(dec *json.Decoder)(any,error){
+	tok,err:=dec.Token()
+	if err!=nil{
+		return nil,err
+	}
+	switch tok{
+	case json.Delim('{'):
+		o:=&object{}
+		for dec.More(){
+			key,err:=dec.Token()
+			if err!=nil{
+				return nil,err
+			}
+			k:=key.(string)
+			if _,dup:=o.get(k);dup{
+				return nil,Err_Format
+			}
+			v,err:=decode_value(dec)
+			if err!=nil{
+				return nil,err
+			}
+			o.set(k,v)
+		}
+		_,err=dec.Token()
+		return o,err
+	case json.Delim('['):
+		a:=[]any{}
+		for dec.More(){
+			v,err:=decode_value(dec)
+			if err!=nil{
+				return nil,err
+			}
+			a=append(a,v)
+		}
+		_,err=dec.Token()
+		return a,err
+	}
+	return tok,nil
+}
+
+func marshal_scalar(v any)[]byte{
+	var buf bytes.Buffer
+	enc:=json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return bytes.TrimSuffix(buf.Bytes(),[]byte("\n"))
+}
+
+func marshal_json(v any)[]byte{
+	var buf bytes.Buffer
+	write_value(&buf,v,"")
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func write_value(buf *bytes.Buffer,v any,indent string){
+	inner:=indent+"  "
+	switch v:=v.(type){
+	case *object:
+		if len(v.keys)==0{
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i,k:=range v.keys{
+			buf.WriteString(inner)
+			buf.Write(marshal_scalar(k))
+			buf.WriteString(": ")
+			write_value(buf,v.values[i],inner)
+			if i<len(v.keys)-1{
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent+"}")
+	case []any:
+		if len(v)==0{
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i,e:=range v{
+			buf.WriteString(inner)
+			write_value(buf,e,inner)
+			if i<len(v)-1{
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent+"]")
+	default:
+		buf.Write(marshal_scalar(v))
+	}
+}
+
+//visits every leaf with its path of keys and indices, plain is set below keys with the unencrypted suffix, leaves are replaced by what fn returns
+func walk(v any,path []any,plain bool,fn func(path []any,v any,plain bool)(any,error))(any,error){
+	switch node:=v.(type){
+	case *object:
+		for i,k:=range node.keys{
+			child,err:=walk(node.values[i],append(path[:len(path):len(path)],k),plain||strings.HasSuffix(k,Unencrypted_Suffix),fn)
+			if err!=nil{
+				return nil,err
+			}
+			node.values[i]=child
+		}
+		return node,nil
+	case []any:
+		for i:=range node{
+			child,err:=walk(node[i],append(path[:len(path):len(path)],i),plain,fn)
+			if err!=nil{
+				return nil,err
+			}
+			node[i]=child
+		}
+		return node,nil
+	}
+	return fn(path,v,plain)
+}
@@ -0,0 +1,433 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains sops style encryption of the values in a JSON or YAML document, the keys and the structure stay readable for review and diffs
+	value:     "ENC[AES256_GCM,data:base64,iv:base64,tag:base64,type:str|num|bool]"
+	           AES-256-GCM(KMAC256(data key,"kyber_secrets value"),iv,plaintext,aad=JSON path of the value||0||type)
+	metadata:  "kyber": {"recipients": [{"scheme","public_key","data_key": kyber_seal(public key,data key)}],"lastmodified","mac","unencrypted_suffix","version"}
+	mac:       HMAC-SHA256(KMAC256(data key,"kyber_secrets mac"),every leaf's path, stored value and plain flag||every recipient's scheme and public key||lastmodified||suffix||version)
+null values and everything under a key ending in _unencrypted are left as they are but still covered by the MAC,
+a document that starts with { is JSON and anything else is YAML, each is written back in the format it was read in
+*/
+package kyber_secrets
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kdf"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_seal"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const(
+	Metadata_Key="kyber"
+	Unencrypted_Suffix="_unencrypted"
+	Version="1"
+	data_key_len=32
+	iv_len=12
+	tag_len=16
+	value_prefix="ENC[AES256_GCM,"
+	data_key_aad="kyber_secrets data key"
+)
+
+var b64=base64.StdEncoding
+
+var(
+	Err_Format=errors.New("kyber_secrets: malformed document")
+	Err_Encrypted=errors.New("kyber_secrets: document is already encrypted")
+	Err_No_Recipient=errors.New("kyber_secrets: key is not a recipient of the document")
+	Err_Duplicate=errors.New("kyber_secrets: key is already a recipient of the document")
+	Err_Last_Recipient=errors.New("kyber_secrets: the last recipient can not be removed")
+	Err_MAC=errors.New("kyber_secrets: document MAC does not match")
+	Err_Value=errors.New("kyber_secrets: encrypted value failed authentication")
+)
+
+type recipient struct{
+	Scheme string `json:"scheme"`
+	Public_Key string `json:"public_key"`
+	Data_Key string `json:"data_key"`
+}
+
+type metadata struct{
+	Recipients []recipient `json:"recipients"`
+	Last_Modified string `json:"lastmodified"`
+	MAC string `json:"mac"`
+	Unencrypted_Suffix string `json:"unencrypted_suffix"`
+	Version string `json:"version"`
+}
+
+type document struct{
+	root *object
+	meta *metadata
+	data_key []byte
+	yaml bool
+}
+
+func (r *recipient)public_key()(kyber_kem.Public_Key,error){
+	s,err:=kyber_kem.Scheme_by_Name(r.Scheme)
+	if err!=nil{
+		return nil,Err_Format
+	}
+	data,err:=b64.DecodeString(r.Public_Key)
+	if err!=nil{
+		return nil,Err_Format
+	}
+	return s.Bytes_to_Pk(data)
+}
+
+func (r *recipient)is(pk kyber_kem.Public_Key)bool{
+	return r.Scheme==pk.Scheme().Name()&&r.Public_Key==b64.EncodeToString(pk.To_Bytes())
+}
+
+func (d *document)find(pk kyber_kem.Public_Key)int{
+	for i:=range d.meta.Recipients{
+		if d.meta.Recipients[i].is(pk){
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *document)add(pk kyber_kem.Public_Key)error{
+	if d.find(pk)>=0{
+		return Err_Duplicate
+	}
+	sealed,err:=kyber_seal.Seal(pk,d.data_key,[]byte(data_key_aad))
+	if err!=nil{
+		return err
+	}
+	d.meta.Recipients=append(d.meta.Recipients,recipient{pk.Scheme().Name(),b64.EncodeToString(pk.To_Bytes()),b64.EncodeToString(sealed)})
+	return nil
+}
+
+func derive(data_key []byte,label string)[]byte{
+	key,err:=kyber_kdf.KMAC256.Derive(data_key,nil,label,32)
+	if err!=nil{
+		panic(err)//KMAC256 takes any length
+	}
+	return key
+}
+
+func value_aead(data_key []byte)cipher.AEAD{
+	block,_:=aes.NewCipher(derive(data_key,"kyber_secrets value"))
+	aead,_:=cipher.NewGCM(block)
+	return aead
+}
+
+func value_aad(path []any,typ string)[]byte{
+	return append(append(marshal_scalar(path),0),typ...)
+}
+
+func put_bytes(out,field []byte)[]byte{
+	return append(binary.BigEndian.AppendUint32(out,uint32(len(field))),field...)
+}
+
+func (d *document)mac()string{
+	m:=hmac.New(sha256.New,derive(d.data_key,"kyber_secrets mac"))
+	walk(d.root,nil,false,func(path []any,v any,plain bool)(any,error){
+		flag:=byte(0)
+		if plain{
+			flag=1
+		}
+		m.Write(append(put_bytes(put_bytes(nil,marshal_scalar(path)),marshal_scalar(v)),flag))
+		return v,nil
+	})
+	//a recipient swapped for an attacker's key would get the data key on the next add or rotate
+	for _,r:=range d.meta.Recipients{
+		m.Write(put_bytes(put_bytes(nil,[]byte(r.Scheme)),[]byte(r.Public_Key)))
+	}
+	for _,field:=range []string{d.meta.Last_Modified,d.meta.Unencrypted_Suffix,d.meta.Version}{
+		m.Write(put_bytes(nil,[]byte(field)))
+	}
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func (d *document)encrypt_values()error{
+	aead:=value_aead(d.data_key)
+	_,err:=walk(d.root,nil,false,func(path []any,v any,plain bool)(any,error){
+		if plain||v==nil{
+			return v,nil
+		}
+		var typ,text string
+		switch v:=v.(type){
+		case string:
+			typ,text="str",v
+		case json.Number:
+			typ,text="num",string(v)
+		case bool:
+			typ,text="bool","false"
+			if v{
+				text="true"
+			}
+		default:
+			return nil,Err_Format
+		}
+		iv:=make([]byte,iv_len)
+		if _,err:=rand.Read(iv);err!=nil{
+			return nil,err
+		}
+		sealed:=aead.Seal(nil,iv,[]byte(text),value_aad(path,typ))
+		ct,tag:=sealed[:len(sealed)-tag_len],sealed[len(sealed)-tag_len:]
+		return value_prefix+"data:"+b64.EncodeToString(ct)+",iv:"+b64.EncodeToString(iv)+",tag:"+b64.EncodeToString(tag)+",type:"+typ+"]",nil
+	})
+	return err
+}
+
+func parse_value(s string)(ct,iv,tag []byte,typ string,err error){
+	if !strings.HasPrefix(s,value_prefix)||!strings.HasSuffix(s,"]"){
+		return nil,nil,nil,"",Err_Format
+	}
+	fields:=strings.Split(s[len(value_prefix):len(s)-1],",")
+	if len(fields)!=4{
+		return nil,nil,nil,"",Err_Format
+	}
+	var decoded [3][]byte
+	for i,name:=range []string{"data:","iv:","tag:"}{
+		if !strings.HasPrefix(fields[i],name){
+			return nil,nil,nil,"",Err_Format
+		}
+		if decoded[i],err=b64.DecodeString(fields[i][len(name):]);err!=nil{
+			return nil,nil,nil,"",Err_Format
+		}
+	}
+	typ,ok:=strings.CutPrefix(fields[3],"type:")
+	if !ok||len(decoded[1])!=iv_len||len(decoded[2])!=tag_len{
+		return nil,nil,nil,"",Err_Format
+	}
+	return decoded[0],decoded[1],decoded[2],typ,nil
+}
+
+func (d *document)decrypt_values()error{
+	aead:=value_aead(d.data_key)
+	_,err:=walk(d.root,nil,false,func(path []any,v any,plain bool)(any,error){
+		if plain||v==nil{
+			return v,nil
+		}
+		s,ok:=v.(string)
+		if !ok{
+			return nil,Err_Format
+		}
+		ct,iv,tag,typ,err:=parse_value(s)
+		if err!=nil{
+			return nil,err
+		}
+		text,err:=aead.Open(nil,iv,append(ct,tag...),value_aad(path,typ))
+		if err!=nil{
+			return nil,Err_Value
+		}
+		switch typ{
+		case "str":
+			return string(text),nil
+		case "num":
+			var n json.Number
+			if json.Unmarshal(text,&n)!=nil{
+				return nil,Err_Format
+			}
+			return n,nil
+		case "bool":
+			return string(text)=="true",nil
+		}
+		return nil,Err_Format
+	})
+	return err
+}
+
+//the root of a document has to be an object
+func parse_document(data []byte)(root *object,yaml bool,err error){
+	var v any
+	if yaml=!bytes.HasPrefix(bytes.TrimLeft(data," \t\r\n"),[]byte("{"));yaml{
+		v,err=parse_yaml(data)
+	}else{
+		v,err=parse_json(data)
+	}
+	if err!=nil{
+		return nil,false,err
+	}
+	root,ok:=v.(*object)
+	if !ok{
+		return nil,false,Err_Format
+	}
+	return root,yaml,nil
+}
+
+func (d *document)encode()[]byte{
+	if d.yaml{
+		return marshal_yaml(d.root)
+	}
+	return marshal_json(d.root)
+}
+
+func (d *document)marshal()([]byte,error){
+	d.meta.Last_Modified=time.Now().UTC().Format(time.RFC3339)
+	d.meta.MAC=d.mac()
+	data,err:=json.Marshal(d.meta)
+	if err!=nil{
+		return nil,err
+	}
+	meta,err:=parse_json(data)
+	if err!=nil{
+		return nil,err
+	}
+	d.root.set(Metadata_Key,meta)
+	out:=d.encode()
+	d.root.remove(Metadata_Key)
+	return out,nil
+}
+
+//opens the data key with sk and checks the MAC, the values stay encrypted
+func open(doc []byte,sk kyber_kem.Private_Key)(*document,error){
+	root,yaml,err:=parse_document(doc)
+	if err!=nil{
+		return nil,err
+	}
+	meta_node,ok:=root.get(Metadata_Key)
+	if !ok{
+		return nil,Err_Format
+	}
+	d:=&document{root:root,meta:&metadata{},yaml:yaml}
+	dec:=json.NewDecoder(bytes.NewReader(marshal_json(meta_node)))
+	dec.DisallowUnknownFields()
+	if dec.Decode(d.meta)!=nil||d.meta.Version!=Version||d.meta.Unencrypted_Suffix!=Unencrypted_Suffix{
+		return nil,Err_Format
+	}
+	root.remove(Metadata_Key)
+	i:=d.find(sk.Public())
+	if i<0{
+		return nil,Err_No_Recipient
+	}
+	sealed,err:=b64.DecodeString(d.meta.Recipients[i].Data_Key)
+	if err!=nil{
+		return nil,Err_Format
+	}
+	if d.data_key,err=kyber_seal.Open(sk,sealed,[]byte(data_key_aad));err!=nil||len(d.data_key)!=data_key_len{
+		return nil,Err_No_Recipient
+	}
+	if !hmac.Equal([]byte(d.mac()),[]byte(d.meta.MAC)){
+		return nil,Err_MAC
+	}
+	return d,nil
+}
+
+func new_data_key()([]byte,error){
+	key:=make([]byte,data_key_len)
+	_,err:=rand.Read(key)
+	return key,err
+}
+
+//a new data key for every value and recipient
+func (d *document)rotate()error{
+	if err:=d.decrypt_values();err!=nil{
+		return err
+	}
+	recipients:=d.meta.Recipients
+	d.meta.Recipients=nil
+	var err error
+	if d.data_key,err=new_data_key();err!=nil{
+		return err
+	}
+	for _,r:=range recipients{
+		pk,err:=r.public_key()
+		if err!=nil{
+			return err
+		}
+		if err=d.add(pk);err!=nil{
+			return err
+		}
+	}
+	return d.encrypt_values()
+}
+
+//plain has to be a JSON object or a YAML mapping without a kyber key
+func Encrypt(plain []byte,recipients []kyber_kem.Public_Key)([]byte,error){
+	root,yaml,err:=parse_document(plain)
+	if err!=nil{
+		return nil,err
+	}
+	if len(recipients)==0{
+		return nil,Err_Format
+	}
+	if _,ok:=root.get(Metadata_Key);ok{
+		return nil,Err_Encrypted
+	}
+	d:=&document{root:root,meta:&metadata{Unencrypted_Suffix:Unencrypted_Suffix,Version:Version},yaml:yaml}
+	if d.data_key,err=new_data_key();err!=nil{
+		return nil,err
+	}
+	for _,pk:=range recipients{
+		if err=d.add(pk);err!=nil{
+			return nil,err
+		}
+	}
+	if err=d.encrypt_values();err!=nil{
+		return nil,err
+	}
+	return d.marshal()
+}
+
+func Decrypt(doc []byte,sk kyber_kem.Private_Key)([]byte,error){
+	d,err:=open(doc,sk)
+	if err!=nil{
+		return nil,err
+	}
+	if err=d.decrypt_values();err!=nil{
+		return nil,err
+	}
+	return d.encode(),nil
+}
+
+//sk is any current recipient, the new one gets the same data key
+func Add_Recipient(doc []byte,sk kyber_kem.Private_Key,pk kyber_kem.Public_Key)([]byte,error){
+	d,err:=open(doc,sk)
+	if err!=nil{
+		return nil,err
+	}
+	if err=d.add(pk);err!=nil{
+		return nil,err
+	}
+	return d.marshal()
+}
+
+//the data key is rotated as well so the removed recipient's copy opens none of the values from now on
+func Remove_Recipient(doc []byte,sk kyber_kem.Private_Key,pk kyber_kem.Public_Key)([]byte,error){
+	d,err:=open(doc,sk)
+	if err!=nil{
+		return nil,err
+	}
+	i:=d.find(pk)
+	if i<0{
+		return nil,Err_No_Recipient
+	}
+	if len(d.meta.Recipients)==1{
+		return nil,Err_Last_Recipient
+	}
+	d.meta.Recipients=append(d.meta.Recipients[:i:i],d.meta.Recipients[i+1:]...)
+	if err=d.rotate();err!=nil{
+		return nil,err
+	}
+	return d.marshal()
+}
+
+func Rotate(doc []byte,sk kyber_kem.Private_Key)([]byte,error){
+	d,err:=open(doc,sk)
+	if err!=nil{
+		return nil,err
+	}
+	if err=d.rotate();err!=nil{
+		return nil,err
+	}
+	return d.marshal()
+}
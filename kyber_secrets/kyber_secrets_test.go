@@ -0,0 +1,345 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on encrypting JSON and YAML secrets, the document MAC and adding, removing and rotating recipients
+*/
+package kyber_secrets
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//written the way marshal_json writes, so the decrypted document compares byte for byte
+const plain=`{
+  "database": {
+    "host_unencrypted": "db.internal",
+    "password": "hunter2 <&>",
+    "port": 5432,
+    "ratio": 1.50,
+    "tls": true,
+    "replicas": [
+      "r1-secret",
+      {
+        "token": "ümlaut token"
+      }
+    ]
+  },
+  "empty": "",
+  "nothing": null,
+  "z_first_in_file": {},
+  "a_after": []
+}
+`
+
+func keys(t *testing.T,n int)[]kyber_kem.Private_Key{
+	var out []kyber_kem.Private_Key
+	for i:=0;i<n;i++{
+		sk,err:=kyber_kem.Kyber_768.Keygen()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		out=append(out,sk)
+	}
+	return out
+}
+
+func Test_Round_Trip(t *testing.T){
+	sks:=keys(t,3)
+	doc,err:=Encrypt([]byte(plain),[]kyber_kem.Public_Key{sks[0].Public(),sks[1].Public()})
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,secret:=range []string{"hunter2","5432","r1-secret","token\": \"ümlaut"}{
+		if bytes.Contains(doc,[]byte(secret)){
+			t.Fatal("encrypted document shows "+secret)
+		}
+	}
+	for _,readable:=range []string{`"password": "ENC[AES256_GCM,`,`"host_unencrypted": "db.internal"`,`"nothing": null`,`type:num]`,`"replicas": [`}{
+		if !bytes.Contains(doc,[]byte(readable)){
+			t.Fatal("encrypted document does not show "+readable)
+		}
+	}
+	for _,sk:=range sks[:2]{
+		got,err:=Decrypt(doc,sk)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if string(got)!=plain{
+			t.Fatal("document did not round trip:\n"+string(got))
+		}
+	}
+	if _,err=Decrypt(doc,sks[2]);err!=Err_No_Recipient{
+		t.Fatal("document opened for a key that is not a recipient")
+	}
+	if _,err=Encrypt(doc,[]kyber_kem.Public_Key{sks[0].Public()});err!=Err_Encrypted{
+		t.Fatal("encrypted document was encrypted again")
+	}
+	for _,bad:=range []string{`[1]`,`{"a":1,"a":2}`,`{"a":1} {}`,`{`}{
+		if _,err=Encrypt([]byte(bad),[]kyber_kem.Public_Key{sks[0].Public()});err!=Err_Format{
+			t.Fatal("malformed document was encrypted: "+bad)
+		}
+	}
+	if _,err=Encrypt([]byte(plain),nil);err!=Err_Format{
+		t.Fatal("document was encrypted to nobody")
+	}
+}
+
+func Test_MAC(t *testing.T){
+	sk:=keys(t,1)[0]
+	doc,_:=Encrypt([]byte(plain),[]kyber_kem.Public_Key{sk.Public()})
+	text:=string(doc)
+	lines:=strings.Split(text,"\n")
+	var password,empty string
+	for _,l:=range lines{
+		if strings.Contains(l,`"password"`){
+			password=strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(l),`"password": `),",")
+		}
+		if strings.Contains(l,`"empty"`){
+			empty=strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(l),`"empty": `),",")
+		}
+	}
+	swapped:=strings.NewReplacer(password,empty,empty,password).Replace(text)
+	tampered:=[]string{
+		strings.Replace(text,"db.internal","db.evil",1),
+		strings.Replace(text,"data:","data:A",1),
+		swapped,
+		strings.Replace(text,`"nothing": null,`,"",1),
+	}
+	for i,doc:=range tampered{
+		if _,err:=Decrypt([]byte(doc),sk);err!=Err_MAC{
+			t.Fatalf("tampered document %d passed the MAC: %v",i,err)
+		}
+	}
+	//the recipient list is covered as well
+	sks:=keys(t,3)
+	doc,_=Encrypt([]byte(plain),[]kyber_kem.Public_Key{sks[1].Public(),sks[0].Public()})
+	text=string(doc)
+	pk:=func(sk kyber_kem.Private_Key)string{
+		return b64.EncodeToString(sk.Public().To_Bytes())
+	}
+	for i,doc:=range []string{
+		strings.Replace(text,pk(sks[1]),pk(sks[2]),1),
+		strings.Replace(text,`"scheme": "kyber_768"`,`"scheme": "kyber_768_90s"`,1),
+	}{
+		if _,err:=Decrypt([]byte(doc),sks[0]);err!=Err_MAC{
+			t.Fatalf("document with changed recipient %d passed the MAC: %v",i,err)
+		}
+	}
+	//values are bound to their path even without the MAC
+	d,err:=open(doc,sks[0])
+	if err!=nil{
+		t.Fatal(err)
+	}
+	database,_:=d.root.get("database")
+	password_value,_:=database.(*object).get("password")
+	empty_value,_:=d.root.get("empty")
+	database.(*object).set("password",empty_value)
+	d.root.set("empty",password_value)
+	if err=d.decrypt_values();err!=Err_Value{
+		t.Fatal("value moved to another path was decrypted")
+	}
+}
+
+func Test_Recipients(t *testing.T){
+	sks:=keys(t,3)
+	doc,_:=Encrypt([]byte(plain),[]kyber_kem.Public_Key{sks[0].Public()})
+	added,err:=Add_Recipient(doc,sks[0],sks[1].Public())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if got,err:=Decrypt(added,sks[1]);err!=nil||string(got)!=plain{
+		t.Fatal("added recipient can not decrypt")
+	}
+	if _,err=Add_Recipient(added,sks[1],sks[0].Public());err!=Err_Duplicate{
+		t.Fatal("recipient was added twice")
+	}
+	if _,err=Add_Recipient(doc,sks[2],sks[2].Public());err!=Err_No_Recipient{
+		t.Fatal("non recipient added itself")
+	}
+	before,_:=open(added,sks[0])
+	removed,err:=Remove_Recipient(added,sks[1],sks[0].Public())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if _,err=Decrypt(removed,sks[0]);err!=Err_No_Recipient{
+		t.Fatal("removed recipient can still decrypt")
+	}
+	after,err:=open(removed,sks[1])
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if bytes.Equal(before.data_key,after.data_key){
+		t.Fatal("data key was not rotated on removal")
+	}
+	if got,err:=Decrypt(removed,sks[1]);err!=nil||string(got)!=plain{
+		t.Fatal("remaining recipient can not decrypt")
+	}
+	if _,err=Remove_Recipient(removed,sks[1],sks[1].Public());err!=Err_Last_Recipient{
+		t.Fatal("last recipient was removed")
+	}
+	if _,err=Remove_Recipient(removed,sks[1],sks[2].Public());err!=Err_No_Recipient{
+		t.Fatal("non recipient was removed")
+	}
+	rotated,err:=Rotate(added,sks[0])
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,sk:=range sks[:2]{
+		if got,err:=Decrypt(rotated,sk);err!=nil||string(got)!=plain{
+			t.Fatal("rotated document does not decrypt")
+		}
+	}
+	rotated_doc,_:=open(rotated,sks[0])
+	if bytes.Equal(before.data_key,rotated_doc.data_key)||bytes.Contains(rotated,[]byte(strings.SplitN(string(added),"data:",2)[1][:20])){
+		t.Fatal("rotation kept the data key or a ciphertext")
+	}
+}
+
+//written the way marshal_yaml writes
+const plain_yaml=`database:
+  host_unencrypted: db.internal
+  password: hunter2 <&>
+  port: 5432
+  ratio: 1.50
+  tls: true
+  replicas:
+    - r1-secret
+    - token: ümlaut token
+      enabled: "yes"
+    - - nested
+  cert: "-----BEGIN CERT-----\nMIIB\n-----END CERT-----\n"
+empty: ""
+nothing: null
+z_first_in_file: {}
+a_after: []
+`
+
+func Test_YAML(t *testing.T){
+	sks:=keys(t,2)
+	doc,err:=Encrypt([]byte(plain_yaml),[]kyber_kem.Public_Key{sks[0].Public()})
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,secret:=range []string{"hunter2","5432","r1-secret","ümlaut","nested","MIIB"}{
+		if bytes.Contains(doc,[]byte(secret)){
+			t.Fatal("encrypted document shows "+secret)
+		}
+	}
+	for _,readable:=range []string{"\n  password: ENC[AES256_GCM,","\n  host_unencrypted: db.internal\n","\nnothing: null\n","\nkyber:\n  recipients:\n    - scheme: kyber_768\n","\n  version: \"1\"\n"}{
+		if !bytes.Contains(doc,[]byte(readable)){
+			t.Fatal("encrypted document does not show "+readable)
+		}
+	}
+	added,err:=Add_Recipient(doc,sks[0],sks[1].Public())
+	if err!=nil||bytes.HasPrefix(added,[]byte("{")){
+		t.Fatal("recipient was not added in YAML")
+	}
+	got,err:=Decrypt(added,sks[1])
+	if err!=nil||string(got)!=plain_yaml{
+		t.Fatal("document did not round trip:\n"+string(got))
+	}
+	if _,err=Decrypt(bytes.Replace(added,[]byte("db.internal"),[]byte("db.evil"),1),sks[0]);err!=Err_MAC{
+		t.Fatal("tampered YAML document passed the MAC")
+	}
+	//the parts of YAML that config files use read into the same tree as JSON
+	v,err:=parse_yaml([]byte(`--- # config
+# a comment
+"quoted: key": 'it''s'
+plain: a#b # comment
+list:
+- "tab\there"
+-   - x
+    - 'y'
+-
+  k: ~
+block: |
+  line one
+
+    indented
+  # not a comment
+stripped: |-
+  no newline
+kept: |+
+  two
+
+next: 1e3
+`))
+	want:=`{
+  "quoted: key": "it's",
+  "plain": "a#b",
+  "list": [
+    "tab\there",
+    [
+      "x",
+      "y"
+    ],
+    {
+      "k": null
+    }
+  ],
+  "block": "line one\n\n  indented\n# not a comment\n",
+  "stripped": "no newline",
+  "kept": "two\n\n",
+  "next": 1e3
+}
+`
+	if err!=nil||string(marshal_json(v))!=want{
+		t.Fatalf("YAML was read wrong: %v\n%s",err,marshal_json(v))
+	}
+	//strings that look like something else are quoted
+	o:=&object{}
+	for i,s:=range []string{"","yes","Off","null","~","1","-1","0x1F",".5","- x","a: b","b #c","#c"," x","x:","&a","*a","!t","'q'","\"q\"","[a]","{a}","|","<<","x\ny","\x00","ENC[AES256_GCM,data:x,iv:y]","1_000","2024-01-01","+1","01",".inf","y","ON","0o17","1:30","-"}{
+		o.set(strconv.Itoa(i),s)
+	}
+	v,err=parse_yaml(marshal_yaml(o))
+	if err!=nil||!bytes.Equal(marshal_json(v),marshal_json(o)){
+		t.Fatalf("strings did not round trip: %v\n%s",err,marshal_yaml(o))
+	}
+	//plain scalars that YAML types but this reader does not are refused, quoted they stay strings
+	for _,typed:=range []string{"0x1F","yes","No","on","y","1_000",".5","+1","01","0o17","0b101","1:30","1_0.5e+3",".inf","-.Inf",".NaN","2024-01-01","2024-1-1 10:00:00 Z","2001-12-14t21:59:43.10-05:00","<<"}{
+		if _,err=parse_yaml([]byte("a: "+typed+"\n"));err!=Err_Format{
+			t.Fatalf("plain %s was read as a string",typed)
+		}
+		v,err=parse_yaml([]byte("a: '"+typed+"'\n"))
+		if err!=nil||string(marshal_json(v))!=`{
+  "a": "`+typed+`"
+}
+`{
+			t.Fatalf("quoted %s was not read as a string",typed)
+		}
+		if back,err:=parse_yaml(marshal_yaml(v));err!=nil||!bytes.Equal(marshal_json(back),marshal_json(v)){
+			t.Fatalf("quoted %s did not round trip",typed)
+		}
+	}
+	for _,plain:=range []string{"1.0.0","v1","yess","0x","1e","2024-01","db-01",".","nULL","-1.5e3"}{
+		if v,err=parse_yaml([]byte("a: "+plain+"\n"));err!=nil{
+			t.Fatalf("plain %s was refused",plain)
+		}
+	}
+	for _,bad:=range []string{
+		"- a\n",
+		"a: 1\na: 2\n",
+		"a:\n\tb: 1\n",
+		"a: &x 1\nb: *x\n",
+		"a: !!str 1\n",
+		"a: >\n  folded\n",
+		"a: [1, 2]\n",
+		"a: b: c\n",
+		"a: \"open\n",
+		"a: 1\n  b: 2\n",
+		"a:\n  - 1\n  b: 2\n",
+		"just a scalar\n",
+		"",
+	}{
+		if _,err=Encrypt([]byte(bad),[]kyber_kem.Public_Key{sks[0].Public()});err!=Err_Format{
+			t.Fatalf("malformed YAML was encrypted: %q",bad)
+		}
+	}
+}
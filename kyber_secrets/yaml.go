@@ -0,0 +1,431 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the block YAML that config files are written in, read into the same tree as JSON and written back with two space indents
+	mappings and sequences in block style, plain, single and double quoted scalars, | block scalars, comments and a leading ---
+	null, ~, true, false and numbers in JSON syntax are typed, every other plain scalar is a string
+plain scalars that YAML 1.1 or 1.2 reads as some other type, like 0x1F, yes, 1_000, .5 or 2024-01-01, are rejected with Err_Format and have to be quoted,
+anchors, aliases, tags, folded scalars, multi line flow scalars and flow collections other than {} and [] are rejected with Err_Format,
+comments are dropped
+*/
+package kyber_secrets
+
+import(
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type yaml_parser struct{
+	lines []string
+	i int
+	err error
+}
+
+func parse_yaml(data []byte)(any,error){
+	text:=strings.TrimPrefix(strings.ReplaceAll(string(data),"\r\n","\n"),"\ufeff")
+	p:=&yaml_parser{lines:strings.Split(strings.TrimSuffix(text,"\n"),"\n")}
+	if _,line,ok:=p.peek();ok&&(line=="---"||strings.HasPrefix(line,"--- #")){
+		p.i++
+	}
+	indent,_,ok:=p.peek()
+	if !ok{
+		return nil,Err_Format
+	}
+	v:=p.node(indent)
+	if _,_,more:=p.peek();more||p.err!=nil{
+		return nil,Err_Format
+	}
+	return v,nil
+}
+
+//the next line that is not blank or a comment, without consuming it
+func (p *yaml_parser)peek()(indent int,text string,ok bool){
+	for ;p.err==nil&&p.i<len(p.lines);p.i++{
+		line:=strings.TrimRight(p.lines[p.i]," \t")
+		text=strings.TrimLeft(line," ")
+		if text==""||text[0]=='#'{
+			continue
+		}
+		if text[0]=='\t'{
+			p.err=Err_Format
+			break
+		}
+		return len(line)-len(text),text,true
+	}
+	return 0,"",false
+}
+
+func is_item(text string)bool{
+	return text=="-"||strings.HasPrefix(text,"- ")
+}
+
+//a block node whose first line is at indent
+func (p *yaml_parser)node(indent int)any{
+	_,text,_:=p.peek()
+	if is_item(text){
+		return p.sequence(indent)
+	}
+	if _,_,ok:=split_key(text);ok{
+		return p.mapping(indent)
+	}
+	p.i++
+	return p.scalar(text)
+}
+
+func (p *yaml_parser)mapping(indent int)any{
+	o:=&object{}
+	for{
+		at,text,ok:=p.peek()
+		if !ok||at<indent{
+			return o
+		}
+		key,rest,ok:=split_key(text)
+		if at>indent||!ok{
+			p.err=Err_Format
+			return nil
+		}
+		if _,dup:=o.get(key);dup{
+			p.err=Err_Format
+			return nil
+		}
+		p.i++
+		o.set(key,p.value(indent,rest,true))
+	}
+}
+
+func (p *yaml_parser)sequence(indent int)any{
+	a:=[]any{}
+	for{
+		at,text,ok:=p.peek()
+		if !ok||at<indent||(at==indent&&!is_item(text)){
+			return a
+		}
+		if at>indent{
+			p.err=Err_Format
+			return nil
+		}
+		rest:=strings.TrimLeft(text[1:]," ")
+		switch{
+		case rest==""||rest[0]=='#':
+			p.i++
+			a=append(a,p.value(indent,"",false))
+		case rest[0]=='|':
+			p.i++
+			a=append(a,p.block(indent,rest))
+		default:
+			//the item is read as if the dash were a space, which also covers a mapping that starts on the dash line
+			line:=p.lines[p.i]
+			p.lines[p.i]=line[:indent]+" "+line[indent+1:]
+			a=append(a,p.node(indent+len(text)-len(rest)))
+		}
+		if p.err!=nil{
+			return nil
+		}
+	}
+}
+
+//the value after a key or a dash, a sequence may sit at the same indent as the key it belongs to
+func (p *yaml_parser)value(parent int,rest string,key bool)any{
+	if rest==""||rest[0]=='#'{
+		at,text,ok:=p.peek()
+		if ok&&(at>parent||(key&&at==parent&&is_item(text))){
+			return p.node(at)
+		}
+		return nil
+	}
+	if rest[0]=='|'{
+		return p.block(parent,rest)
+	}
+	return p.scalar(rest)
+}
+
+//a literal block scalar, | keeps one final line break, |- none and |+ all of them
+func (p *yaml_parser)block(parent int,header string)any{
+	chomp,comment,_:=strings.Cut(header[1:]," ")
+	if (chomp!=""&&chomp!="-"&&chomp!="+")||(strings.TrimSpace(comment)!=""&&!strings.HasPrefix(strings.TrimSpace(comment),"#")){
+		p.err=Err_Format
+		return nil
+	}
+	indent:=-1
+	var lines []string
+	for ;p.i<len(p.lines);p.i++{
+		line:=p.lines[p.i]
+		text:=strings.TrimLeft(line," ")
+		if text==""{
+			if indent>=0&&len(line)>indent{
+				lines=append(lines,line[indent:])
+			}else{
+				lines=append(lines,"")
+			}
+			continue
+		}
+		at:=len(line)-len(text)
+		if indent<0{
+			if at<=parent{
+				break
+			}
+			indent=at
+		}
+		if at<indent{
+			break
+		}
+		lines=append(lines,line[indent:])
+	}
+	end:=len(lines)
+	for end>0&&lines[end-1]==""{
+		end--
+	}
+	if end==0&&chomp!="+"{
+		return ""
+	}
+	s:=strings.Join(lines[:end],"\n")
+	switch chomp{
+	case "":
+		s+="\n"
+	case "+":
+		s+=strings.Repeat("\n",len(lines)-end+1)
+	}
+	return s
+}
+
+//a mapping entry, the key is a quoted scalar or a plain one without ": " or " #" in it
+func split_key(text string)(key,rest string,ok bool){
+	if text[0]=='"'||text[0]=='\''{
+		key,after,err:=unquote(text)
+		if err!=nil||!strings.HasPrefix(after,":")||(len(after)>1&&after[1]!=' '){
+			return "","",false
+		}
+		return key,strings.TrimSpace(after[1:]),true
+	}
+	i:=strings.Index(text,": ")
+	if i<0{
+		if !strings.HasSuffix(text,":"){
+			return "","",false
+		}
+		i=len(text)-1
+	}
+	key=strings.TrimRight(text[:i]," ")
+	if key==""||strings.ContainsRune("-?:,[]{}#&*!|>%@`",rune(key[0]))||strings.Contains(key," #"){
+		return "","",false
+	}
+	return key,strings.TrimSpace(text[i+1:]),true
+}
+
+func (p *yaml_parser)scalar(text string)any{
+	switch text[0]{
+	case '"','\'':
+		s,after,err:=unquote(text)
+		if err!=nil||(after!=""&&after[0]!='#'){
+			p.err=Err_Format
+			return nil
+		}
+		return s
+	case '[','{':
+		flow,_,_:=strings.Cut(text," #")
+		switch strings.TrimRight(flow," "){
+		case "[]":
+			return []any{}
+		case "{}":
+			return &object{}
+		}
+		p.err=Err_Format
+		return nil
+	}
+	if i:=strings.Index(text," #");i>=0{
+		text=strings.TrimRight(text[:i]," ")
+	}
+	if strings.ContainsRune("&*!|>%@`",rune(text[0]))||is_item(text)||strings.Contains(text,": ")||strings.HasSuffix(text,":"){
+		p.err=Err_Format
+		return nil
+	}
+	v:=resolve_plain(text)
+	if _,ok:=v.(string);ok&&yaml_typed.MatchString(text){
+		p.err=Err_Format//reading it as a string would change its type on the way back out
+		return nil
+	}
+	return v
+}
+
+//the booleans, ints, floats, timestamps, merge and value keys of the YAML 1.1 types and the YAML 1.2 core schema
+var yaml_typed=regexp.MustCompile(`^(?:`+strings.Join([]string{
+	`y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF|<<|=`,
+	`[-+]?0b[01_]+|[-+]?0o?[0-7_]+|[-+]?0x[0-9a-fA-F_]+|[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])*`,
+	`[-+]?(?:[0-9][0-9_]*\.[0-9_]*|\.[0-9][0-9_]*)(?:[eE][-+]?[0-9]+)?|[-+]?[0-9][0-9_]*[eE][-+]?[0-9]+|[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+\.[0-9_]*`,
+	`[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN)`,
+	`[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(?:(?:[Tt]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]*)?(?:[ \t]*Z|[-+][0-9]{1,2}(?::[0-9]{2})?)?)?`,
+},"|")+`)$`)
+
+func resolve_plain(s string)any{
+	switch s{
+	case "null","Null","NULL","~":
+		return nil
+	case "true","True","TRUE":
+		return true
+	case "false","False","FALSE":
+		return false
+	}
+	if (s[0]=='-'||(s[0]>='0'&&s[0]<='9'))&&json.Valid([]byte(s)){
+		return json.Number(s)
+	}
+	return s
+}
+
+//a quoted scalar on one line and what follows it with spaces trimmed
+func unquote(text string)(s,after string,err error){
+	var b strings.Builder
+	quote:=text[0]
+	for i:=1;i<len(text);i++{
+		c:=text[i]
+		switch{
+		case c==quote&&quote=='\''&&i+1<len(text)&&text[i+1]=='\'':
+			b.WriteByte('\'')
+			i++
+		case c==quote:
+			return b.String(),strings.TrimLeft(text[i+1:]," "),nil
+		case c=='\\'&&quote=='"':
+			if i+1>=len(text){
+				return "","",Err_Format
+			}
+			i++
+			if r,ok:=yaml_escapes[text[i]];ok{
+				b.WriteRune(r)
+				continue
+			}
+			digits:=map[byte]int{'x':2,'u':4,'U':8}[text[i]]
+			if digits==0||i+digits>=len(text){
+				return "","",Err_Format
+			}
+			r,err:=strconv.ParseUint(text[i+1:i+1+digits],16,32)
+			if err!=nil||!utf8.ValidRune(rune(r)){
+				return "","",Err_Format
+			}
+			b.WriteRune(rune(r))
+			i+=digits
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "","",Err_Format
+}
+
+var yaml_escapes=map[byte]rune{
+	'0':0,'a':'\a','b':'\b','t':'\t','\t':'\t','n':'\n','v':'\v','f':'\f','r':'\r','e':0x1b,
+	' ':' ','"':'"','/':'/','\\':'\\','N':0x85,'_':0xa0,'L':0x2028,'P':0x2029,
+}
+
+func marshal_yaml(v any)[]byte{
+	var buf bytes.Buffer
+	o,ok:=v.(*object)
+	if !ok||len(o.keys)==0{
+		write_yaml_scalar(&buf,v)
+		buf.WriteByte('\n')
+		return buf.Bytes()
+	}
+	write_yaml_object(&buf,o,"")
+	return buf.Bytes()
+}
+
+func write_yaml_object(buf *bytes.Buffer,o *object,indent string){
+	for i,k:=range o.keys{
+		buf.WriteString(indent)
+		write_yaml_scalar(buf,k)
+		buf.WriteByte(':')
+		write_yaml_child(buf,o.values[i],indent+"  ")
+	}
+}
+
+//the first line of a nested collection goes after "- " and the rest keep their indent
+func write_yaml_list(buf *bytes.Buffer,a []any,indent string){
+	for _,e:=range a{
+		buf.WriteString(indent+"-")
+		var sub bytes.Buffer
+		switch e:=e.(type){
+		case *object:
+			if len(e.keys)>0{
+				write_yaml_object(&sub,e,indent+"  ")
+			}
+		case []any:
+			if len(e)>0{
+				write_yaml_list(&sub,e,indent+"  ")
+			}
+		}
+		if sub.Len()==0{
+			buf.WriteByte(' ')
+			write_yaml_scalar(buf,e)
+			buf.WriteByte('\n')
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.Write(sub.Bytes()[len(indent)+2:])
+	}
+}
+
+func write_yaml_child(buf *bytes.Buffer,v any,indent string){
+	switch v:=v.(type){
+	case *object:
+		if len(v.keys)>0{
+			buf.WriteByte('\n')
+			write_yaml_object(buf,v,indent)
+			return
+		}
+	case []any:
+		if len(v)>0{
+			buf.WriteByte('\n')
+			write_yaml_list(buf,v,indent)
+			return
+		}
+	}
+	buf.WriteByte(' ')
+	write_yaml_scalar(buf,v)
+	buf.WriteByte('\n')
+}
+
+func write_yaml_scalar(buf *bytes.Buffer,v any){
+	switch v:=v.(type){
+	case nil:
+		buf.WriteString("null")
+	case *object:
+		buf.WriteString("{}")
+	case []any:
+		buf.WriteString("[]")
+	case string:
+		if plain_safe(v){
+			buf.WriteString(v)
+		}else{
+			buf.Write(marshal_scalar(v))//JSON strings are YAML double quoted scalars
+		}
+	default:
+		buf.Write(marshal_scalar(v))
+	}
+}
+
+//strings that read back as the same string in YAML 1.1 and 1.2 without quotes
+func plain_safe(s string)bool{
+	if s==""||s!=strings.TrimSpace(s)||strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`+.0123456789",rune(s[0])){
+		return false
+	}
+	if _,ok:=resolve_plain(s).(string);!ok||yaml_typed.MatchString(s){
+		return false
+	}
+	switch strings.ToLower(s){
+	case "y","n","yes","no","on","off","null","true","false","<<":
+		return false
+	}
+	if strings.Contains(s,": ")||strings.Contains(s," #")||strings.HasSuffix(s,":"){
+		return false
+	}
+	for _,r:=range s{
+		if r!=' '&&!unicode.IsPrint(r){
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,216 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the messages and shared secret of the SSH hybrid key exchange laid out for mlkem768x25519-sha256 (draft-ietf-sshm-mlkem-hybrid-kex)
+built on kyber_768 with X25519, the sntrup761x25519-sha512 method has the same shape with a different hash so both hashes are offered:
+
+	SSH_MSG_KEX_ECDH_INIT  (30): string Q_C        Q_C=kyber public key(1184)||X25519 public key(32)
+	SSH_MSG_KEX_ECDH_REPLY (31): string K_S, string Q_S, string signature of H        Q_S=kyber ciphertext(1088)||X25519 public key(32)
+	K=HASH(kyber secret||X25519 secret), put in the exchange hash as a string and not an mpint
+	H=HASH(string V_C||string V_S||string I_C||string I_S||string K_S||string Q_C||string Q_S||string K)
+kyber_768 is the round 3 algorithm and not FIPS 203 ML-KEM, so peers must both use this package and the method names are kept under a private domain
+*/
+package kyber_ssh
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+)
+
+const(
+	Msg_Kex_ECDH_Init=30
+	Msg_Kex_ECDH_Reply=31
+	x25519_len=32
+)
+
+var(
+	Err_Format=errors.New("kyber_ssh: malformed key exchange message")
+	Err_Message=errors.New("kyber_ssh: unexpected message number")
+	Err_State=errors.New("kyber_ssh: key exchange step run out of order")
+)
+
+var kem=kyber_kem.Kyber_768
+
+type Method struct{
+	Name string
+	Hash crypto.Hash
+}
+
+var(
+	Kyber768_X25519_SHA256=&Method{"kyber768x25519-sha256@kyber-go-native",crypto.SHA256}
+	Kyber768_X25519_SHA512=&Method{"kyber768x25519-sha512@kyber-go-native",crypto.SHA512}
+)
+
+func (m *Method)sum(data []byte)[]byte{
+	if m.Hash==crypto.SHA512{
+		h:=sha512.Sum512(data)
+		return h[:]
+	}
+	h:=sha256.Sum256(data)
+	return h[:]
+}
+
+func Init_Len()int{
+	return kem.Pk_Len()+x25519_len
+}
+
+func Reply_Len()int{
+	return kem.Ct_Len()+x25519_len
+}
+
+func put_string(out,field []byte)[]byte{
+	out=binary.BigEndian.AppendUint32(out,uint32(len(field)))
+	return append(out,field...)
+}
+
+type reader struct{
+	data []byte
+	err error
+}
+
+func (r *reader)bytes()[]byte{
+	if r.err!=nil||len(r.data)<4{
+		r.err=Err_Format
+		return nil
+	}
+	n:=binary.BigEndian.Uint32(r.data)
+	if uint64(n)>uint64(len(r.data)-4){
+		r.err=Err_Format
+		return nil
+	}
+	out:=r.data[4:4+n]
+	r.data=r.data[4+n:]
+	return out
+}
+
+func Marshal_Init(Q_C []byte)[]byte{
+	return put_string([]byte{Msg_Kex_ECDH_Init},Q_C)
+}
+
+func Parse_Init(packet []byte)(Q_C []byte,err error){
+	if len(packet)<1||packet[0]!=Msg_Kex_ECDH_Init{
+		return nil,Err_Message
+	}
+	r:=&reader{data:packet[1:]}
+	Q_C=r.bytes()
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	return
+}
+
+//K_S is the server host key blob and signature the encoded signature of H, both as x/crypto/ssh marshals them
+func Marshal_Reply(K_S,Q_S,signature []byte)[]byte{
+	return put_string(put_string(put_string([]byte{Msg_Kex_ECDH_Reply},K_S),Q_S),signature)
+}
+
+func Parse_Reply(packet []byte)(K_S,Q_S,signature []byte,err error){
+	if len(packet)<1||packet[0]!=Msg_Kex_ECDH_Reply{
+		return nil,nil,nil,Err_Message
+	}
+	r:=&reader{data:packet[1:]}
+	K_S,Q_S,signature=r.bytes(),r.bytes(),r.bytes()
+	if r.err!=nil||len(r.data)!=0{
+		return nil,nil,nil,Err_Format
+	}
+	return
+}
+
+//the shared secret already encoded as an SSH string, ready for the exchange hash and the key derivation of RFC 4253 section 7.2
+func (m *Method)encode_secret(K_PQ,K_CL []byte)[]byte{
+	return put_string(nil,m.sum(append(append([]byte(nil),K_PQ...),K_CL...)))
+}
+
+//V_C and V_S are the identification strings without CR LF, I_C and I_S the payloads of both SSH_MSG_KEXINIT
+func (m *Method)Exchange_Hash(V_C,V_S,I_C,I_S,K_S,Q_C,Q_S,K []byte)[]byte{
+	var data []byte
+	for _,field:=range [][]byte{V_C,V_S,I_C,I_S,K_S,Q_C,Q_S}{
+		data=put_string(data,field)
+	}
+	return m.sum(append(data,K...))
+}
+
+type Client struct{
+	method *Method
+	sk kyber_kem.Private_Key
+	dh *ecdh.PrivateKey
+	Q_C []byte
+}
+
+func (m *Method)New_Client()(C *Client,err error){
+	C=&Client{method:m}
+	if C.sk,err=kem.Keygen();err!=nil{
+		return nil,err
+	}
+	if C.dh,err=ecdh.X25519().GenerateKey(rand.Reader);err!=nil{
+		return nil,err
+	}
+	C.Q_C=append(C.sk.Public().To_Bytes(),C.dh.PublicKey().Bytes()...)
+	return
+}
+
+//SSH_MSG_KEX_ECDH_INIT packet
+func (C *Client)Init()[]byte{
+	return Marshal_Init(C.Q_C)
+}
+
+//the shared secret K as a string, the client checks the host key signature of Exchange_Hash before using it
+func (C *Client)Finish(Q_S []byte)(K []byte,err error){
+	if C.sk==nil{
+		return nil,Err_State
+	}
+	if len(Q_S)!=Reply_Len(){
+		return nil,Err_Format
+	}
+	K_PQ,err:=C.sk.Dec(Q_S[:kem.Ct_Len()])
+	if err!=nil{
+		return
+	}
+	server_dh,err:=ecdh.X25519().NewPublicKey(Q_S[kem.Ct_Len():])
+	if err!=nil{
+		return nil,Err_Format
+	}
+	K_CL,err:=C.dh.ECDH(server_dh)//fails on a low order point
+	if err!=nil{
+		return
+	}
+	C.sk,C.dh=nil,nil
+	return C.method.encode_secret(K_PQ,K_CL),nil
+}
+
+//the server side is a single step, it signs Exchange_Hash with its host key and sends Marshal_Reply
+func (m *Method)Server_Reply(Q_C []byte)(Q_S,K []byte,err error){
+	if len(Q_C)!=Init_Len(){
+		return nil,nil,Err_Format
+	}
+	pk,err:=kem.Bytes_to_Pk(Q_C[:kem.Pk_Len()])
+	if err!=nil{
+		return nil,nil,Err_Format
+	}
+	client_dh,err:=ecdh.X25519().NewPublicKey(Q_C[kem.Pk_Len():])
+	if err!=nil{
+		return nil,nil,Err_Format
+	}
+	c,K_PQ,err:=pk.Enc()
+	if err!=nil{
+		return
+	}
+	dh,err:=ecdh.X25519().GenerateKey(rand.Reader)
+	if err!=nil{
+		return
+	}
+	K_CL,err:=dh.ECDH(client_dh)
+	if err!=nil{
+		return
+	}
+	return append(c,dh.PublicKey().Bytes()...),m.encode_secret(K_PQ,K_CL),nil
+}
@@ -0,0 +1,129 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on the SSH hybrid key exchange between an in-process client and server
+*/
+package kyber_ssh
+
+import(
+	"golang.org/x/crypto/ssh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"bytes"
+	"testing"
+)
+
+var(
+	V_C=[]byte("SSH-2.0-client")
+	V_S=[]byte("SSH-2.0-server")
+	I_C=[]byte("client kexinit payload")
+	I_S=[]byte("server kexinit payload")
+)
+
+func host_key(t *testing.T)ssh.Signer{
+	_,priv,err:=ed25519.GenerateKey(rand.Reader)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	signer,err:=ssh.NewSignerFromKey(priv)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return signer
+}
+
+//runs the server side on a SSH_MSG_KEX_ECDH_INIT packet and returns the reply packet with its shared secret
+func serve(t *testing.T,m *Method,host ssh.Signer,init []byte)(reply,K []byte){
+	Q_C,err:=Parse_Init(init)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	Q_S,K,err:=m.Server_Reply(Q_C)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	K_S:=host.PublicKey().Marshal()
+	sig,err:=host.Sign(rand.Reader,m.Exchange_Hash(V_C,V_S,I_C,I_S,K_S,Q_C,Q_S,K))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return Marshal_Reply(K_S,Q_S,ssh.Marshal(sig)),K
+}
+
+func Test_Exchange(t *testing.T){
+	host:=host_key(t)
+	for _,m:=range []*Method{Kyber768_X25519_SHA256,Kyber768_X25519_SHA512}{
+		C,err:=m.New_Client()
+		if err!=nil{
+			t.Fatal(err)
+		}
+		init:=C.Init()
+		if len(init)!=1+4+Init_Len(){
+			t.Fatal("init packet has the wrong length")
+		}
+		reply,server_K:=serve(t,m,host,init)
+		K_S,Q_S,sig_blob,err:=Parse_Reply(reply)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		K,err:=C.Finish(Q_S)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		if !bytes.Equal(K,server_K)||len(K)!=4+m.Hash.Size(){
+			t.Fatal(m.Name+" shared secrets do not match")
+		}
+		host_pub,err:=ssh.ParsePublicKey(K_S)
+		if err!=nil{
+			t.Fatal(err)
+		}
+		sig:=new(ssh.Signature)
+		if err=ssh.Unmarshal(sig_blob,sig);err!=nil{
+			t.Fatal(err)
+		}
+		H:=m.Exchange_Hash(V_C,V_S,I_C,I_S,K_S,C.Q_C,Q_S,K)
+		if err=host_pub.Verify(H,sig);err!=nil{
+			t.Fatal("client could not verify the exchange hash: "+err.Error())
+		}
+		if _,err=C.Finish(Q_S);err!=Err_State{
+			t.Fatal("client finished twice")
+		}
+	}
+}
+
+func Test_Bad_Messages(t *testing.T){
+	m:=Kyber768_X25519_SHA256
+	C,_:=m.New_Client()
+	init:=C.Init()
+	if _,err:=Parse_Init(init[:len(init)-1]);err!=Err_Format{
+		t.Fatal("truncated init was parsed")
+	}
+	if _,err:=Parse_Init(append(init,0));err!=Err_Format{
+		t.Fatal("init with trailing data was parsed")
+	}
+	if _,_,_,err:=Parse_Reply(init);err!=Err_Message{
+		t.Fatal("init was parsed as a reply")
+	}
+	if _,_,err:=m.Server_Reply(C.Q_C[1:]);err!=Err_Format{
+		t.Fatal("short client key was accepted")
+	}
+	low_order:=append([]byte(nil),C.Q_C...)
+	copy(low_order[kem.Pk_Len():],make([]byte,x25519_len))
+	if _,_,err:=m.Server_Reply(low_order);err==nil{
+		t.Fatal("low order X25519 key was accepted")
+	}
+	//a changed ciphertext gives the client a different secret, so the host signature would not verify
+	reply,server_K:=serve(t,m,host_key(t),init)
+	_,Q_S,_,_:=Parse_Reply(reply)
+	Q_S[0]^=1
+	K,err:=C.Finish(Q_S)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	if bytes.Equal(K,server_K){
+		t.Fatal("tampered reply gave the same secret")
+	}
+}
@@ -0,0 +1,618 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains TreeKEM group key agreement in the style of MLS with kyber_768 as the KEM
+a commit applies removes then adds and always carries an UpdatePath from the committer
+	path_secret[0]=Derive(leaf_secret,"path") path_secret[i+1]=Derive(path_secret[i],"path") commit_secret=path_secret[len(filtered path)]
+	node key=kyber_768 Seed_to_Keys(Derive(secret,"node"))
+each path secret is sealed with kyber_seal to every node in the resolution of the copath child, leaves added by the same commit get theirs in the Welcome
+	joiner_secret=HKDF-Extract(salt init_secret,commit_secret) epoch_secret=Expand(joiner_secret,"epoch"||group context)
+	init_secret=Expand(epoch_secret,"init") confirmation tag=HMAC-SHA256(Expand(epoch_secret,"confirm"),group context)
+group context=group id||epoch||SHA3-256(tree) so the tag binds every public key and membership change of the commit
+*/
+package kyber_treekem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_seal"
+	"golang.org/x/crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const(
+	secret_len=32
+	max_leaves=1<<16
+	state_version=1
+)
+
+var kem=kyber_kem.Kyber_768
+
+var(
+	Err_Epoch=errors.New("kyber_treekem: commit is for a different epoch")
+	Err_Removed=errors.New("kyber_treekem: this member was removed from the group")
+	Err_Commit=errors.New("kyber_treekem: invalid commit")
+	Err_Confirm=errors.New("kyber_treekem: confirmation tag does not match")
+	Err_Not_Invited=errors.New("kyber_treekem: welcome has no secrets for this key package")
+	Err_Format=errors.New("kyber_treekem: malformed message")
+)
+
+type Key_Package struct{
+	Identity []byte
+	Public_Key kyber_kem.Public_Key
+}
+
+type Path_Node struct{
+	Public_Key kyber_kem.Public_Key
+	Secrets [][]byte//one per node in the resolution of the copath child, in resolution order
+}
+
+type Commit struct{
+	Epoch uint64//the epoch the commit was made in
+	Committer uint32
+	Removes []uint32
+	Adds []*Key_Package
+	Leaf_Key kyber_kem.Public_Key
+	Path []Path_Node
+	Confirmation_Tag []byte
+}
+
+type Welcome_Secret struct{
+	Leaf uint32
+	Ciphertext []byte//kyber_seal of joiner_secret||[path secret of the lowest common ancestor with the committer]
+}
+
+type Welcome struct{
+	Group_ID []byte
+	Epoch uint64
+	Committer uint32
+	Tree []byte
+	Secrets []Welcome_Secret
+	Confirmation_Tag []byte
+}
+
+type Group struct{
+	group_id []byte
+	epoch uint64
+	tree *tree
+	own uint32
+	keys map[uint32]kyber_kem.Private_Key
+	init_secret,epoch_secret []byte
+}
+
+func derive(secret []byte,label string)[]byte{
+	out:=make([]byte,secret_len)
+	io.ReadFull(hkdf.Expand(sha256.New,secret,[]byte("kyber_treekem "+label)),out)
+	return out
+}
+
+func node_key(secret []byte)(kyber_kem.Private_Key,error){
+	return kem.Seed_to_Keys([32]byte(derive(secret,"node")))
+}
+
+func random_secret()([]byte,error){
+	secret:=make([]byte,secret_len)
+	_,err:=rand.Read(secret)
+	return secret,err
+}
+
+//the private key stays with the new member and opens its Welcome in Join
+func New_Key_Package(identity []byte)(*Key_Package,kyber_kem.Private_Key,error){
+	sk,err:=kem.Keygen()
+	if err!=nil{
+		return nil,nil,err
+	}
+	return &Key_Package{append([]byte(nil),identity...),sk.Public()},sk,nil
+}
+
+func New_Group(group_id,identity []byte)(*Group,error){
+	kp,sk,err:=New_Key_Package(identity)
+	if err!=nil{
+		return nil,err
+	}
+	g:=&Group{group_id:append([]byte(nil),group_id...),tree:&tree{[]*node{{pk:kp.Public_Key,identity:kp.Identity}}},keys:map[uint32]kyber_kem.Private_Key{0:sk}}
+	if g.init_secret,err=random_secret();err!=nil{
+		return nil,err
+	}
+	if g.epoch_secret,err=random_secret();err!=nil{
+		return nil,err
+	}
+	return g,nil
+}
+
+func (g *Group)Epoch()uint64{
+	return g.epoch
+}
+
+func (g *Group)Epoch_Secret()[]byte{
+	return append([]byte(nil),g.epoch_secret...)
+}
+
+func (g *Group)Leaf_Index()uint32{
+	return g.own
+}
+
+//identities by leaf index, blank leaves are nil
+func (g *Group)Members()[][]byte{
+	out:=make([][]byte,g.tree.n_leaves())
+	for l:=range out{
+		if n:=g.tree.leaf(uint32(l));n!=nil{
+			out[l]=n.identity
+		}
+	}
+	return out
+}
+
+func (g *Group)context(epoch uint64,t *tree)[]byte{
+	out:=put_bytes(nil,g.group_id)
+	out=binary.BigEndian.AppendUint64(out,epoch)
+	return append(out,t.hash()...)
+}
+
+func (g *Group)clone()*Group{
+	c:=*g
+	c.tree=g.tree.clone()
+	c.keys=make(map[uint32]kyber_kem.Private_Key,len(g.keys))
+	for x,sk:=range g.keys{
+		c.keys[x]=sk
+	}
+	return &c
+}
+
+func (g *Group)forget_blank(){
+	for x:=range g.keys{
+		if x>=uint32(len(g.tree.nodes))||g.tree.nodes[x]==nil{
+			delete(g.keys,x)
+		}
+	}
+}
+
+func (g *Group)apply_proposals(removes []uint32,adds []*Key_Package)(added []uint32,err error){
+	for _,l:=range removes{
+		if g.tree.leaf(l)==nil{
+			return nil,Err_Commit
+		}
+		if l==g.own{
+			return nil,Err_Removed
+		}
+		g.tree.remove(l)
+	}
+	for _,kp:=range adds{
+		if kp==nil||kp.Public_Key==nil||kp.Public_Key.Scheme()!=kem{
+			return nil,Err_Commit
+		}
+		if g.tree.n_leaves()>=max_leaves&&g.tree.leaf(g.tree.n_leaves()-1)!=nil{
+			return nil,Err_Commit
+		}
+		added=append(added,g.tree.add(&node{pk:kp.Public_Key,identity:append([]byte(nil),kp.Identity...)}))
+	}
+	g.forget_blank()
+	return
+}
+
+func (g *Group)advance(commit_secret []byte)[]byte{
+	g.epoch++
+	joiner:=hkdf.Extract(sha256.New,commit_secret,g.init_secret)
+	return g.set_epoch(joiner)
+}
+
+func (g *Group)set_epoch(joiner []byte)(tag []byte){
+	context:=g.context(g.epoch,g.tree)
+	g.epoch_secret=derive(joiner,"epoch"+string(context))
+	g.init_secret=derive(g.epoch_secret,"init")
+	mac:=hmac.New(sha256.New,derive(g.epoch_secret,"confirm"))
+	mac.Write(context)
+	return mac.Sum(nil)
+}
+
+func exclude(nodes,leaves []uint32)(out []uint32){
+	for _,x:=range nodes{
+		skip:=false
+		for _,l:=range leaves{
+			if x==2*l{
+				skip=true
+			}
+		}
+		if !skip{
+			out=append(out,x)
+		}
+	}
+	return
+}
+
+//lowest common ancestor of two leaves
+func (t *tree)ancestor(a,b uint32)uint32{
+	pa,_:=t.direct_path(2*a)
+	pb,_:=t.direct_path(2*b)
+	for _,x:=range pa{
+		for _,y:=range pb{
+			if x==y{
+				return x
+			}
+		}
+	}
+	return t.root()
+}
+
+//the committer moves to the new epoch right away, every other member calls Process_Commit and new members call Join with the Welcome
+func (g *Group)Commit(adds []*Key_Package,removes []uint32)(*Commit,*Welcome,error){
+	next:=g.clone()
+	added,err:=next.apply_proposals(removes,adds)
+	if err!=nil{
+		return nil,nil,err
+	}
+	leaf_secret,err:=random_secret()
+	if err!=nil{
+		return nil,nil,err
+	}
+	leaf_sk,err:=node_key(leaf_secret)
+	if err!=nil{
+		return nil,nil,err
+	}
+	t:=next.tree
+	t.nodes[2*next.own]=&node{pk:leaf_sk.Public(),identity:t.nodes[2*next.own].identity}
+	next.keys[2*next.own]=leaf_sk
+	t.blank_path(next.own)
+	path,copath:=t.filtered_path(next.own)
+	secrets:=make([][]byte,len(path)+1)
+	secrets[0]=derive(leaf_secret,"path")
+	for i,x:=range path{
+		sk,err:=node_key(secrets[i])
+		if err!=nil{
+			return nil,nil,err
+		}
+		t.nodes[x]=&node{pk:sk.Public()}
+		next.keys[x]=sk
+		secrets[i+1]=derive(secrets[i],"path")
+	}
+	next.forget_blank()
+	c:=&Commit{Epoch:g.epoch,Committer:g.own,Removes:append([]uint32(nil),removes...),Adds:adds,Leaf_Key:leaf_sk.Public()}
+	context:=next.context(g.epoch+1,t)
+	for i,x:=range path{
+		pn:=Path_Node{Public_Key:t.nodes[x].pk}
+		for _,r:=range exclude(t.resolution(copath[i]),added){
+			sealed,err:=kyber_seal.Seal(t.nodes[r].pk,secrets[i],context)
+			if err!=nil{
+				return nil,nil,err
+			}
+			pn.Secrets=append(pn.Secrets,sealed)
+		}
+		c.Path=append(c.Path,pn)
+	}
+	joiner:=hkdf.Extract(sha256.New,secrets[len(path)],next.init_secret)
+	next.epoch++
+	c.Confirmation_Tag=next.set_epoch(joiner)
+	var w *Welcome
+	if len(added)>0{
+		w=&Welcome{Group_ID:next.group_id,Epoch:next.epoch,Committer:next.own,Tree:t.encode(),Confirmation_Tag:c.Confirmation_Tag}
+		for _,l:=range added{
+			payload:=append([]byte(nil),joiner...)
+			lca:=t.ancestor(l,next.own)
+			for i,x:=range path{
+				if x==lca{
+					payload=append(payload,secrets[i]...)
+				}
+			}
+			sealed,err:=kyber_seal.Seal(t.nodes[2*l].pk,payload,context)
+			if err!=nil{
+				return nil,nil,err
+			}
+			w.Secrets=append(w.Secrets,Welcome_Secret{l,sealed})
+		}
+	}
+	*g=*next
+	return c,w,nil
+}
+
+//derives the path secrets from node i of the committer's filtered path upward, checks them against the commit and keeps the private keys
+func (g *Group)merge_path(path []uint32,i int,secret []byte)([]byte,error){
+	for ;i<len(path);i++{
+		sk,err:=node_key(secret)
+		if err!=nil{
+			return nil,err
+		}
+		if !hmac.Equal(sk.Public().To_Bytes(),g.tree.nodes[path[i]].pk.To_Bytes()){
+			return nil,Err_Commit
+		}
+		g.keys[path[i]]=sk
+		secret=derive(secret,"path")
+	}
+	return secret,nil
+}
+
+//the group is left unchanged when the commit is rejected
+func (g *Group)Process_Commit(c *Commit)error{
+	if c.Epoch!=g.epoch{
+		return Err_Epoch
+	}
+	if c.Committer==g.own||g.tree.leaf(c.Committer)==nil||c.Leaf_Key==nil||c.Leaf_Key.Scheme()!=kem{
+		return Err_Commit
+	}
+	for _,l:=range c.Removes{
+		if l==c.Committer{
+			return Err_Commit
+		}
+	}
+	next:=g.clone()
+	added,err:=next.apply_proposals(c.Removes,c.Adds)
+	if err!=nil{
+		return err
+	}
+	t:=next.tree
+	t.nodes[2*c.Committer]=&node{pk:c.Leaf_Key,identity:t.nodes[2*c.Committer].identity}
+	t.blank_path(c.Committer)
+	path,copath:=t.filtered_path(c.Committer)
+	if len(path)!=len(c.Path){
+		return Err_Commit
+	}
+	for i,x:=range path{
+		if c.Path[i].Public_Key==nil||c.Path[i].Public_Key.Scheme()!=kem{
+			return Err_Commit
+		}
+		t.nodes[x]=&node{pk:c.Path[i].Public_Key}
+	}
+	next.forget_blank()
+	context:=next.context(g.epoch+1,t)
+	lca:=t.ancestor(next.own,c.Committer)
+	var secret []byte
+	for i,x:=range path{
+		if x!=lca{
+			continue
+		}
+		res:=exclude(t.resolution(copath[i]),added)
+		if len(res)!=len(c.Path[i].Secrets){
+			return Err_Commit
+		}
+		for j,r:=range res{
+			sk,ok:=next.keys[r]
+			if !ok{
+				continue
+			}
+			if secret,err=kyber_seal.Open(sk,c.Path[i].Secrets[j],context);err!=nil{
+				return Err_Commit
+			}
+			if secret,err=next.merge_path(path,i,secret);err!=nil{
+				return err
+			}
+			break
+		}
+	}
+	if secret==nil{
+		return Err_Commit
+	}
+	if !hmac.Equal(next.advance(secret),c.Confirmation_Tag){
+		return Err_Confirm
+	}
+	*g=*next
+	return nil
+}
+
+func Join(w *Welcome,sk kyber_kem.Private_Key)(*Group,error){
+	r:=&reader{data:w.Tree}
+	t:=r.tree()
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	g:=&Group{group_id:append([]byte(nil),w.Group_ID...),epoch:w.Epoch,tree:t,keys:map[uint32]kyber_kem.Private_Key{}}
+	if w.Epoch==0||t.leaf(w.Committer)==nil{
+		return nil,Err_Format
+	}
+	context:=g.context(w.Epoch,t)
+	for _,s:=range w.Secrets{
+		if n:=t.leaf(s.Leaf);n==nil||!hmac.Equal(n.pk.To_Bytes(),sk.Public().To_Bytes()){
+			continue
+		}
+		payload,err:=kyber_seal.Open(sk,s.Ciphertext,context)
+		if err!=nil||(len(payload)!=secret_len&&len(payload)!=2*secret_len){
+			return nil,Err_Commit
+		}
+		g.own=s.Leaf
+		g.keys[2*s.Leaf]=sk
+		if len(payload)==2*secret_len{
+			path,_:=t.filtered_path(w.Committer)
+			lca:=t.ancestor(s.Leaf,w.Committer)
+			for i,x:=range path{
+				if x==lca{
+					if _,err=g.merge_path(path,i,payload[secret_len:]);err!=nil{
+						return nil,err
+					}
+				}
+			}
+		}
+		if !hmac.Equal(g.set_epoch(payload[:secret_len]),w.Confirmation_Tag){
+			return nil,Err_Confirm
+		}
+		return g,nil
+	}
+	return nil,Err_Not_Invited
+}
+
+func put_bytes(data,field []byte)[]byte{
+	data=binary.BigEndian.AppendUint32(data,uint32(len(field)))
+	return append(data,field...)
+}
+
+type reader struct{
+	data []byte
+	err error
+}
+
+func (r *reader)byte()(x byte){
+	if r.err!=nil||len(r.data)<1{
+		r.err=Err_Format
+		return
+	}
+	x=r.data[0]
+	r.data=r.data[1:]
+	return
+}
+
+func (r *reader)uint32()(x uint32){
+	if r.err!=nil||len(r.data)<4{
+		r.err=Err_Format
+		return
+	}
+	x=binary.BigEndian.Uint32(r.data)
+	r.data=r.data[4:]
+	return
+}
+
+func (r *reader)uint64()uint64{
+	return uint64(r.uint32())<<32|uint64(r.uint32())
+}
+
+func (r *reader)bytes()(field []byte){
+	n:=r.uint32()
+	if r.err!=nil||uint64(len(r.data))<uint64(n){
+		r.err=Err_Format
+		return
+	}
+	field=r.data[:n]
+	r.data=r.data[n:]
+	return
+}
+
+func (r *reader)public_key()kyber_kem.Public_Key{
+	data:=r.bytes()
+	if r.err!=nil{
+		return nil
+	}
+	pk,err:=kem.Bytes_to_Pk(data)
+	if err!=nil{
+		r.err=Err_Format
+	}
+	return pk
+}
+
+func (c *Commit)To_Bytes()(data []byte){
+	data=binary.BigEndian.AppendUint64(data,c.Epoch)
+	data=binary.BigEndian.AppendUint32(data,c.Committer)
+	data=binary.BigEndian.AppendUint32(data,uint32(len(c.Removes)))
+	for _,l:=range c.Removes{
+		data=binary.BigEndian.AppendUint32(data,l)
+	}
+	data=binary.BigEndian.AppendUint32(data,uint32(len(c.Adds)))
+	for _,kp:=range c.Adds{
+		data=put_bytes(data,kp.Identity)
+		data=put_bytes(data,kp.Public_Key.To_Bytes())
+	}
+	data=put_bytes(data,c.Leaf_Key.To_Bytes())
+	data=binary.BigEndian.AppendUint32(data,uint32(len(c.Path)))
+	for _,pn:=range c.Path{
+		data=put_bytes(data,pn.Public_Key.To_Bytes())
+		data=binary.BigEndian.AppendUint32(data,uint32(len(pn.Secrets)))
+		for _,s:=range pn.Secrets{
+			data=put_bytes(data,s)
+		}
+	}
+	return put_bytes(data,c.Confirmation_Tag)
+}
+
+func Bytes_to_Commit(data []byte)(*Commit,error){
+	r:=&reader{data:data}
+	c:=&Commit{Epoch:r.uint64(),Committer:r.uint32()}
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		c.Removes=append(c.Removes,r.uint32())
+	}
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		kp:=&Key_Package{Identity:append([]byte(nil),r.bytes()...)}
+		kp.Public_Key=r.public_key()
+		c.Adds=append(c.Adds,kp)
+	}
+	c.Leaf_Key=r.public_key()
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		pn:=Path_Node{Public_Key:r.public_key()}
+		for m:=r.uint32();m>0&&r.err==nil;m--{
+			pn.Secrets=append(pn.Secrets,append([]byte(nil),r.bytes()...))
+		}
+		c.Path=append(c.Path,pn)
+	}
+	c.Confirmation_Tag=append([]byte(nil),r.bytes()...)
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	return c,nil
+}
+
+func (w *Welcome)To_Bytes()(data []byte){
+	data=put_bytes(data,w.Group_ID)
+	data=binary.BigEndian.AppendUint64(data,w.Epoch)
+	data=binary.BigEndian.AppendUint32(data,w.Committer)
+	data=put_bytes(data,w.Tree)
+	data=binary.BigEndian.AppendUint32(data,uint32(len(w.Secrets)))
+	for _,s:=range w.Secrets{
+		data=binary.BigEndian.AppendUint32(data,s.Leaf)
+		data=put_bytes(data,s.Ciphertext)
+	}
+	return put_bytes(data,w.Confirmation_Tag)
+}
+
+func Bytes_to_Welcome(data []byte)(*Welcome,error){
+	r:=&reader{data:data}
+	w:=&Welcome{Group_ID:append([]byte(nil),r.bytes()...),Epoch:r.uint64(),Committer:r.uint32(),Tree:append([]byte(nil),r.bytes()...)}
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		w.Secrets=append(w.Secrets,Welcome_Secret{r.uint32(),append([]byte(nil),r.bytes()...)})
+	}
+	w.Confirmation_Tag=append([]byte(nil),r.bytes()...)
+	if r.err!=nil||len(r.data)!=0{
+		return nil,Err_Format
+	}
+	return w,nil
+}
+
+//the serialized group holds the private node keys and epoch secrets and must be stored as carefully as a private key
+func (g *Group)To_Bytes()(data []byte){
+	data=append(data,state_version)
+	data=put_bytes(data,g.group_id)
+	data=binary.BigEndian.AppendUint64(data,g.epoch)
+	data=binary.BigEndian.AppendUint32(data,g.own)
+	data=put_bytes(data,g.tree.encode())
+	data=put_bytes(data,g.init_secret)
+	data=put_bytes(data,g.epoch_secret)
+	data=binary.BigEndian.AppendUint32(data,uint32(len(g.keys)))
+	for x:=uint32(0);x<uint32(len(g.tree.nodes));x++{
+		if sk,ok:=g.keys[x];ok{
+			data=binary.BigEndian.AppendUint32(data,x)
+			data=put_bytes(data,sk.To_Bytes())
+		}
+	}
+	return
+}
+
+func Bytes_to_Group(data []byte)(*Group,error){
+	if len(data)<1||data[0]!=state_version{
+		return nil,Err_Format
+	}
+	r:=&reader{data:data[1:]}
+	g:=&Group{group_id:append([]byte(nil),r.bytes()...),epoch:r.uint64(),own:r.uint32(),keys:map[uint32]kyber_kem.Private_Key{}}
+	tr:=&reader{data:r.bytes()}
+	g.init_secret=append([]byte(nil),r.bytes()...)
+	g.epoch_secret=append([]byte(nil),r.bytes()...)
+	for n:=r.uint32();n>0&&r.err==nil;n--{
+		x:=r.uint32()
+		sk,err:=kem.Bytes_to_Sk(r.bytes())
+		if err!=nil{
+			return nil,Err_Format
+		}
+		g.keys[x]=sk
+	}
+	if r.err!=nil||len(r.data)!=0||len(g.init_secret)!=secret_len||len(g.epoch_secret)!=secret_len{
+		return nil,Err_Format
+	}
+	if g.tree=tr.tree();tr.err!=nil||len(tr.data)!=0||g.tree.leaf(g.own)==nil{
+		return nil,Err_Format
+	}
+	for x:=range g.keys{
+		if x>=uint32(len(g.tree.nodes))||g.tree.nodes[x]==nil{
+			return nil,Err_Format
+		}
+	}
+	return g,nil
+}
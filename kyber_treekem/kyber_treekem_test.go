@@ -0,0 +1,195 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains code to run tests on TreeKEM group key agreement
+*/
+package kyber_treekem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"bytes"
+	"fmt"
+	mrand "math/rand"
+	"slices"
+	"testing"
+)
+
+type test_group struct{
+	members map[string]*Group
+	next_id int
+}
+
+func (tg *test_group)key_packages(n int)(kps []*Key_Package,sks []kyber_kem.Private_Key){
+	for i:=0;i<n;i++{
+		kp,sk,err:=New_Key_Package([]byte(fmt.Sprintf("member %d",tg.next_id)))
+		if err!=nil{
+			panic(err)
+		}
+		tg.next_id++
+		kps=append(kps,kp)
+		sks=append(sks,sk)
+	}
+	return
+}
+
+//commits from the named member, sends the serialized commit and welcome to everyone and checks they all agree on the epoch secret
+func (tg *test_group)commit(t *testing.T,committer string,adds int,removes []string){
+	g:=tg.members[committer]
+	kps,sks:=tg.key_packages(adds)
+	var leaves []uint32
+	for _,name:=range removes{
+		leaves=append(leaves,tg.members[name].Leaf_Index())
+	}
+	c,w,err:=g.Commit(kps,leaves)
+	if err!=nil{
+		t.Fatal(err)
+	}
+	c,err=Bytes_to_Commit(c.To_Bytes())
+	if err!=nil{
+		t.Fatal(err)
+	}
+	for _,name:=range removes{
+		if err=tg.members[name].Process_Commit(c);err!=Err_Removed{
+			t.Fatal("removed member processed the commit")
+		}
+		delete(tg.members,name)
+	}
+	for name,m:=range tg.members{
+		if name==committer{
+			continue
+		}
+		if err=m.Process_Commit(c);err!=nil{
+			t.Fatal(name+": "+err.Error())
+		}
+	}
+	if adds>0{
+		if w,err=Bytes_to_Welcome(w.To_Bytes());err!=nil{
+			t.Fatal(err)
+		}
+		for i,kp:=range kps{
+			joined,err:=Join(w,sks[i])
+			if err!=nil{
+				t.Fatal(err)
+			}
+			tg.members[string(kp.Identity)]=joined
+		}
+	}
+	for name,m:=range tg.members{
+		if m.Epoch()!=g.Epoch()||!bytes.Equal(m.Epoch_Secret(),g.Epoch_Secret()){
+			t.Fatal(name+" does not agree on the epoch secret")
+		}
+	}
+}
+
+//sorted so a seeded rng picks the same members on every run
+func (tg *test_group)names()(out []string){
+	for name:=range tg.members{
+		out=append(out,name)
+	}
+	slices.Sort(out)
+	return
+}
+
+func new_test_group(t *testing.T)*test_group{
+	g,err:=New_Group([]byte("room"),[]byte("founder"))
+	if err!=nil{
+		t.Fatal(err)
+	}
+	return &test_group{members:map[string]*Group{"founder":g}}
+}
+
+func Test_Churn(t *testing.T){
+	tg:=new_test_group(t)
+	tg.commit(t,"founder",1,nil)
+	tg.commit(t,"member 0",6,nil)
+	tg.commit(t,"founder",0,nil)
+	rng:=mrand.New(mrand.NewSource(1))
+	for epoch:=0;epoch<25;epoch++{
+		names:=tg.names()
+		committer:=names[rng.Intn(len(names))]
+		var removes []string
+		for _,name:=range names{
+			if name!=committer&&name!="founder"&&len(names)-len(removes)>3&&rng.Intn(6)==0{//the founder stays to count the members at the end
+				removes=append(removes,name)
+			}
+		}
+		tg.commit(t,committer,rng.Intn(4),removes)
+		for _,name:=range tg.names(){//every member goes through serialization now and then
+			if rng.Intn(3)==0{
+				restored,err:=Bytes_to_Group(tg.members[name].To_Bytes())
+				if err!=nil{
+					t.Fatal(err)
+				}
+				tg.members[name]=restored
+			}
+		}
+	}
+	founder:=tg.members["founder"]
+	if founder==nil{
+		t.Fatal("founder left the group")
+	}
+	members:=0
+	for _,id:=range founder.Members(){
+		if id!=nil{
+			members++
+		}
+	}
+	if members!=len(tg.members){
+		t.Fatal("member list does not match the group")
+	}
+}
+
+func Test_Large_Group(t *testing.T){
+	tg:=new_test_group(t)
+	tg.commit(t,"founder",199,nil)
+	tg.commit(t,"member 150",0,[]string{"member 3","member 77","member 198"})
+	tg.commit(t,"member 20",2,nil)
+	if tg.members["founder"].tree.n_leaves()!=256{
+		t.Fatal("tree has the wrong size")
+	}
+}
+
+func Test_Bad_Commits(t *testing.T){
+	tg:=new_test_group(t)
+	tg.commit(t,"founder",3,nil)
+	g:=tg.members["founder"]
+	other:=tg.members["member 1"]
+	state:=other.To_Bytes()
+	c,_,_:=g.Commit(nil,nil)
+	tampered,_:=Bytes_to_Commit(c.To_Bytes())
+	tampered.Confirmation_Tag[0]^=1
+	if err:=other.Process_Commit(tampered);err!=Err_Confirm{
+		t.Fatal("commit with a bad confirmation tag was accepted")
+	}
+	tampered,_=Bytes_to_Commit(c.To_Bytes())
+	tampered.Path[len(tampered.Path)-1].Public_Key=tampered.Leaf_Key
+	if err:=other.Process_Commit(tampered);err==nil{
+		t.Fatal("commit with a swapped path key was accepted")
+	}
+	tampered,_=Bytes_to_Commit(c.To_Bytes())
+	tampered.Epoch++
+	if err:=other.Process_Commit(tampered);err!=Err_Epoch{
+		t.Fatal("commit for another epoch was accepted")
+	}
+	if !bytes.Equal(state,other.To_Bytes()){
+		t.Fatal("rejected commits changed the group")
+	}
+	if err:=other.Process_Commit(c);err!=nil{
+		t.Fatal(err)
+	}
+	if err:=other.Process_Commit(c);err!=Err_Epoch{
+		t.Fatal("commit was applied twice")
+	}
+	_,sk,_:=New_Key_Package([]byte("stranger"))
+	kps,_:=tg.key_packages(1)
+	_,w,_:=g.Commit(kps,nil)
+	if _,err:=Join(w,sk);err!=Err_Not_Invited{
+		t.Fatal("joined without an invitation")
+	}
+	if _,err:=Bytes_to_Commit(c.To_Bytes()[1:]);err==nil{
+		t.Fatal("truncated commit was parsed")
+	}
+}
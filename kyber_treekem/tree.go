@@ -0,0 +1,232 @@
+/*Copyright (c) 2025 Haven F.C. Johnson under the MIT license
+The kyber algorithm has a license that can be found in the file titled "nist-pqc-license-summary-and-excerpts.pdf"
+
+Go port of the kyber post quantum encryption algorithm laid out by the NIST round 3 package that can be found by following the link below:
+https://csrc.nist.gov/Projects/post-quantum-cryptography/selected-algorithms-2022
+
+This file contains the left-balanced array ratchet tree from MLS (RFC 9420 appendix C) with kyber_768 node keys
+leaves sit at even indices, the tree always has a power of two leaves and blank nodes are nil
+*/
+package kyber_treekem
+
+import(
+	"github.com/HavenOfTheRaven/kyber-go-native/kyber_kem"
+	"golang.org/x/crypto/sha3"
+	"encoding/binary"
+)
+
+type node struct{
+	pk kyber_kem.Public_Key
+	identity []byte//leaves only
+	unmerged []uint32//parents only, leaves added below the node since it was last set
+}
+
+type tree struct{
+	nodes []*node
+}
+
+func level(x uint32)(k uint32){
+	for (x>>k)&1==1{
+		k++
+	}
+	return
+}
+
+func left(x uint32)uint32{
+	return x^(1<<(level(x)-1))
+}
+
+func right(x uint32)uint32{
+	return x^(3<<(level(x)-1))
+}
+
+func parent(x uint32)uint32{
+	k:=level(x)
+	b:=(x>>(k+1))&1
+	return (x|(1<<k))^(b<<(k+1))
+}
+
+func sibling(x uint32)uint32{
+	p:=parent(x)
+	if x<p{
+		return right(p)
+	}
+	return left(p)
+}
+
+func (t *tree)n_leaves()uint32{
+	return uint32(len(t.nodes)+1)/2
+}
+
+func (t *tree)root()uint32{
+	return t.n_leaves()-1
+}
+
+func (t *tree)leaf(l uint32)*node{
+	if 2*l>=uint32(len(t.nodes)){
+		return nil
+	}
+	return t.nodes[2*l]
+}
+
+//the direct path runs from the parent of the node up to and including the root, the copath holds the sibling of each step
+func (t *tree)direct_path(x uint32)(path,copath []uint32){
+	for x!=t.root(){
+		path=append(path,parent(x))
+		copath=append(copath,sibling(x))
+		x=parent(x)
+	}
+	return
+}
+
+func (t *tree)resolution(x uint32)[]uint32{
+	n:=t.nodes[x]
+	if n!=nil{
+		out:=[]uint32{x}
+		for _,l:=range n.unmerged{
+			out=append(out,2*l)
+		}
+		return out
+	}
+	if level(x)==0{
+		return nil
+	}
+	return append(t.resolution(left(x)),t.resolution(right(x))...)
+}
+
+//nodes whose copath child has an empty resolution are left out since nobody would be able to decrypt them
+func (t *tree)filtered_path(l uint32)(path,copath []uint32){
+	p,c:=t.direct_path(2*l)
+	for i:=range p{
+		if len(t.resolution(c[i]))>0{
+			path=append(path,p[i])
+			copath=append(copath,c[i])
+		}
+	}
+	return
+}
+
+func (t *tree)blank_path(l uint32){
+	p,_:=t.direct_path(2*l)
+	for _,x:=range p{
+		t.nodes[x]=nil
+	}
+}
+
+func (t *tree)remove(l uint32){
+	t.nodes[2*l]=nil
+	t.blank_path(l)
+	for len(t.nodes)>1{//drop the right half while it is empty
+		half:=(len(t.nodes)+1)/2
+		empty:=true
+		for _,n:=range t.nodes[half:]{
+			if n!=nil{
+				empty=false
+			}
+		}
+		if !empty{
+			break
+		}
+		t.nodes=t.nodes[:half-1]
+	}
+}
+
+//new members take the leftmost blank leaf and the tree doubles when there is none
+func (t *tree)add(n *node)uint32{
+	l:=uint32(0)
+	for ;l<t.n_leaves()&&t.leaf(l)!=nil;l++{
+	}
+	if l==t.n_leaves(){
+		t.nodes=append(t.nodes,make([]*node,len(t.nodes)+1)...)
+	}
+	t.nodes[2*l]=n
+	p,_:=t.direct_path(2*l)
+	for _,x:=range p{
+		if t.nodes[x]!=nil{
+			t.nodes[x].unmerged=append(t.nodes[x].unmerged,l)
+		}
+	}
+	return l
+}
+
+func (t *tree)clone()*tree{
+	c:=&tree{make([]*node,len(t.nodes))}
+	for i,n:=range t.nodes{
+		if n!=nil{
+			m:=*n
+			m.unmerged=append([]uint32(nil),n.unmerged...)
+			c.nodes[i]=&m
+		}
+	}
+	return c
+}
+
+func (t *tree)encode()(data []byte){
+	data=binary.BigEndian.AppendUint32(data,t.n_leaves())
+	for i,n:=range t.nodes{
+		if n==nil{
+			data=append(data,0)
+			continue
+		}
+		data=append(data,1)
+		data=put_bytes(data,n.pk.To_Bytes())
+		if i%2==0{
+			data=put_bytes(data,n.identity)
+			continue
+		}
+		data=binary.BigEndian.AppendUint32(data,uint32(len(n.unmerged)))
+		for _,l:=range n.unmerged{
+			data=binary.BigEndian.AppendUint32(data,l)
+		}
+	}
+	return
+}
+
+func (t *tree)hash()[]byte{
+	h:=sha3.Sum256(t.encode())
+	return h[:]
+}
+
+func (r *reader)tree()*tree{
+	n:=r.uint32()
+	if r.err!=nil||n==0||n&(n-1)!=0||n>max_leaves{
+		r.err=Err_Format
+		return nil
+	}
+	t:=&tree{make([]*node,2*n-1)}
+	for i:=range t.nodes{
+		if r.byte()==0{
+			continue
+		}
+		pk,err:=kem.Bytes_to_Pk(r.bytes())
+		if err!=nil{
+			r.err=Err_Format
+			return nil
+		}
+		nd:=&node{pk:pk}
+		if i%2==0{
+			nd.identity=append([]byte(nil),r.bytes()...)
+		}else{
+			count:=r.uint32()
+			for j:=uint32(0);j<count&&r.err==nil;j++{
+				nd.unmerged=append(nd.unmerged,r.uint32())
+			}
+		}
+		t.nodes[i]=nd
+	}
+	if r.err!=nil{
+		return nil
+	}
+	for i:=1;i<len(t.nodes);i+=2{
+		if t.nodes[i]==nil{
+			continue
+		}
+		for _,l:=range t.nodes[i].unmerged{
+			if l>=n||t.nodes[2*l]==nil{
+				r.err=Err_Format
+				return nil
+			}
+		}
+	}
+	return t
+}